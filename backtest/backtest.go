@@ -0,0 +1,297 @@
+// Package backtest replays historical klines and aggregate trades through
+// simulated exchange and evaluates strategy trading on it.
+//
+// Strategy trades through the same binance.Binance interface as in production.
+// Orders, balances and fees are handled by sim.Exchange, market data calls are
+// served from the replayed history up to the current simulated time, so the
+// strategy can't see the future. Run returns fills, equity curve and statistics.
+//
+//	bt := backtest.New(backtest.Config{
+//		Valuation: "USDT",
+//		Balances:  map[string]binance.Decimal{"USDT": binance.MustParseDecimal("1000")},
+//		Fill:      backtest.FillModel{Price: backtest.FillNextOpen, TakerFee: binance.MustParseDecimal("0.001")},
+//	})
+//	bt.AddKlines(sim.Symbol{Name: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT"}, binance.Hour, klines)
+//	res, err := bt.Run(backtest.Strategy{OnKline: func(b binance.Binance, symbol string, k *binance.Kline) error {
+//		...
+//	}})
+package backtest
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rootpd/binance"
+	"github.com/rootpd/binance/paper"
+	"github.com/rootpd/binance/sim"
+)
+
+// FillPrice decides price at which market orders placed on kline are filled
+// and when resting limit orders are filled.
+type FillPrice string
+
+var (
+	// FillClose fills market orders at close price of the kline they were
+	// placed on. Limit orders are filled when close price crosses them.
+	FillClose = FillPrice("CLOSE")
+	// FillNextOpen fills market orders at open price of the next kline. Limit
+	// orders are filled when open or close price crosses them.
+	FillNextOpen = FillPrice("NEXT_OPEN")
+	// FillHighLow fills market orders at open price of the next kline. Limit
+	// orders are filled when the price range of kline reaches them.
+	FillHighLow = FillPrice("HIGH_LOW")
+)
+
+// FillModel configures how orders are filled.
+//
+// Market orders and limit orders crossing the book at placement are takers,
+// they are filled at fill price moved against them by Slippage, e.g. 0.0005,
+// and at most by volume of the kline or quantity of the trade. Resting limit
+// orders are makers filled at their price. When replaying trades, market
+// orders are filled at price of the last trade.
+type FillModel struct {
+	Price    FillPrice
+	Slippage binance.Decimal
+	MakerFee binance.Decimal
+	TakerFee binance.Decimal
+}
+
+// Config configures backtest.
+type Config struct {
+	Fill FillModel
+	// Balances are initial balances of the account.
+	Balances map[string]binance.Decimal
+	// Valuation is asset in which equity is computed, e.g. "USDT". Other assets
+	// are valued by last price of symbol trading them against Valuation, assets
+	// without such symbol aren't counted.
+	Valuation string
+}
+
+// Strategy reacts to replayed market data by trading through b. Non-nil error
+// stops the backtest.
+type Strategy struct {
+	// OnKline is called when kline of the symbol closes.
+	OnKline func(b binance.Binance, symbol string, k *binance.Kline) error
+	// OnTrade is called after aggregate trade of the symbol.
+	OnTrade func(b binance.Binance, symbol string, t *binance.AggTrade) error
+}
+
+// Backtest replays history added by AddKlines and AddTrades.
+type Backtest struct {
+	cfg      Config
+	now      time.Time
+	exchange *sim.Exchange
+	history  *history
+	symbols  map[string]sim.Symbol
+	last     map[string]binance.Decimal
+}
+
+// New creates backtest with the config.
+func New(cfg Config) *Backtest {
+	if cfg.Fill.Price == "" {
+		cfg.Fill.Price = FillClose
+	}
+	bt := &Backtest{
+		cfg:     cfg,
+		symbols: make(map[string]sim.Symbol),
+		last:    make(map[string]binance.Decimal),
+	}
+	bt.exchange = sim.NewExchange(func() time.Time { return bt.now })
+	bt.history = &history{
+		exchange: bt.exchange,
+		klines:   make(map[string]map[binance.Interval][]*binance.Kline),
+		trades:   make(map[string][]*binance.AggTrade),
+		last:     bt.last,
+	}
+	bt.exchange.SetCommissions(cfg.Fill.MakerFee, cfg.Fill.TakerFee)
+	for asset, amount := range cfg.Balances {
+		bt.exchange.SetBalance(asset, amount)
+	}
+	return bt
+}
+
+// AddKlines adds klines of the symbol to be replayed. Strategy is notified of
+// klines of every added interval, market orders are filled by klines of the
+// shortest one.
+func (bt *Backtest) AddKlines(symbol sim.Symbol, interval binance.Interval, klines []*binance.Kline) {
+	bt.addSymbol(symbol)
+	sorted := append([]*binance.Kline(nil), klines...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].OpenTime.Before(sorted[j].OpenTime) })
+	if bt.history.klines[symbol.Name] == nil {
+		bt.history.klines[symbol.Name] = make(map[binance.Interval][]*binance.Kline)
+	}
+	bt.history.klines[symbol.Name][interval] = sorted
+}
+
+// AddTrades adds aggregate trades of the symbol to be replayed.
+func (bt *Backtest) AddTrades(symbol sim.Symbol, trades []*binance.AggTrade) {
+	bt.addSymbol(symbol)
+	sorted := append([]*binance.AggTrade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+	bt.history.trades[symbol.Name] = sorted
+}
+
+func (bt *Backtest) addSymbol(symbol sim.Symbol) {
+	bt.symbols[symbol.Name] = symbol
+	bt.exchange.AddSymbol(symbol)
+}
+
+// event is single replayed kline or trade.
+type event struct {
+	time   time.Time
+	symbol string
+	// rank orders events of the same time and symbol, trades go first and
+	// klines follow from the shortest interval
+	rank time.Duration
+	// fill is true for trades and klines of the shortest interval
+	fill  bool
+	kline *binance.Kline
+	next  *binance.Kline
+	trade *binance.AggTrade
+}
+
+func (bt *Backtest) events() []*event {
+	var events []*event
+	for symbol, byInterval := range bt.history.klines {
+		var shortest time.Duration
+		for interval := range byInterval {
+			if d, _ := sim.IntervalDuration(interval); shortest == 0 || d < shortest {
+				shortest = d
+			}
+		}
+		for interval, klines := range byInterval {
+			d, _ := sim.IntervalDuration(interval)
+			for i, k := range klines {
+				e := &event{time: k.CloseTime, symbol: symbol, rank: d, fill: d == shortest, kline: k}
+				if i+1 < len(klines) {
+					e.next = klines[i+1]
+				}
+				events = append(events, e)
+			}
+		}
+	}
+	for symbol, trades := range bt.history.trades {
+		for _, t := range trades {
+			events = append(events, &event{time: t.Timestamp, symbol: symbol, fill: true, trade: t})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if !a.time.Equal(b.time) {
+			return a.time.Before(b.time)
+		}
+		if a.symbol != b.symbol {
+			return a.symbol < b.symbol
+		}
+		return a.rank < b.rank
+	})
+	return events
+}
+
+// Run replays the history in time order and returns result of the strategy.
+func (bt *Backtest) Run(strategy Strategy) (*Result, error) {
+	events := bt.events()
+	if len(events) == 0 {
+		return nil, errors.New("no history to replay")
+	}
+	b := binance.NewBinance(paper.NewService(bt.history, bt.exchange))
+
+	res := &Result{}
+	for _, e := range events {
+		bt.now = e.time
+		if e.fill {
+			if err := bt.fill(e); err != nil {
+				return nil, err
+			}
+		}
+		var err error
+		switch {
+		case e.kline != nil && strategy.OnKline != nil:
+			err = strategy.OnKline(b, e.symbol, e.kline)
+		case e.trade != nil && strategy.OnTrade != nil:
+			err = strategy.OnTrade(b, e.symbol, e.trade)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "strategy failed at %s", e.time)
+		}
+		res.addEquity(e.time, bt.equity())
+	}
+
+	for _, symbol := range bt.exchange.Symbols() {
+		for _, t := range bt.exchange.MyTrades(symbol.Name, 0, 0) {
+			res.Fills = append(res.Fills, &Fill{Symbol: symbol.Name, Trade: t})
+		}
+	}
+	sort.SliceStable(res.Fills, func(i, j int) bool { return res.Fills[i].ID < res.Fills[j].ID })
+	res.Stats = stats(res, bt.symbols, bt.cfg.Valuation)
+	return res, nil
+}
+
+// fill fills resting orders by the event and prepares liquidity for orders
+// placed by the strategy.
+func (bt *Backtest) fill(e *event) error {
+	if e.trade != nil {
+		bt.last[e.symbol] = e.trade.Price
+		if err := bt.exchange.ExecuteTrade(e.symbol, e.trade.Price, e.trade.Quantity, e.trade.BuyerMaker); err != nil {
+			return err
+		}
+		return bt.liquidity(e.symbol, e.trade.Price, e.trade.Quantity)
+	}
+
+	k := e.kline
+	bt.last[e.symbol] = k.Close
+	var crossed []binance.Decimal
+	switch bt.cfg.Fill.Price {
+	case FillClose:
+		crossed = []binance.Decimal{k.Close}
+	case FillNextOpen:
+		crossed = []binance.Decimal{k.Open, k.Close}
+	case FillHighLow:
+		crossed = []binance.Decimal{k.Low, k.High}
+	default:
+		return errors.Errorf("unknown fill price %s", bt.cfg.Fill.Price)
+	}
+	for _, price := range crossed {
+		if err := bt.exchange.ExecuteTrade(e.symbol, price, k.Volume, false); err != nil {
+			return err
+		}
+	}
+
+	price, qty := k.Close, k.Volume
+	if bt.cfg.Fill.Price != FillClose && e.next != nil {
+		price, qty = e.next.Open, e.next.Volume
+	}
+	return bt.liquidity(e.symbol, price, qty)
+}
+
+// liquidity replaces the book of the symbol by qty on both sides of price
+// moved by slippage.
+func (bt *Backtest) liquidity(symbol string, price, qty binance.Decimal) error {
+	bt.exchange.ClearLiquidity(symbol)
+	one := binance.NewDecimalFromInt(1)
+	slippage := bt.cfg.Fill.Slippage
+	if err := bt.exchange.SetLiquidity(symbol, binance.SideSell, price.Mul(one.Add(slippage)), qty); err != nil {
+		return err
+	}
+	return bt.exchange.SetLiquidity(symbol, binance.SideBuy, price.Mul(one.Sub(slippage)), qty)
+}
+
+// equity returns value of all balances in valuation asset.
+func (bt *Backtest) equity() binance.Decimal {
+	var equity binance.Decimal
+	for _, b := range bt.exchange.Account().Balances {
+		total := b.Free.Add(b.Locked)
+		if b.Asset == bt.cfg.Valuation {
+			equity = equity.Add(total)
+			continue
+		}
+		for name, s := range bt.symbols {
+			if s.BaseAsset == b.Asset && s.QuoteAsset == bt.cfg.Valuation {
+				equity = equity.Add(total.Mul(bt.last[name]))
+				break
+			}
+		}
+	}
+	return equity
+}
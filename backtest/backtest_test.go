@@ -0,0 +1,354 @@
+package backtest
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rootpd/binance"
+	"github.com/rootpd/binance/sim"
+)
+
+var d = binance.MustParseDecimal
+
+var bnbusdt = sim.Symbol{Name: "BNBUSDT", BaseAsset: "BNB", QuoteAsset: "USDT"}
+
+var start = time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// hourly creates hourly klines from open, high, low and close prices with volume 10.
+func hourly(ohlc ...[4]string) []*binance.Kline {
+	var klines []*binance.Kline
+	for i, p := range ohlc {
+		open := start.Add(time.Duration(i) * time.Hour)
+		klines = append(klines, &binance.Kline{
+			OpenTime:  open,
+			Open:      d(p[0]),
+			High:      d(p[1]),
+			Low:       d(p[2]),
+			Close:     d(p[3]),
+			Volume:    d("10"),
+			CloseTime: open.Add(time.Hour - time.Millisecond),
+		})
+	}
+	return klines
+}
+
+func marketOrder(side binance.OrderSide, qty string) binance.NewOrderRequest {
+	return binance.NewOrderRequest{Symbol: "BNBUSDT", Side: side, Type: binance.TypeMarket, Quantity: d(qty)}
+}
+
+func TestFillModels(t *testing.T) {
+	klines := hourly(
+		[4]string{"10", "11", "9", "10"},
+		[4]string{"12", "13", "8", "11"},
+		[4]string{"11", "12", "10", "12"},
+	)
+	for _, test := range []struct {
+		fill  FillPrice
+		price string
+	}{
+		{FillClose, "10.01"},
+		{FillNextOpen, "12.012"},
+		{FillHighLow, "12.012"},
+	} {
+		bt := New(Config{
+			Fill:      FillModel{Price: test.fill, Slippage: d("0.001"), TakerFee: d("0.01")},
+			Balances:  map[string]binance.Decimal{"USDT": d("100")},
+			Valuation: "USDT",
+		})
+		bt.AddKlines(bnbusdt, binance.Hour, klines)
+		res, err := bt.Run(Strategy{OnKline: func(b binance.Binance, symbol string, k *binance.Kline) error {
+			if k.OpenTime.Equal(start) {
+				_, err := b.NewOrder(marketOrder(binance.SideBuy, "2"))
+				return err
+			}
+			return nil
+		}})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.fill, err)
+		}
+		if len(res.Fills) != 1 || !res.Fills[0].Price.Equal(d(test.price)) || res.Fills[0].IsMaker {
+			t.Errorf("%s: unexpected fills: %+v", test.fill, res.Fills)
+			continue
+		}
+		if f := res.Fills[0]; f.Symbol != "BNBUSDT" || !f.Commission.Equal(d("0.02")) || f.CommissionAsset != "BNB" {
+			t.Errorf("%s: unexpected fill: %+v", test.fill, f)
+		}
+		// 1.98 BNB valued at last close and the rest of USDT
+		cost := d(test.price).Mul(d("2"))
+		if final := d("100").Sub(cost).Add(d("1.98").Mul(d("12"))); !res.Stats.FinalEquity.Equal(final) {
+			t.Errorf("%s: expected final equity %s, got %s", test.fill, final, res.Stats.FinalEquity)
+		}
+	}
+}
+
+func TestLimitOrders(t *testing.T) {
+	klines := hourly(
+		[4]string{"10", "11", "9", "10"},
+		[4]string{"10", "10.5", "9.5", "10"},
+		[4]string{"10", "10", "8", "9.6"},
+	)
+	for _, test := range []struct {
+		fill   FillPrice
+		filled bool
+	}{
+		{FillClose, false},
+		{FillNextOpen, false},
+		{FillHighLow, true},
+	} {
+		bt := New(Config{
+			Fill:      FillModel{Price: test.fill, MakerFee: d("0.001"), TakerFee: d("0.01")},
+			Balances:  map[string]binance.Decimal{"USDT": d("100")},
+			Valuation: "USDT",
+		})
+		bt.AddKlines(bnbusdt, binance.Hour, klines)
+		res, err := bt.Run(Strategy{OnKline: func(b binance.Binance, symbol string, k *binance.Kline) error {
+			if k.OpenTime.Equal(start) {
+				_, err := b.NewOrder(binance.NewOrderRequest{
+					Symbol: "BNBUSDT", Side: binance.SideBuy, Type: binance.TypeLimit, TimeInForce: binance.GTC,
+					Quantity: d("1"), Price: d("9.5"),
+				})
+				return err
+			}
+			return nil
+		}})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.fill, err)
+		}
+		if filled := len(res.Fills) == 1; filled != test.filled {
+			t.Errorf("%s: expected filled %v, got fills %+v", test.fill, test.filled, res.Fills)
+			continue
+		}
+		if test.filled {
+			f := res.Fills[0]
+			if !f.IsMaker || !f.Price.Equal(d("9.5")) || !f.Commission.Equal(d("0.001")) || !f.Time.Equal(klines[1].CloseTime) {
+				t.Errorf("%s: unexpected fill: %+v", test.fill, f)
+			}
+		}
+	}
+}
+
+func TestNoLookahead(t *testing.T) {
+	klines := hourly(
+		[4]string{"10", "11", "9", "10"},
+		[4]string{"10", "11", "9", "11"},
+		[4]string{"11", "12", "10", "12"},
+	)
+	bt := New(Config{})
+	bt.AddKlines(bnbusdt, binance.Hour, klines)
+	var seen []int
+	_, err := bt.Run(Strategy{OnKline: func(b binance.Binance, symbol string, k *binance.Kline) error {
+		history, err := b.Klines(binance.KlinesRequest{Symbol: symbol, Interval: binance.Hour})
+		if err != nil {
+			return err
+		}
+		if last := history[len(history)-1]; last != k {
+			t.Errorf("expected last kline %+v, got %+v", k, last)
+		}
+		seen = append(seen, len(history))
+		prices, _ := b.TickerAllPrices()
+		if len(prices) != 1 || !prices[0].Price.Equal(k.Close) {
+			t.Errorf("unexpected prices: %+v", prices)
+		}
+		if _, err := b.OrderBook(binance.OrderBookRequest{Symbol: symbol}); err == nil {
+			t.Error("expected order book to be unavailable")
+		}
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(seen) != 3 || seen[0] != 1 || seen[2] != 3 {
+		t.Errorf("unexpected history lengths: %v", seen)
+	}
+}
+
+func TestStats(t *testing.T) {
+	klines := hourly(
+		[4]string{"10", "10", "10", "10"},
+		[4]string{"10", "12", "10", "12"},
+		[4]string{"12", "12", "9", "9"},
+		[4]string{"9", "12", "9", "12"},
+		[4]string{"12", "12", "8", "8"},
+	)
+	bt := New(Config{
+		Balances:  map[string]binance.Decimal{"USDT": d("100")},
+		Valuation: "USDT",
+	})
+	bt.AddKlines(bnbusdt, binance.Hour, klines)
+	res, err := bt.Run(Strategy{OnKline: func(b binance.Binance, symbol string, k *binance.Kline) error {
+		switch k.OpenTime.Sub(start) / time.Hour {
+		case 0, 2:
+			_, err := b.NewOrder(marketOrder(binance.SideBuy, "5"))
+			return err
+		case 1:
+			_, err := b.NewOrder(marketOrder(binance.SideSell, "5"))
+			return err
+		case 3:
+			_, err := b.NewOrder(marketOrder(binance.SideSell, "2"))
+			return err
+		}
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := res.Stats
+	// equity 100, 110, 110, 125, 113
+	if len(res.Equity) != 5 || !s.InitialEquity.Equal(d("100")) || !s.FinalEquity.Equal(d("113")) {
+		t.Errorf("unexpected equity: %+v", s)
+	}
+	if math.Abs(s.Return-0.13) > 1e-9 || math.Abs(s.MaxDrawdown-12.0/125) > 1e-9 || s.Sharpe <= 0 {
+		t.Errorf("unexpected return %v, drawdown %v or sharpe %v", s.Return, s.MaxDrawdown, s.Sharpe)
+	}
+	if s.Fills != 4 || s.Buys != 2 || s.Sells != 2 || s.ClosedTrades != 2 || s.Wins != 2 || s.WinRate != 1 {
+		t.Errorf("unexpected trade stats: %+v", s)
+	}
+	if !s.GrossProfit.Equal(d("16")) || !s.GrossLoss.IsZero() || !s.Start.Equal(klines[0].CloseTime) {
+		t.Errorf("unexpected profit: %+v", s)
+	}
+}
+
+func TestTrades(t *testing.T) {
+	var trades []*binance.AggTrade
+	for i, p := range []string{"10", "10.5", "9.8", "10.7"} {
+		trades = append(trades, &binance.AggTrade{
+			ID: i + 1, Price: d(p), Quantity: d("3"), Timestamp: start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	bt := New(Config{Balances: map[string]binance.Decimal{"USDT": d("100")}, Valuation: "USDT"})
+	bt.AddTrades(bnbusdt, trades)
+	res, err := bt.Run(Strategy{OnTrade: func(b binance.Binance, symbol string, t *binance.AggTrade) error {
+		switch t.ID {
+		case 1:
+			_, err := b.NewOrder(marketOrder(binance.SideBuy, "1"))
+			return err
+		case 2:
+			_, err := b.NewOrder(binance.NewOrderRequest{
+				Symbol: symbol, Side: binance.SideSell, Type: binance.TypeLimit, TimeInForce: binance.GTC,
+				Quantity: d("1"), Price: d("10.6"),
+			})
+			return err
+		}
+		history, _ := b.AggTrades(binance.AggTradesRequest{Symbol: symbol})
+		if len(history) != t.ID {
+			return binance.ErrInsufficientBalance
+		}
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Fills) != 2 || !res.Fills[0].Price.Equal(d("10")) || !res.Fills[1].Price.Equal(d("10.6")) || !res.Fills[1].IsMaker {
+		t.Fatalf("unexpected fills: %+v", res.Fills)
+	}
+	if !res.Stats.FinalEquity.Equal(d("100.6")) {
+		t.Errorf("unexpected final equity: %s", res.Stats.FinalEquity)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	if _, err := New(Config{}).Run(Strategy{}); err == nil {
+		t.Error("expected error without history")
+	}
+	bt := New(Config{})
+	bt.AddKlines(bnbusdt, binance.Hour, hourly([4]string{"10", "10", "10", "10"}))
+	_, err := bt.Run(Strategy{OnKline: func(b binance.Binance, symbol string, k *binance.Kline) error {
+		_, err := b.NewOrder(marketOrder(binance.SideBuy, "1"))
+		return err
+	}})
+	if err == nil || !strings.Contains(err.Error(), "insufficient balance") {
+		t.Errorf("expected insufficient balance error, got %v", err)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	klines, err := ReadKlinesCSV(strings.NewReader(
+		"open_time,open,high,low,close,volume,close_time,quote_volume,count,taker_buy_volume,taker_buy_quote_volume,ignore\n" +
+			"1514764800000,10.1,11,9.5,10.5,100,1514768399999,1050,42,60,630,0\n" +
+			"1514768400000000,10.5,10.6,10,10.2,50,1514771999999999,510,7,20,204,0\n",
+	))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(klines) != 2 || !klines[0].OpenTime.Equal(start) || !klines[0].High.Equal(d("11")) || klines[0].NumberOfTrades != 42 {
+		t.Fatalf("unexpected klines: %+v", klines)
+	}
+	if !klines[1].OpenTime.Equal(start.Add(time.Hour)) || !klines[1].TakerBuyQuoteAssetVolume.Equal(d("204")) {
+		t.Errorf("unexpected kline: %+v", klines[1])
+	}
+
+	trades, err := ReadAggTradesCSV(strings.NewReader("26129,0.01633102,4.7,27781,27781,1514764800000,True,True\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trades) != 1 || trades[0].ID != 26129 || !trades[0].Quantity.Equal(d("4.7")) || !trades[0].BuyerMaker || !trades[0].Timestamp.Equal(start) {
+		t.Errorf("unexpected trades: %+v", trades)
+	}
+
+	if _, err := ReadAggTradesCSV(strings.NewReader("1,x,1,1,1,1514764800000,true,true\n")); err == nil {
+		t.Error("expected error for malformed price")
+	}
+	if _, err := ReadKlinesCSV(strings.NewReader("1514764800000,10\n")); err == nil {
+		t.Error("expected error for missing columns")
+	}
+}
+
+// pagedService serves klines and aggregate trades of every minute of 2018-01-01.
+type pagedService struct {
+	binance.Service
+	calls int
+}
+
+func (s *pagedService) Klines(ctx context.Context, kr binance.KlinesRequest) ([]*binance.Kline, error) {
+	s.calls++
+	var klines []*binance.Kline
+	// open times are aligned to minutes
+	for ms := (kr.StartTime + 59999) / 60000 * 60000; len(klines) < kr.Limit && ms <= kr.EndTime; ms += 60000 {
+		klines = append(klines, &binance.Kline{OpenTime: time.Unix(0, ms*int64(time.Millisecond))})
+	}
+	return klines, nil
+}
+
+func (s *pagedService) AggTrades(ctx context.Context, atr binance.AggTradesRequest) ([]*binance.AggTrade, error) {
+	s.calls++
+	id := atr.FromID
+	if id == 0 {
+		id = (atr.StartTime - toMs(start)) / 60000
+	}
+	var trades []*binance.AggTrade
+	for ; len(trades) < atr.Limit && id < 24*60; id++ {
+		trades = append(trades, &binance.AggTrade{ID: int(id), Timestamp: start.Add(time.Duration(id) * time.Minute)})
+	}
+	return trades, nil
+}
+
+func TestFetch(t *testing.T) {
+	svc := &pagedService{}
+	klines, err := FetchKlines(context.Background(), svc, binance.KlinesRequest{
+		Symbol: "BNBUSDT", Interval: binance.Minute, StartTime: toMs(start), EndTime: toMs(start.Add(24*time.Hour)) - 1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(klines) != 24*60 || svc.calls != 2 || !klines[1439].OpenTime.Equal(start.Add(1439*time.Minute)) {
+		t.Errorf("unexpected %d klines in %d calls", len(klines), svc.calls)
+	}
+
+	svc = &pagedService{}
+	trades, err := FetchAggTrades(context.Background(), svc, binance.AggTradesRequest{
+		Symbol: "BNBUSDT", StartTime: toMs(start.Add(time.Hour)), EndTime: toMs(start.Add(23 * time.Hour)),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trades) != 22*60+1 || svc.calls != 2 || trades[0].ID != 60 || trades[len(trades)-1].ID != 23*60 {
+		t.Errorf("unexpected %d trades in %d calls", len(trades), svc.calls)
+	}
+
+	if _, err := FetchKlines(context.Background(), svc, binance.KlinesRequest{Symbol: "BNBUSDT"}); err == nil {
+		t.Error("expected error without start time")
+	}
+}
@@ -0,0 +1,204 @@
+package backtest
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rootpd/binance"
+)
+
+// pageSize is maximum number of klines and aggregate trades returned by single call.
+const pageSize = 1000
+
+// FetchKlines downloads all klines from kr.StartTime to kr.EndTime page by page.
+// Zero EndTime means up to now, kr.Limit is ignored.
+func FetchKlines(ctx context.Context, svc binance.Service, kr binance.KlinesRequest) ([]*binance.Kline, error) {
+	if kr.StartTime == 0 {
+		return nil, errors.New("start time is required to fetch klines")
+	}
+	kr.Limit = pageSize
+	var klines []*binance.Kline
+	for {
+		page, err := svc.Klines(ctx, kr)
+		if err != nil {
+			return nil, err
+		}
+		klines = append(klines, page...)
+		if len(page) < pageSize {
+			return klines, nil
+		}
+		kr.StartTime = toMs(page[len(page)-1].OpenTime) + 1
+		if kr.EndTime != 0 && kr.StartTime > kr.EndTime {
+			return klines, nil
+		}
+	}
+}
+
+// FetchAggTrades downloads all aggregate trades from atr.FromID or
+// atr.StartTime to atr.EndTime page by page. Zero EndTime means up to now,
+// atr.Limit is ignored.
+func FetchAggTrades(ctx context.Context, svc binance.Service, atr binance.AggTradesRequest) ([]*binance.AggTrade, error) {
+	if atr.FromID == 0 && atr.StartTime == 0 {
+		return nil, errors.New("start time or from ID is required to fetch aggregate trades")
+	}
+	endTime := atr.EndTime
+	if atr.FromID != 0 {
+		atr.StartTime, atr.EndTime = 0, 0
+	} else if endTime != 0 && endTime-atr.StartTime > int64(time.Hour/time.Millisecond) {
+		// API accepts at most an hour between start and end, following pages are
+		// requested by ID
+		atr.EndTime = 0
+	}
+	atr.Limit = pageSize
+	var trades []*binance.AggTrade
+	for {
+		page, err := svc.AggTrades(ctx, atr)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range page {
+			if endTime != 0 && toMs(t.Timestamp) > endTime {
+				return trades, nil
+			}
+			trades = append(trades, t)
+		}
+		if len(page) < pageSize {
+			return trades, nil
+		}
+		atr.FromID = int64(page[len(page)-1].ID) + 1
+		atr.StartTime, atr.EndTime = 0, 0
+	}
+}
+
+// ReadKlinesCSV reads klines in format of Binance public data dumps: open time,
+// open, high, low, close, volume, close time, quote asset volume, number of
+// trades, taker buy base and quote asset volume. Header row and trailing
+// columns are ignored.
+func ReadKlinesCSV(r io.Reader) ([]*binance.Kline, error) {
+	var klines []*binance.Kline
+	err := readCSV(r, 11, func(p *csvParser) {
+		klines = append(klines, &binance.Kline{
+			OpenTime:                 p.time(0),
+			Open:                     p.decimal(1),
+			High:                     p.decimal(2),
+			Low:                      p.decimal(3),
+			Close:                    p.decimal(4),
+			Volume:                   p.decimal(5),
+			CloseTime:                p.time(6),
+			QuoteAssetVolume:         p.decimal(7),
+			NumberOfTrades:           p.int(8),
+			TakerBuyBaseAssetVolume:  p.decimal(9),
+			TakerBuyQuoteAssetVolume: p.decimal(10),
+		})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read klines")
+	}
+	return klines, nil
+}
+
+// ReadAggTradesCSV reads aggregate trades in format of Binance public data
+// dumps: ID, price, quantity, first trade ID, last trade ID, timestamp, buyer
+// maker and best price match. Header row is ignored.
+func ReadAggTradesCSV(r io.Reader) ([]*binance.AggTrade, error) {
+	var trades []*binance.AggTrade
+	err := readCSV(r, 8, func(p *csvParser) {
+		trades = append(trades, &binance.AggTrade{
+			ID:             p.int(0),
+			Price:          p.decimal(1),
+			Quantity:       p.decimal(2),
+			FirstTradeID:   p.int(3),
+			LastTradeID:    p.int(4),
+			Timestamp:      p.time(5),
+			BuyerMaker:     p.bool(6),
+			BestPriceMatch: p.bool(7),
+		})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read aggregate trades")
+	}
+	return trades, nil
+}
+
+func readCSV(r io.Reader, columns int, parse func(p *csvParser)) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(record) < columns {
+			return errors.Errorf("line %d: expected %d columns, got %d", line, columns, len(record))
+		}
+		if _, err := strconv.ParseInt(record[0], 10, 64); err != nil && line == 1 {
+			// header
+			continue
+		}
+		p := &csvParser{record: record}
+		parse(p)
+		if p.err != nil {
+			return errors.Wrapf(p.err, "line %d", line)
+		}
+	}
+}
+
+// csvParser parses columns of a record, the first failure is kept in err.
+type csvParser struct {
+	record []string
+	err    error
+}
+
+func (p *csvParser) fail(i int, err error) {
+	if p.err == nil {
+		p.err = errors.Wrapf(err, "column %d", i+1)
+	}
+}
+
+func (p *csvParser) int(i int) int {
+	v, err := strconv.Atoi(p.record[i])
+	if err != nil {
+		p.fail(i, err)
+	}
+	return v
+}
+
+func (p *csvParser) decimal(i int) binance.Decimal {
+	v, err := binance.ParseDecimal(p.record[i])
+	if err != nil {
+		p.fail(i, err)
+	}
+	return v
+}
+
+func (p *csvParser) bool(i int) bool {
+	v, err := strconv.ParseBool(p.record[i])
+	if err != nil {
+		p.fail(i, err)
+	}
+	return v
+}
+
+// time parses timestamp in milliseconds, dumps since 2025 use microseconds.
+func (p *csvParser) time(i int) time.Time {
+	v, err := strconv.ParseInt(p.record[i], 10, 64)
+	if err != nil {
+		p.fail(i, err)
+	}
+	if v > 1e14 {
+		return time.Unix(0, v*int64(time.Microsecond))
+	}
+	return time.Unix(0, v*int64(time.Millisecond))
+}
+
+func toMs(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
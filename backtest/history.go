@@ -0,0 +1,203 @@
+package backtest
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rootpd/binance"
+	"github.com/rootpd/binance/sim"
+)
+
+// errUnavailable is returned by market data calls which can't be answered from
+// replayed history.
+var errUnavailable = errors.New("not available in backtest")
+
+// history serves market data calls of the strategy from replayed history up to
+// the current time. Trading calls are served by paper.Service wrapping it.
+type history struct {
+	binance.Service
+
+	exchange *sim.Exchange
+	klines   map[string]map[binance.Interval][]*binance.Kline
+	trades   map[string][]*binance.AggTrade
+	last     map[string]binance.Decimal
+}
+
+func (h *history) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (h *history) Time(ctx context.Context) (time.Time, error) {
+	return h.exchange.Now(), nil
+}
+
+// OrderBook isn't available, the book of the simulated exchange holds
+// liquidity at fill price which may come from the future.
+func (h *history) OrderBook(ctx context.Context, obr binance.OrderBookRequest) (*binance.OrderBook, error) {
+	return nil, errUnavailable
+}
+
+func (h *history) AggTrades(ctx context.Context, atr binance.AggTradesRequest) ([]*binance.AggTrade, error) {
+	trades, ok := h.trades[atr.Symbol]
+	if !ok {
+		return nil, &binance.Error{Code: -1121, Message: "Invalid symbol.", StatusCode: 400}
+	}
+	now := h.exchange.Now()
+	var res []*binance.AggTrade
+	for _, t := range trades {
+		if t.Timestamp.After(now) {
+			break
+		}
+		if int64(t.ID) < atr.FromID || !inRange(t.Timestamp, atr.StartTime, atr.EndTime) {
+			continue
+		}
+		res = append(res, t)
+	}
+	i, j := window(len(res), atr.Limit, atr.FromID != 0 || atr.StartTime != 0)
+	return res[i:j], nil
+}
+
+func (h *history) Trades(ctx context.Context, tr binance.TradesRequest) ([]*binance.MarketTrade, error) {
+	return nil, errUnavailable
+}
+
+func (h *history) HistoricalTrades(ctx context.Context, htr binance.HistoricalTradesRequest) ([]*binance.MarketTrade, error) {
+	return nil, errUnavailable
+}
+
+func (h *history) Klines(ctx context.Context, kr binance.KlinesRequest) ([]*binance.Kline, error) {
+	byInterval, ok := h.klines[kr.Symbol]
+	if !ok {
+		return nil, &binance.Error{Code: -1121, Message: "Invalid symbol.", StatusCode: 400}
+	}
+	klines, ok := byInterval[kr.Interval]
+	if !ok {
+		return nil, errors.Wrapf(errUnavailable, "klines of interval %s", kr.Interval)
+	}
+	now := h.exchange.Now()
+	var res []*binance.Kline
+	for _, k := range klines {
+		if k.CloseTime.After(now) {
+			break
+		}
+		if inRange(k.OpenTime, kr.StartTime, kr.EndTime) {
+			res = append(res, k)
+		}
+	}
+	i, j := window(len(res), kr.Limit, kr.StartTime != 0)
+	return res[i:j], nil
+}
+
+func (h *history) Ticker24(ctx context.Context, tr binance.TickerRequest) (*binance.Ticker24, error) {
+	return nil, errUnavailable
+}
+
+func (h *history) TickerAll24(ctx context.Context) ([]*binance.Ticker24, error) {
+	return nil, errUnavailable
+}
+
+// TickerPrice returns last replayed price of the symbol.
+func (h *history) TickerPrice(ctx context.Context, tr binance.TickerRequest) (*binance.PriceTicker, error) {
+	price, ok := h.last[tr.Symbol]
+	if !ok {
+		return nil, errors.Wrapf(errUnavailable, "price of %s", tr.Symbol)
+	}
+	return &binance.PriceTicker{Symbol: tr.Symbol, Price: price}, nil
+}
+
+// TickerAllPrices returns last replayed price of every symbol.
+func (h *history) TickerAllPrices(ctx context.Context) ([]*binance.PriceTicker, error) {
+	var prices []*binance.PriceTicker
+	for symbol, price := range h.last {
+		prices = append(prices, &binance.PriceTicker{Symbol: symbol, Price: price})
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Symbol < prices[j].Symbol })
+	return prices, nil
+}
+
+func (h *history) TickerAllBooks(ctx context.Context) ([]*binance.BookTicker, error) {
+	return nil, errUnavailable
+}
+
+func (h *history) TickerBook(ctx context.Context, tr binance.TickerRequest) (*binance.BookTicker, error) {
+	return nil, errUnavailable
+}
+
+// AvgPrice returns volume weighted price of trades replayed within last 5
+// minutes, last replayed price if there were none.
+func (h *history) AvgPrice(ctx context.Context, apr binance.AvgPriceRequest) (*binance.AvgPrice, error) {
+	price, ok := h.last[apr.Symbol]
+	if !ok {
+		return nil, errors.Wrapf(errUnavailable, "price of %s", apr.Symbol)
+	}
+	now := h.exchange.Now()
+	since := now.Add(-5 * time.Minute)
+	var volume, quote binance.Decimal
+	for _, t := range h.trades[apr.Symbol] {
+		if t.Timestamp.After(now) {
+			break
+		}
+		if t.Timestamp.After(since) {
+			volume = volume.Add(t.Quantity)
+			quote = quote.Add(t.Price.Mul(t.Quantity))
+		}
+	}
+	if volume.Sign() > 0 {
+		price = quote.Div(volume, 8)
+	}
+	return &binance.AvgPrice{Mins: 5, Price: price}, nil
+}
+
+// ExchangeInfo lists replayed symbols as trading.
+func (h *history) ExchangeInfo(ctx context.Context) (*binance.ExchangeInfo, error) {
+	ei := &binance.ExchangeInfo{Timezone: "UTC", ServerTime: h.exchange.Now()}
+	for _, s := range h.exchange.Symbols() {
+		ei.Symbols = append(ei.Symbols, &binance.SymbolInfo{
+			Symbol:     s.Name,
+			Status:     binance.SymbolTrading,
+			BaseAsset:  s.BaseAsset,
+			QuoteAsset: s.QuoteAsset,
+			OrderTypes: []binance.OrderType{binance.TypeLimit, binance.TypeMarket},
+			Filters:    s.Filters,
+		})
+	}
+	return ei, nil
+}
+
+func (h *history) DepthWebsocket(ctx context.Context, dwr binance.DepthWebsocketRequest) (chan *binance.DepthEvent, chan struct{}, error) {
+	return nil, nil, errUnavailable
+}
+
+func (h *history) CombinedDepthWebsocket(ctx context.Context, cdwr binance.CombinedDepthWebsocketRequest) (chan *binance.DepthEvent, chan struct{}, error) {
+	return nil, nil, errUnavailable
+}
+
+func (h *history) KlineWebsocket(ctx context.Context, kwr binance.KlineWebsocketRequest) (chan *binance.KlineEvent, chan struct{}, error) {
+	return nil, nil, errUnavailable
+}
+
+func (h *history) TradeWebsocket(ctx context.Context, twr binance.TradeWebsocketRequest) (chan *binance.AggTradeEvent, chan struct{}, error) {
+	return nil, nil, errUnavailable
+}
+
+// window returns bounds of at most limit items, 500 by default. Like the API,
+// first items are returned if the request has start, the most recent otherwise.
+func window(n, limit int, fromStart bool) (int, int) {
+	if limit <= 0 {
+		limit = 500
+	}
+	if n <= limit {
+		return 0, n
+	}
+	if fromStart {
+		return 0, limit
+	}
+	return n - limit, n
+}
+
+func inRange(t time.Time, start, end int64) bool {
+	ms := t.UnixNano() / int64(time.Millisecond)
+	return (start == 0 || ms >= start) && (end == 0 || ms <= end)
+}
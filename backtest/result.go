@@ -0,0 +1,181 @@
+package backtest
+
+import (
+	"math"
+	"time"
+
+	"github.com/rootpd/binance"
+	"github.com/rootpd/binance/sim"
+)
+
+// Result is outcome of backtest.
+type Result struct {
+	Fills  []*Fill
+	Equity []*EquityPoint
+	Stats  Stats
+}
+
+// Fill is trade of the account.
+type Fill struct {
+	Symbol string
+	*binance.Trade
+}
+
+// EquityPoint is value of the account in valuation asset after replayed event.
+type EquityPoint struct {
+	Time   time.Time
+	Equity binance.Decimal
+}
+
+// Stats summarizes the result.
+type Stats struct {
+	Start         time.Time
+	End           time.Time
+	InitialEquity binance.Decimal
+	FinalEquity   binance.Decimal
+	// Return is relative change of equity, e.g. 0.05 for 5% gain.
+	Return float64
+	// MaxDrawdown is the largest relative decline of equity from its peak.
+	MaxDrawdown float64
+	// Sharpe is annualized Sharpe ratio of returns between equity points with
+	// zero risk-free rate.
+	Sharpe float64
+
+	Fills int
+	Buys  int
+	Sells int
+	// Fees are paid commissions per asset.
+	Fees map[string]binance.Decimal
+
+	// Closed trades are sells matched with earlier buys of the symbol first in
+	// first out. Only symbols quoted in valuation asset are counted.
+	ClosedTrades int
+	Wins         int
+	WinRate      float64
+	GrossProfit  binance.Decimal
+	GrossLoss    binance.Decimal
+	// ProfitFactor is GrossProfit divided by GrossLoss, zero without losses.
+	ProfitFactor float64
+}
+
+func (r *Result) addEquity(t time.Time, equity binance.Decimal) {
+	if n := len(r.Equity); n > 0 && r.Equity[n-1].Time.Equal(t) {
+		r.Equity[n-1].Equity = equity
+		return
+	}
+	r.Equity = append(r.Equity, &EquityPoint{Time: t, Equity: equity})
+}
+
+const year = 365 * 24 * time.Hour
+
+func stats(r *Result, symbols map[string]sim.Symbol, valuation string) Stats {
+	s := Stats{Fees: make(map[string]binance.Decimal)}
+	if len(r.Equity) > 0 {
+		first, last := r.Equity[0], r.Equity[len(r.Equity)-1]
+		s.Start, s.End = first.Time, last.Time
+		s.InitialEquity, s.FinalEquity = first.Equity, last.Equity
+		if first.Equity.Sign() > 0 {
+			s.Return = last.Equity.Float64()/first.Equity.Float64() - 1
+		}
+	}
+
+	var peak float64
+	var returns []float64
+	for i, p := range r.Equity {
+		equity := p.Equity.Float64()
+		if equity > peak {
+			peak = equity
+		}
+		if peak > 0 {
+			s.MaxDrawdown = math.Max(s.MaxDrawdown, (peak-equity)/peak)
+		}
+		if i == 0 {
+			continue
+		}
+		if prev := r.Equity[i-1].Equity.Float64(); prev > 0 {
+			returns = append(returns, equity/prev-1)
+		}
+	}
+	if len(returns) > 1 {
+		mean, std := meanStd(returns)
+		period := s.End.Sub(s.Start) / time.Duration(len(returns))
+		if std > 0 && period > 0 {
+			s.Sharpe = mean / std * math.Sqrt(float64(year)/float64(period))
+		}
+	}
+
+	// lots are open buys of the symbol, cost includes commission
+	type lot struct {
+		qty, price binance.Decimal
+	}
+	lots := make(map[string][]*lot)
+	for _, f := range r.Fills {
+		s.Fills++
+		s.Fees[f.CommissionAsset] = s.Fees[f.CommissionAsset].Add(f.Commission)
+		if f.IsBuyer {
+			s.Buys++
+		} else {
+			s.Sells++
+		}
+		if symbols[f.Symbol].QuoteAsset != valuation {
+			continue
+		}
+		if f.IsBuyer {
+			// commission of buy is paid in base asset, so fewer units cost the same
+			qty := f.Qty.Sub(f.Commission)
+			if qty.Sign() > 0 {
+				lots[f.Symbol] = append(lots[f.Symbol], &lot{qty: qty, price: f.Price.Mul(f.Qty).Div(qty, 16)})
+			}
+			continue
+		}
+
+		var pnl, matched binance.Decimal
+		remaining := f.Qty
+		for remaining.Sign() > 0 && len(lots[f.Symbol]) > 0 {
+			l := lots[f.Symbol][0]
+			qty := l.qty
+			if qty.Cmp(remaining) > 0 {
+				qty = remaining
+			}
+			pnl = pnl.Add(f.Price.Sub(l.price).Mul(qty))
+			matched = matched.Add(qty)
+			remaining = remaining.Sub(qty)
+			l.qty = l.qty.Sub(qty)
+			if l.qty.Sign() == 0 {
+				lots[f.Symbol] = lots[f.Symbol][1:]
+			}
+		}
+		if matched.Sign() == 0 {
+			continue
+		}
+		// commission of sell is paid in quote asset, share of unmatched quantity is ignored
+		pnl = pnl.Sub(f.Commission.Mul(matched).Div(f.Qty, 16))
+		s.ClosedTrades++
+		if pnl.Sign() > 0 {
+			s.Wins++
+			s.GrossProfit = s.GrossProfit.Add(pnl)
+		} else {
+			s.GrossLoss = s.GrossLoss.Add(pnl.Neg())
+		}
+	}
+	if s.ClosedTrades > 0 {
+		s.WinRate = float64(s.Wins) / float64(s.ClosedTrades)
+	}
+	if s.GrossLoss.Sign() > 0 {
+		s.ProfitFactor = s.GrossProfit.Float64() / s.GrossLoss.Float64()
+	}
+	return s
+}
+
+func meanStd(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(values)-1))
+}
@@ -1,7 +1,9 @@
 package binance
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"time"
 )
 
@@ -16,56 +18,140 @@ import (
 type Binance interface {
 	// Ping tests connectivity.
 	Ping() error
+	// PingContext tests connectivity within provided context.
+	PingContext(ctx context.Context) error
 	// Time returns server time.
 	Time() (time.Time, error)
+	// TimeContext returns server time within provided context.
+	TimeContext(ctx context.Context) (time.Time, error)
 	// OrderBook returns list of orders.
 	OrderBook(obr OrderBookRequest) (*OrderBook, error)
+	// OrderBookContext returns list of orders within provided context.
+	OrderBookContext(ctx context.Context, obr OrderBookRequest) (*OrderBook, error)
 	// AggTrades returns compressed/aggregate list of trades.
 	AggTrades(atr AggTradesRequest) ([]*AggTrade, error)
+	// AggTradesContext returns compressed/aggregate list of trades within provided context.
+	AggTradesContext(ctx context.Context, atr AggTradesRequest) ([]*AggTrade, error)
+	// Trades returns recent trades.
+	Trades(tr TradesRequest) ([]*MarketTrade, error)
+	// TradesContext returns recent trades within provided context.
+	TradesContext(ctx context.Context, tr TradesRequest) ([]*MarketTrade, error)
+	// HistoricalTrades returns older trades, API key is required.
+	HistoricalTrades(htr HistoricalTradesRequest) ([]*MarketTrade, error)
+	// HistoricalTradesContext returns older trades within provided context, API key is required.
+	HistoricalTradesContext(ctx context.Context, htr HistoricalTradesRequest) ([]*MarketTrade, error)
 	// Klines returns klines/candlestick data.
 	Klines(kr KlinesRequest) ([]*Kline, error)
+	// KlinesContext returns klines/candlestick data within provided context.
+	KlinesContext(ctx context.Context, kr KlinesRequest) ([]*Kline, error)
 	// Ticker24 returns 24hr price change statistics.
 	Ticker24(tr TickerRequest) (*Ticker24, error)
+	// Ticker24Context returns 24hr price change statistics within provided context.
+	Ticker24Context(ctx context.Context, tr TickerRequest) (*Ticker24, error)
+	// TickerAll24 returns 24hr price change statistics of all symbols.
+	TickerAll24() ([]*Ticker24, error)
+	// TickerAll24Context returns 24hr price change statistics of all symbols within provided context.
+	TickerAll24Context(ctx context.Context) ([]*Ticker24, error)
+	// TickerPrice returns latest price of the symbol.
+	TickerPrice(tr TickerRequest) (*PriceTicker, error)
+	// TickerPriceContext returns latest price of the symbol within provided context.
+	TickerPriceContext(ctx context.Context, tr TickerRequest) (*PriceTicker, error)
+	// TickerBook returns best bid and ask of the symbol.
+	TickerBook(tr TickerRequest) (*BookTicker, error)
+	// TickerBookContext returns best bid and ask of the symbol within provided context.
+	TickerBookContext(ctx context.Context, tr TickerRequest) (*BookTicker, error)
+	// AvgPrice returns current average price of the symbol.
+	AvgPrice(apr AvgPriceRequest) (*AvgPrice, error)
+	// AvgPriceContext returns current average price of the symbol within provided context.
+	AvgPriceContext(ctx context.Context, apr AvgPriceRequest) (*AvgPrice, error)
 	// TickerAllPrices returns ticker data for symbols.
 	TickerAllPrices() ([]*PriceTicker, error)
+	// TickerAllPricesContext returns ticker data for symbols within provided context.
+	TickerAllPricesContext(ctx context.Context) ([]*PriceTicker, error)
 	// TickerAllBooks returns tickers for all books.
 	TickerAllBooks() ([]*BookTicker, error)
+	// TickerAllBooksContext returns tickers for all books within provided context.
+	TickerAllBooksContext(ctx context.Context) ([]*BookTicker, error)
+	// ExchangeInfo returns trading rules and symbol information.
+	ExchangeInfo() (*ExchangeInfo, error)
+	// ExchangeInfoContext returns trading rules and symbol information within provided context.
+	ExchangeInfoContext(ctx context.Context) (*ExchangeInfo, error)
 
 	// NewOrder places new order and returns ProcessedOrder.
 	NewOrder(nor NewOrderRequest) (*ProcessedOrder, error)
+	// NewOrderContext places new order and returns ProcessedOrder within provided context.
+	NewOrderContext(ctx context.Context, nor NewOrderRequest) (*ProcessedOrder, error)
 	// NewOrder places testing order.
 	NewOrderTest(nor NewOrderRequest) error
+	// NewOrderTestContext places testing order within provided context.
+	NewOrderTestContext(ctx context.Context, nor NewOrderRequest) error
 	// QueryOrder returns data about existing order.
 	QueryOrder(qor QueryOrderRequest) (*ExecutedOrder, error)
+	// QueryOrderContext returns data about existing order within provided context.
+	QueryOrderContext(ctx context.Context, qor QueryOrderRequest) (*ExecutedOrder, error)
 	// CancelOrder cancels order.
 	CancelOrder(cor CancelOrderRequest) (*CanceledOrder, error)
+	// CancelOrderContext cancels order within provided context.
+	CancelOrderContext(ctx context.Context, cor CancelOrderRequest) (*CanceledOrder, error)
 	// OpenOrders returns list of open orders.
 	OpenOrders(oor OpenOrdersRequest) ([]*ExecutedOrder, error)
+	// OpenOrdersContext returns list of open orders within provided context.
+	OpenOrdersContext(ctx context.Context, oor OpenOrdersRequest) ([]*ExecutedOrder, error)
 	// AllOrders returns list of all previous orders.
 	AllOrders(aor AllOrdersRequest) ([]*ExecutedOrder, error)
+	// AllOrdersContext returns list of all previous orders within provided context.
+	AllOrdersContext(ctx context.Context, aor AllOrdersRequest) ([]*ExecutedOrder, error)
 
 	// Account returns account data.
 	Account(ar AccountRequest) (*Account, error)
+	// AccountContext returns account data within provided context.
+	AccountContext(ctx context.Context, ar AccountRequest) (*Account, error)
 	// MyTrades list user's trades.
 	MyTrades(mtr MyTradesRequest) ([]*Trade, error)
+	// MyTradesContext list user's trades within provided context.
+	MyTradesContext(ctx context.Context, mtr MyTradesRequest) ([]*Trade, error)
 	// Withdraw executes withdrawal.
 	Withdraw(wr WithdrawRequest) (*WithdrawResult, error)
+	// WithdrawContext executes withdrawal within provided context.
+	WithdrawContext(ctx context.Context, wr WithdrawRequest) (*WithdrawResult, error)
 	// DepositHistory lists deposit data.
 	DepositHistory(hr HistoryRequest) ([]*Deposit, error)
+	// DepositHistoryContext lists deposit data within provided context.
+	DepositHistoryContext(ctx context.Context, hr HistoryRequest) ([]*Deposit, error)
 	// WithdrawHistory lists withdraw data.
 	WithdrawHistory(hr HistoryRequest) ([]*Withdrawal, error)
+	// WithdrawHistoryContext lists withdraw data within provided context.
+	WithdrawHistoryContext(ctx context.Context, hr HistoryRequest) ([]*Withdrawal, error)
 
 	// StartUserDataStream starts stream and returns Stream with ListenKey.
 	StartUserDataStream() (*Stream, error)
+	// StartUserDataStreamContext starts stream and returns Stream with ListenKey within provided context.
+	StartUserDataStreamContext(ctx context.Context) (*Stream, error)
 	// KeepAliveUserDataStream prolongs stream livespan.
 	KeepAliveUserDataStream(s *Stream) error
+	// KeepAliveUserDataStreamContext prolongs stream livespan within provided context.
+	KeepAliveUserDataStreamContext(ctx context.Context, s *Stream) error
 	// CloseUserDataStream closes opened stream.
 	CloseUserDataStream(s *Stream) error
+	// CloseUserDataStreamContext closes opened stream within provided context.
+	CloseUserDataStreamContext(ctx context.Context, s *Stream) error
 
 	DepthWebsocket(dwr DepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error)
+	// DepthWebsocketContext opens stream which is closed when ctx is done.
+	DepthWebsocketContext(ctx context.Context, dwr DepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error)
+	// CombinedDepthWebsocket opens depth streams of multiple symbols over single connection.
+	CombinedDepthWebsocket(cdwr CombinedDepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error)
+	// CombinedDepthWebsocketContext opens combined stream which is closed when ctx is done.
+	CombinedDepthWebsocketContext(ctx context.Context, cdwr CombinedDepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error)
 	KlineWebsocket(kwr KlineWebsocketRequest) (chan *KlineEvent, chan struct{}, error)
+	// KlineWebsocketContext opens stream which is closed when ctx is done.
+	KlineWebsocketContext(ctx context.Context, kwr KlineWebsocketRequest) (chan *KlineEvent, chan struct{}, error)
 	TradeWebsocket(twr TradeWebsocketRequest) (chan *AggTradeEvent, chan struct{}, error)
+	// TradeWebsocketContext opens stream which is closed when ctx is done.
+	TradeWebsocketContext(ctx context.Context, twr TradeWebsocketRequest) (chan *AggTradeEvent, chan struct{}, error)
 	UserDataWebsocket(udwr UserDataWebsocketRequest) (chan *AccountEvent, chan struct{}, error)
+	// UserDataWebsocketContext opens stream which is closed when ctx is done.
+	UserDataWebsocketContext(ctx context.Context, udwr UserDataWebsocketRequest) (chan *AccountEvent, chan struct{}, error)
 }
 
 type binance struct {
@@ -73,13 +159,25 @@ type binance struct {
 }
 
 // Error represents Binance error structure with error code and message.
+//
+// Error can be matched against category errors (e.g. ErrRateLimited) with errors.Is.
 type Error struct {
 	Code    int    `json:"code"`
 	Message string `json:"msg"`
+
+	// StatusCode is HTTP status code of the response.
+	StatusCode int `json:"-"`
+	// Header contains headers of the response.
+	Header http.Header `json:"-"`
+	// Body contains raw response body if it wasn't valid Binance error JSON.
+	Body []byte `json:"-"`
 }
 
 // Error returns formatted error message.
 func (e Error) Error() string {
+	if e.Code == 0 && e.StatusCode != 0 {
+		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+	}
 	return fmt.Sprintf("%d: %s", e.Code, e.Message)
 }
 
@@ -92,12 +190,22 @@ func NewBinance(service Service) Binance {
 
 // Ping tests connectivity.
 func (b *binance) Ping() error {
-	return b.Service.Ping()
+	return b.PingContext(context.Background())
+}
+
+// PingContext tests connectivity within provided context.
+func (b *binance) PingContext(ctx context.Context) error {
+	return b.Service.Ping(ctx)
 }
 
 // Time returns server time.
 func (b *binance) Time() (time.Time, error) {
-	return b.Service.Time()
+	return b.TimeContext(context.Background())
+}
+
+// TimeContext returns server time within provided context.
+func (b *binance) TimeContext(ctx context.Context) (time.Time, error) {
+	return b.Service.Time(ctx)
 }
 
 // OrderBook represents Bids and Asks.
@@ -107,16 +215,20 @@ type OrderBook struct {
 	Asks         []*Order
 }
 
+// DepthEvent represents diff of the order book. FirstUpdateID and UpdateID
+// are the first and the last update ID covered by the event, zero quantity
+// removes the price level.
 type DepthEvent struct {
 	WSEvent
-	UpdateID int
+	FirstUpdateID int
+	UpdateID      int
 	OrderBook
 }
 
 // Order represents single order information.
 type Order struct {
-	Price    float64
-	Quantity float64
+	Price    Decimal
+	Quantity Decimal
 }
 
 // OrderBookRequest represents OrderBook request data.
@@ -127,14 +239,19 @@ type OrderBookRequest struct {
 
 // OrderBook returns list of orders.
 func (b *binance) OrderBook(obr OrderBookRequest) (*OrderBook, error) {
-	return b.Service.OrderBook(obr)
+	return b.OrderBookContext(context.Background(), obr)
+}
+
+// OrderBookContext returns list of orders within provided context.
+func (b *binance) OrderBookContext(ctx context.Context, obr OrderBookRequest) (*OrderBook, error) {
+	return b.Service.OrderBook(ctx, obr)
 }
 
 // AggTrade represents aggregated trade.
 type AggTrade struct {
 	ID             int
-	Price          float64
-	Quantity       float64
+	Price          Decimal
+	Quantity       Decimal
 	FirstTradeID   int
 	LastTradeID    int
 	Timestamp      time.Time
@@ -158,7 +275,57 @@ type AggTradesRequest struct {
 
 // AggTrades returns compressed/aggregate list of trades.
 func (b *binance) AggTrades(atr AggTradesRequest) ([]*AggTrade, error) {
-	return b.Service.AggTrades(atr)
+	return b.AggTradesContext(context.Background(), atr)
+}
+
+// AggTradesContext returns compressed/aggregate list of trades within provided context.
+func (b *binance) AggTradesContext(ctx context.Context, atr AggTradesRequest) ([]*AggTrade, error) {
+	return b.Service.AggTrades(ctx, atr)
+}
+
+// MarketTrade represents single trade of the symbol.
+type MarketTrade struct {
+	ID           int64
+	Price        Decimal
+	Qty          Decimal
+	QuoteQty     Decimal
+	Time         time.Time
+	IsBuyerMaker bool
+	IsBestMatch  bool
+}
+
+// TradesRequest represents Trades request data.
+type TradesRequest struct {
+	Symbol string
+	Limit  int
+}
+
+// Trades returns recent trades.
+func (b *binance) Trades(tr TradesRequest) ([]*MarketTrade, error) {
+	return b.TradesContext(context.Background(), tr)
+}
+
+// TradesContext returns recent trades within provided context.
+func (b *binance) TradesContext(ctx context.Context, tr TradesRequest) ([]*MarketTrade, error) {
+	return b.Service.Trades(ctx, tr)
+}
+
+// HistoricalTradesRequest represents HistoricalTrades request data. The most
+// recent trades are returned if FromID is zero.
+type HistoricalTradesRequest struct {
+	Symbol string
+	Limit  int
+	FromID int64
+}
+
+// HistoricalTrades returns older trades, API key is required.
+func (b *binance) HistoricalTrades(htr HistoricalTradesRequest) ([]*MarketTrade, error) {
+	return b.HistoricalTradesContext(context.Background(), htr)
+}
+
+// HistoricalTradesContext returns older trades within provided context, API key is required.
+func (b *binance) HistoricalTradesContext(ctx context.Context, htr HistoricalTradesRequest) ([]*MarketTrade, error) {
+	return b.Service.HistoricalTrades(ctx, htr)
 }
 
 // KlinesRequest represents Klines request data.
@@ -173,16 +340,16 @@ type KlinesRequest struct {
 // Kline represents single Kline information.
 type Kline struct {
 	OpenTime                 time.Time
-	Open                     float64
-	High                     float64
-	Low                      float64
-	Close                    float64
-	Volume                   float64
+	Open                     Decimal
+	High                     Decimal
+	Low                      Decimal
+	Close                    Decimal
+	Volume                   Decimal
 	CloseTime                time.Time
-	QuoteAssetVolume         float64
+	QuoteAssetVolume         Decimal
 	NumberOfTrades           int
-	TakerBuyBaseAssetVolume  float64
-	TakerBuyQuoteAssetVolume float64
+	TakerBuyBaseAssetVolume  Decimal
+	TakerBuyQuoteAssetVolume Decimal
 }
 
 type KlineEvent struct {
@@ -196,7 +363,12 @@ type KlineEvent struct {
 
 // Klines returns klines/candlestick data.
 func (b *binance) Klines(kr KlinesRequest) ([]*Kline, error) {
-	return b.Service.Klines(kr)
+	return b.KlinesContext(context.Background(), kr)
+}
+
+// KlinesContext returns klines/candlestick data within provided context.
+func (b *binance) KlinesContext(ctx context.Context, kr KlinesRequest) ([]*Kline, error) {
+	return b.Service.Klines(ctx, kr)
 }
 
 // TickerRequest represents Ticker request data.
@@ -206,17 +378,18 @@ type TickerRequest struct {
 
 // Ticker24 represents data for 24hr ticker.
 type Ticker24 struct {
-	PriceChange        float64
-	PriceChangePercent float64
-	WeightedAvgPrice   float64
-	PrevClosePrice     float64
-	LastPrice          float64
-	BidPrice           float64
-	AskPrice           float64
-	OpenPrice          float64
-	HighPrice          float64
-	LowPrice           float64
-	Volume             float64
+	Symbol             string
+	PriceChange        Decimal
+	PriceChangePercent Decimal
+	WeightedAvgPrice   Decimal
+	PrevClosePrice     Decimal
+	LastPrice          Decimal
+	BidPrice           Decimal
+	AskPrice           Decimal
+	OpenPrice          Decimal
+	HighPrice          Decimal
+	LowPrice           Decimal
+	Volume             Decimal
 	OpenTime           time.Time
 	CloseTime          time.Time
 	FirstID            int
@@ -226,32 +399,257 @@ type Ticker24 struct {
 
 // Ticker24 returns 24hr price change statistics.
 func (b *binance) Ticker24(tr TickerRequest) (*Ticker24, error) {
-	return b.Service.Ticker24(tr)
+	return b.Ticker24Context(context.Background(), tr)
+}
+
+// Ticker24Context returns 24hr price change statistics within provided context.
+func (b *binance) Ticker24Context(ctx context.Context, tr TickerRequest) (*Ticker24, error) {
+	return b.Service.Ticker24(ctx, tr)
+}
+
+// TickerAll24 returns 24hr price change statistics of all symbols.
+func (b *binance) TickerAll24() ([]*Ticker24, error) {
+	return b.TickerAll24Context(context.Background())
+}
+
+// TickerAll24Context returns 24hr price change statistics of all symbols within provided context.
+func (b *binance) TickerAll24Context(ctx context.Context) ([]*Ticker24, error) {
+	return b.Service.TickerAll24(ctx)
 }
 
 // PriceTicker represents ticker data for price.
 type PriceTicker struct {
 	Symbol string
-	Price  float64
+	Price  Decimal
 }
 
 // TickerAllPrices returns ticker data for symbols.
 func (b *binance) TickerAllPrices() ([]*PriceTicker, error) {
-	return b.Service.TickerAllPrices()
+	return b.TickerAllPricesContext(context.Background())
+}
+
+// TickerAllPricesContext returns ticker data for symbols within provided context.
+func (b *binance) TickerAllPricesContext(ctx context.Context) ([]*PriceTicker, error) {
+	return b.Service.TickerAllPrices(ctx)
+}
+
+// TickerPrice returns latest price of the symbol.
+func (b *binance) TickerPrice(tr TickerRequest) (*PriceTicker, error) {
+	return b.TickerPriceContext(context.Background(), tr)
+}
+
+// TickerPriceContext returns latest price of the symbol within provided context.
+func (b *binance) TickerPriceContext(ctx context.Context, tr TickerRequest) (*PriceTicker, error) {
+	return b.Service.TickerPrice(ctx, tr)
 }
 
 // BookTicker represents book ticker data.
 type BookTicker struct {
 	Symbol   string
-	BidPrice float64
-	BidQty   float64
-	AskPrice float64
-	AskQty   float64
+	BidPrice Decimal
+	BidQty   Decimal
+	AskPrice Decimal
+	AskQty   Decimal
 }
 
 // TickerAllBooks returns tickers for all books.
 func (b *binance) TickerAllBooks() ([]*BookTicker, error) {
-	return b.Service.TickerAllBooks()
+	return b.TickerAllBooksContext(context.Background())
+}
+
+// TickerAllBooksContext returns tickers for all books within provided context.
+func (b *binance) TickerAllBooksContext(ctx context.Context) ([]*BookTicker, error) {
+	return b.Service.TickerAllBooks(ctx)
+}
+
+// TickerBook returns best bid and ask of the symbol.
+func (b *binance) TickerBook(tr TickerRequest) (*BookTicker, error) {
+	return b.TickerBookContext(context.Background(), tr)
+}
+
+// TickerBookContext returns best bid and ask of the symbol within provided context.
+func (b *binance) TickerBookContext(ctx context.Context, tr TickerRequest) (*BookTicker, error) {
+	return b.Service.TickerBook(ctx, tr)
+}
+
+// AvgPriceRequest represents AvgPrice request data.
+type AvgPriceRequest struct {
+	Symbol string
+}
+
+// AvgPrice represents average price of the symbol over last Mins minutes.
+type AvgPrice struct {
+	Mins  int
+	Price Decimal
+}
+
+// AvgPrice returns current average price of the symbol.
+func (b *binance) AvgPrice(apr AvgPriceRequest) (*AvgPrice, error) {
+	return b.AvgPriceContext(context.Background(), apr)
+}
+
+// AvgPriceContext returns current average price of the symbol within provided context.
+func (b *binance) AvgPriceContext(ctx context.Context, apr AvgPriceRequest) (*AvgPrice, error) {
+	return b.Service.AvgPrice(ctx, apr)
+}
+
+// ExchangeInfo represents trading rules and symbol information.
+type ExchangeInfo struct {
+	Timezone   string
+	ServerTime time.Time
+	RateLimits []*RateLimit
+	Symbols    []*SymbolInfo
+}
+
+// Symbol returns information about the symbol, nil if it isn't listed.
+func (ei *ExchangeInfo) Symbol(symbol string) *SymbolInfo {
+	for _, si := range ei.Symbols {
+		if si.Symbol == symbol {
+			return si
+		}
+	}
+	return nil
+}
+
+// Limits returns rate limits published by the server in form accepted by
+// NewRateLimiter. Limits of unknown type or interval are ignored.
+func (ei *ExchangeInfo) Limits() RateLimits {
+	var limits RateLimits
+	for _, rl := range ei.RateLimits {
+		switch {
+		case rl.Type == LimitRequestWeight && rl.duration() == time.Minute:
+			limits.RequestWeight = rl.Limit
+		case rl.Type == LimitOrders && rl.duration() == 10*time.Second:
+			limits.Orders = rl.Limit
+		case rl.Type == LimitOrders && rl.duration() == 24*time.Hour:
+			limits.DailyOrders = rl.Limit
+		case rl.Type == LimitRawRequests && rl.duration() == 5*time.Minute:
+			limits.RawRequests = rl.Limit
+		}
+	}
+	return limits
+}
+
+// RateLimitInterval represents rate limit interval enum.
+type RateLimitInterval string
+
+var (
+	IntervalSecond = RateLimitInterval("SECOND")
+	IntervalMinute = RateLimitInterval("MINUTE")
+	IntervalDay    = RateLimitInterval("DAY")
+)
+
+// RateLimit represents limit of Type (e.g. LimitRequestWeight) per IntervalNum
+// of Interval.
+type RateLimit struct {
+	Type        string
+	Interval    RateLimitInterval
+	IntervalNum int
+	Limit       int
+}
+
+func (rl *RateLimit) duration() time.Duration {
+	num := time.Duration(rl.IntervalNum)
+	if num == 0 {
+		num = 1
+	}
+	switch rl.Interval {
+	case IntervalSecond:
+		return num * time.Second
+	case IntervalMinute:
+		return num * time.Minute
+	case IntervalDay:
+		return num * 24 * time.Hour
+	}
+	return 0
+}
+
+// SymbolStatus represents symbol status enum.
+type SymbolStatus string
+
+var (
+	SymbolPreTrading   = SymbolStatus("PRE_TRADING")
+	SymbolTrading      = SymbolStatus("TRADING")
+	SymbolPostTrading  = SymbolStatus("POST_TRADING")
+	SymbolEndOfDay     = SymbolStatus("END_OF_DAY")
+	SymbolHalt         = SymbolStatus("HALT")
+	SymbolAuctionMatch = SymbolStatus("AUCTION_MATCH")
+	SymbolBreak        = SymbolStatus("BREAK")
+)
+
+// SymbolInfo represents trading rules of the symbol.
+type SymbolInfo struct {
+	Symbol             string
+	Status             SymbolStatus
+	BaseAsset          string
+	BaseAssetPrecision int
+	QuoteAsset         string
+	QuotePrecision     int
+	OrderTypes         []OrderType
+	IcebergAllowed     bool
+	Filters            SymbolFilters
+}
+
+// SymbolFilters represents filters of the symbol, filter is nil if it isn't
+// defined for the symbol.
+type SymbolFilters struct {
+	Price            *PriceFilter
+	PercentPrice     *PercentPriceFilter
+	LotSize          *LotSizeFilter
+	MarketLotSize    *LotSizeFilter
+	MinNotional      *MinNotionalFilter
+	IcebergParts     *IcebergPartsFilter
+	MaxNumOrders     *MaxNumOrdersFilter
+	MaxNumAlgoOrders *MaxNumOrdersFilter
+}
+
+// PriceFilter represents PRICE_FILTER, zero value of the field disables its check.
+type PriceFilter struct {
+	MinPrice Decimal
+	MaxPrice Decimal
+	TickSize Decimal
+}
+
+// PercentPriceFilter represents PERCENT_PRICE filter. Price has to be within
+// multipliers of average price of last AvgPriceMins minutes.
+type PercentPriceFilter struct {
+	MultiplierUp   Decimal
+	MultiplierDown Decimal
+	AvgPriceMins   int
+}
+
+// LotSizeFilter represents LOT_SIZE and MARKET_LOT_SIZE filters.
+type LotSizeFilter struct {
+	MinQty   Decimal
+	MaxQty   Decimal
+	StepSize Decimal
+}
+
+// MinNotionalFilter represents MIN_NOTIONAL filter.
+type MinNotionalFilter struct {
+	MinNotional   Decimal
+	ApplyToMarket bool
+	AvgPriceMins  int
+}
+
+// IcebergPartsFilter represents ICEBERG_PARTS filter.
+type IcebergPartsFilter struct {
+	Limit int
+}
+
+// MaxNumOrdersFilter represents MAX_NUM_ORDERS and MAX_NUM_ALGO_ORDERS filters.
+type MaxNumOrdersFilter struct {
+	Limit int
+}
+
+// ExchangeInfo returns trading rules and symbol information.
+func (b *binance) ExchangeInfo() (*ExchangeInfo, error) {
+	return b.ExchangeInfoContext(context.Background())
+}
+
+// ExchangeInfoContext returns trading rules and symbol information within provided context.
+func (b *binance) ExchangeInfoContext(ctx context.Context) (*ExchangeInfo, error) {
+	return b.Service.ExchangeInfo(ctx)
 }
 
 // NewOrderRequest represents NewOrder request data.
@@ -260,11 +658,11 @@ type NewOrderRequest struct {
 	Side             OrderSide
 	Type             OrderType
 	TimeInForce      TimeInForce
-	Quantity         float64
-	Price            float64
+	Quantity         Decimal
+	Price            Decimal
 	NewClientOrderID string
-	StopPrice        float64
-	IcebergQty       float64
+	StopPrice        Decimal
+	IcebergQty       Decimal
 	Timestamp        time.Time
 }
 
@@ -278,12 +676,22 @@ type ProcessedOrder struct {
 
 // NewOrder places new order and returns ProcessedOrder.
 func (b *binance) NewOrder(nor NewOrderRequest) (*ProcessedOrder, error) {
-	return b.Service.NewOrder(nor)
+	return b.NewOrderContext(context.Background(), nor)
+}
+
+// NewOrderContext places new order and returns ProcessedOrder within provided context.
+func (b *binance) NewOrderContext(ctx context.Context, nor NewOrderRequest) (*ProcessedOrder, error) {
+	return b.Service.NewOrder(ctx, nor)
 }
 
 // NewOrder places testing order.
 func (b *binance) NewOrderTest(nor NewOrderRequest) error {
-	return b.Service.NewOrderTest(nor)
+	return b.NewOrderTestContext(context.Background(), nor)
+}
+
+// NewOrderTestContext places testing order within provided context.
+func (b *binance) NewOrderTestContext(ctx context.Context, nor NewOrderRequest) error {
+	return b.Service.NewOrderTest(ctx, nor)
 }
 
 // QueryOrderRequest represents QueryOrder request data.
@@ -300,21 +708,26 @@ type ExecutedOrder struct {
 	Symbol        string
 	OrderID       int
 	ClientOrderID string
-	Price         float64
-	OrigQty       float64
-	ExecutedQty   float64
+	Price         Decimal
+	OrigQty       Decimal
+	ExecutedQty   Decimal
 	Status        OrderStatus
 	TimeInForce   TimeInForce
 	Type          OrderType
 	Side          OrderSide
-	StopPrice     float64
-	IcebergQty    float64
+	StopPrice     Decimal
+	IcebergQty    Decimal
 	Time          time.Time
 }
 
 // QueryOrder returns data about existing order.
 func (b *binance) QueryOrder(qor QueryOrderRequest) (*ExecutedOrder, error) {
-	return b.Service.QueryOrder(qor)
+	return b.QueryOrderContext(context.Background(), qor)
+}
+
+// QueryOrderContext returns data about existing order within provided context.
+func (b *binance) QueryOrderContext(ctx context.Context, qor QueryOrderRequest) (*ExecutedOrder, error) {
+	return b.Service.QueryOrder(ctx, qor)
 }
 
 // CancelOrderRequest represents CancelOrder request data.
@@ -337,7 +750,12 @@ type CanceledOrder struct {
 
 // CancelOrder cancels order.
 func (b *binance) CancelOrder(cor CancelOrderRequest) (*CanceledOrder, error) {
-	return b.Service.CancelOrder(cor)
+	return b.CancelOrderContext(context.Background(), cor)
+}
+
+// CancelOrderContext cancels order within provided context.
+func (b *binance) CancelOrderContext(ctx context.Context, cor CancelOrderRequest) (*CanceledOrder, error) {
+	return b.Service.CancelOrder(ctx, cor)
 }
 
 // OpenOrdersRequest represents OpenOrders request data.
@@ -349,7 +767,12 @@ type OpenOrdersRequest struct {
 
 // OpenOrders returns list of open orders.
 func (b *binance) OpenOrders(oor OpenOrdersRequest) ([]*ExecutedOrder, error) {
-	return b.Service.OpenOrders(oor)
+	return b.OpenOrdersContext(context.Background(), oor)
+}
+
+// OpenOrdersContext returns list of open orders within provided context.
+func (b *binance) OpenOrdersContext(ctx context.Context, oor OpenOrdersRequest) ([]*ExecutedOrder, error) {
+	return b.Service.OpenOrders(ctx, oor)
 }
 
 // AllOrdersRequest represents AllOrders request data.
@@ -363,7 +786,12 @@ type AllOrdersRequest struct {
 
 // AllOrders returns list of all previous orders.
 func (b *binance) AllOrders(aor AllOrdersRequest) ([]*ExecutedOrder, error) {
-	return b.Service.AllOrders(aor)
+	return b.AllOrdersContext(context.Background(), aor)
+}
+
+// AllOrdersContext returns list of all previous orders within provided context.
+func (b *binance) AllOrdersContext(ctx context.Context, aor AllOrdersRequest) ([]*ExecutedOrder, error) {
+	return b.Service.AllOrders(ctx, aor)
 }
 
 // AccountRequest represents Account request data.
@@ -392,13 +820,18 @@ type AccountEvent struct {
 // Balance groups balance-related information.
 type Balance struct {
 	Asset  string
-	Free   float64
-	Locked float64
+	Free   Decimal
+	Locked Decimal
 }
 
 // Account returns account data.
 func (b *binance) Account(ar AccountRequest) (*Account, error) {
-	return b.Service.Account(ar)
+	return b.AccountContext(context.Background(), ar)
+}
+
+// AccountContext returns account data within provided context.
+func (b *binance) AccountContext(ctx context.Context, ar AccountRequest) (*Account, error) {
+	return b.Service.Account(ctx, ar)
 }
 
 // MyTradesRequest represents MyTrades request data.
@@ -413,9 +846,9 @@ type MyTradesRequest struct {
 // Trade represents data about trade.
 type Trade struct {
 	ID              int64
-	Price           float64
-	Qty             float64
-	Commission      float64
+	Price           Decimal
+	Qty             Decimal
+	Commission      Decimal
 	CommissionAsset string
 	Time            time.Time
 	IsBuyer         bool
@@ -425,14 +858,19 @@ type Trade struct {
 
 // MyTrades list user's trades.
 func (b *binance) MyTrades(mtr MyTradesRequest) ([]*Trade, error) {
-	return b.Service.MyTrades(mtr)
+	return b.MyTradesContext(context.Background(), mtr)
+}
+
+// MyTradesContext list user's trades within provided context.
+func (b *binance) MyTradesContext(ctx context.Context, mtr MyTradesRequest) ([]*Trade, error) {
+	return b.Service.MyTrades(ctx, mtr)
 }
 
 // WithdrawRequest represents Withdraw request data.
 type WithdrawRequest struct {
 	Asset      string
 	Address    string
-	Amount     float64
+	Amount     Decimal
 	Name       string
 	RecvWindow time.Duration
 	Timestamp  time.Time
@@ -446,7 +884,12 @@ type WithdrawResult struct {
 
 // Withdraw executes withdrawal.
 func (b *binance) Withdraw(wr WithdrawRequest) (*WithdrawResult, error) {
-	return b.Service.Withdraw(wr)
+	return b.WithdrawContext(context.Background(), wr)
+}
+
+// WithdrawContext executes withdrawal within provided context.
+func (b *binance) WithdrawContext(ctx context.Context, wr WithdrawRequest) (*WithdrawResult, error) {
+	return b.Service.Withdraw(ctx, wr)
 }
 
 // HistoryRequest represents history-related calls request data.
@@ -462,19 +905,24 @@ type HistoryRequest struct {
 // Deposit represents Deposit data.
 type Deposit struct {
 	InsertTime time.Time
-	Amount     float64
+	Amount     Decimal
 	Asset      string
 	Status     int
 }
 
 // DepositHistory lists deposit data.
 func (b *binance) DepositHistory(hr HistoryRequest) ([]*Deposit, error) {
-	return b.Service.DepositHistory(hr)
+	return b.DepositHistoryContext(context.Background(), hr)
+}
+
+// DepositHistoryContext lists deposit data within provided context.
+func (b *binance) DepositHistoryContext(ctx context.Context, hr HistoryRequest) ([]*Deposit, error) {
+	return b.Service.DepositHistory(ctx, hr)
 }
 
 // Withdrawal represents withdrawal data.
 type Withdrawal struct {
-	Amount    float64
+	Amount    Decimal
 	Address   string
 	TxID      string
 	Asset     string
@@ -484,7 +932,12 @@ type Withdrawal struct {
 
 // WithdrawHistory lists withdraw data.
 func (b *binance) WithdrawHistory(hr HistoryRequest) ([]*Withdrawal, error) {
-	return b.Service.WithdrawHistory(hr)
+	return b.WithdrawHistoryContext(context.Background(), hr)
+}
+
+// WithdrawHistoryContext lists withdraw data within provided context.
+func (b *binance) WithdrawHistoryContext(ctx context.Context, hr HistoryRequest) ([]*Withdrawal, error) {
+	return b.Service.WithdrawHistory(ctx, hr)
 }
 
 // Stream represents stream information.
@@ -496,17 +949,32 @@ type Stream struct {
 
 // StartUserDataStream starts stream and returns Stream with ListenKey.
 func (b *binance) StartUserDataStream() (*Stream, error) {
-	return b.Service.StartUserDataStream()
+	return b.StartUserDataStreamContext(context.Background())
+}
+
+// StartUserDataStreamContext starts stream and returns Stream with ListenKey within provided context.
+func (b *binance) StartUserDataStreamContext(ctx context.Context) (*Stream, error) {
+	return b.Service.StartUserDataStream(ctx)
 }
 
 // KeepAliveUserDataStream prolongs stream livespan.
 func (b *binance) KeepAliveUserDataStream(s *Stream) error {
-	return b.Service.KeepAliveUserDataStream(s)
+	return b.KeepAliveUserDataStreamContext(context.Background(), s)
+}
+
+// KeepAliveUserDataStreamContext prolongs stream livespan within provided context.
+func (b *binance) KeepAliveUserDataStreamContext(ctx context.Context, s *Stream) error {
+	return b.Service.KeepAliveUserDataStream(ctx, s)
 }
 
 // CloseUserDataStream closes opened stream.
 func (b *binance) CloseUserDataStream(s *Stream) error {
-	return b.Service.CloseUserDataStream(s)
+	return b.CloseUserDataStreamContext(context.Background(), s)
+}
+
+// CloseUserDataStreamContext closes opened stream within provided context.
+func (b *binance) CloseUserDataStreamContext(ctx context.Context, s *Stream) error {
+	return b.Service.CloseUserDataStream(ctx, s)
 }
 
 type WSEvent struct {
@@ -520,7 +988,28 @@ type DepthWebsocketRequest struct {
 }
 
 func (b *binance) DepthWebsocket(dwr DepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error) {
-	return b.Service.DepthWebsocket(dwr)
+	return b.DepthWebsocketContext(context.Background(), dwr)
+}
+
+// DepthWebsocketContext opens stream which is closed when ctx is done.
+func (b *binance) DepthWebsocketContext(ctx context.Context, dwr DepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error) {
+	return b.Service.DepthWebsocket(ctx, dwr)
+}
+
+// CombinedDepthWebsocketRequest represents depth streams of the symbols
+// multiplexed over single connection.
+type CombinedDepthWebsocketRequest struct {
+	Symbols []string
+}
+
+// CombinedDepthWebsocket opens depth streams of multiple symbols over single connection.
+func (b *binance) CombinedDepthWebsocket(cdwr CombinedDepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error) {
+	return b.CombinedDepthWebsocketContext(context.Background(), cdwr)
+}
+
+// CombinedDepthWebsocketContext opens combined stream which is closed when ctx is done.
+func (b *binance) CombinedDepthWebsocketContext(ctx context.Context, cdwr CombinedDepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error) {
+	return b.Service.CombinedDepthWebsocket(ctx, cdwr)
 }
 
 type KlineWebsocketRequest struct {
@@ -529,7 +1018,12 @@ type KlineWebsocketRequest struct {
 }
 
 func (b *binance) KlineWebsocket(kwr KlineWebsocketRequest) (chan *KlineEvent, chan struct{}, error) {
-	return b.Service.KlineWebsocket(kwr)
+	return b.KlineWebsocketContext(context.Background(), kwr)
+}
+
+// KlineWebsocketContext opens stream which is closed when ctx is done.
+func (b *binance) KlineWebsocketContext(ctx context.Context, kwr KlineWebsocketRequest) (chan *KlineEvent, chan struct{}, error) {
+	return b.Service.KlineWebsocket(ctx, kwr)
 }
 
 type TradeWebsocketRequest struct {
@@ -537,7 +1031,12 @@ type TradeWebsocketRequest struct {
 }
 
 func (b *binance) TradeWebsocket(twr TradeWebsocketRequest) (chan *AggTradeEvent, chan struct{}, error) {
-	return b.Service.TradeWebsocket(twr)
+	return b.TradeWebsocketContext(context.Background(), twr)
+}
+
+// TradeWebsocketContext opens stream which is closed when ctx is done.
+func (b *binance) TradeWebsocketContext(ctx context.Context, twr TradeWebsocketRequest) (chan *AggTradeEvent, chan struct{}, error) {
+	return b.Service.TradeWebsocket(ctx, twr)
 }
 
 type UserDataWebsocketRequest struct {
@@ -545,5 +1044,10 @@ type UserDataWebsocketRequest struct {
 }
 
 func (b *binance) UserDataWebsocket(udwr UserDataWebsocketRequest) (chan *AccountEvent, chan struct{}, error) {
-	return b.Service.UserDataWebsocket(udwr)
+	return b.UserDataWebsocketContext(context.Background(), udwr)
+}
+
+// UserDataWebsocketContext opens stream which is closed when ctx is done.
+func (b *binance) UserDataWebsocketContext(ctx context.Context, udwr UserDataWebsocketRequest) (chan *AccountEvent, chan struct{}, error) {
+	return b.Service.UserDataWebsocket(ctx, udwr)
 }
@@ -0,0 +1,125 @@
+package binancetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CassetteVersion is version of cassette format written by Save.
+const CassetteVersion = 1
+
+// Cassette holds REST interactions and websocket streams recorded by Recorder
+// and served back by Replayer.
+//
+// Cassettes don't contain API keys and signatures, listen keys of user data
+// streams are replaced by placeholders.
+type Cassette struct {
+	Version      int               `json:"version"`
+	Interactions []*Interaction    `json:"interactions"`
+	Streams      []*RecordedStream `json:"streams"`
+}
+
+// Interaction is recorded REST request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is REST request without signature.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	// Path is endpoint of the request, e.g. "api/v3/order".
+	Path  string `json:"path"`
+	Query string `json:"query,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+// RecordedResponse is REST response with headers set by the API.
+type RecordedResponse struct {
+	StatusCode int               `json:"statusCode"`
+	Header     map[string]string `json:"header,omitempty"`
+	Body       string            `json:"body"`
+}
+
+// RecordedStream is recorded websocket stream.
+type RecordedStream struct {
+	// Path is stream name, e.g. "bnbbtc@depth", listen key or combined
+	// stream with its query, e.g. "stream?streams=bnbbtc@depth/ethbtc@depth".
+	Path   string   `json:"path"`
+	Frames []string `json:"frames"`
+}
+
+// volatileParams change with every request and are ignored by matching.
+var volatileParams = []string{"timestamp", "signature"}
+
+// LoadCassette reads cassette from the file.
+func LoadCassette(path string) (*Cassette, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read cassette")
+	}
+	c := &Cassette{}
+	if err := json.Unmarshal(raw, c); err != nil {
+		return nil, errors.Wrap(err, "cassette unmarshal failed")
+	}
+	if c.Version != CassetteVersion {
+		return nil, errors.Errorf("unsupported cassette version %d", c.Version)
+	}
+	return c, nil
+}
+
+// Save writes the cassette to the file.
+func (c *Cassette) Save(path string) error {
+	c.Version = CassetteVersion
+	raw, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "cassette marshal failed")
+	}
+	if err := ioutil.WriteFile(path, append(raw, '\n'), 0644); err != nil {
+		return errors.Wrap(err, "unable to write cassette")
+	}
+	return nil
+}
+
+// matches returns true if the request has the same method, path, body and
+// parameters apart from volatile ones and those in ignore.
+func (rr RecordedRequest) matches(method, path, query, body string, ignore []string) bool {
+	if rr.Method != method || rr.Path != path || rr.Body != body {
+		return false
+	}
+	recorded, err := url.ParseQuery(rr.Query)
+	if err != nil {
+		return false
+	}
+	actual, err := url.ParseQuery(query)
+	if err != nil {
+		return false
+	}
+	for _, keys := range [][]string{volatileParams, ignore} {
+		for _, key := range keys {
+			delete(recorded, key)
+			delete(actual, key)
+		}
+	}
+	if len(recorded) != len(actual) {
+		return false
+	}
+	for key, vals := range recorded {
+		if strings.Join(vals, ",") != strings.Join(actual[key], ",") {
+			return false
+		}
+	}
+	return true
+}
+
+func (rr RecordedRequest) String() string {
+	if rr.Query == "" {
+		return fmt.Sprintf("%s %s", rr.Method, rr.Path)
+	}
+	return fmt.Sprintf("%s %s?%s", rr.Method, rr.Path, rr.Query)
+}
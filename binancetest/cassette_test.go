@@ -0,0 +1,266 @@
+package binancetest
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rootpd/binance"
+)
+
+func replay(t *testing.T, path string) (*Replayer, binance.Binance) {
+	c, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("unable to load cassette: %v", err)
+	}
+	r := NewReplayer(c)
+	service := binance.NewAPIService("", "key", &binance.HmacSigner{Key: []byte("secret")}, nil, nil,
+		binance.WithEnvironment(r.Environment()))
+	return r, binance.NewBinance(service)
+}
+
+// TestReplayResponseShapes parses responses documented by Binance API.
+func TestReplayResponseShapes(t *testing.T) {
+	r, b := replay(t, "testdata/api.json")
+	defer r.Close()
+	ts := time.Unix(0, 1499827319559*int64(time.Millisecond))
+
+	if err := b.Ping(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st, err := b.Time(); err != nil || !st.Equal(ts) {
+		t.Errorf("unexpected time %v: %v", st, err)
+	}
+	ob, err := b.OrderBook(binance.OrderBookRequest{Symbol: "LTCBTC", Limit: 5})
+	if err != nil || ob.LastUpdateID != 1027024 || !ob.Bids[0].Quantity.Equal(d("431")) || !ob.Asks[0].Price.Equal(d("4.000002")) {
+		t.Errorf("unexpected order book %+v: %v", ob, err)
+	}
+	aggTrades, err := b.AggTrades(binance.AggTradesRequest{Symbol: "LTCBTC", Limit: 1})
+	if err != nil || len(aggTrades) != 1 || aggTrades[0].ID != 26129 || !aggTrades[0].Price.Equal(d("0.01633102")) || !aggTrades[0].BuyerMaker {
+		t.Errorf("unexpected agg trades %+v: %v", aggTrades, err)
+	}
+	klines, err := b.Klines(binance.KlinesRequest{Symbol: "LTCBTC", Interval: binance.Minute, Limit: 1})
+	if err != nil || len(klines) != 1 || klines[0].NumberOfTrades != 308 || !klines[0].TakerBuyQuoteAssetVolume.Equal(d("28.46694368")) {
+		t.Errorf("unexpected klines %+v: %v", klines, err)
+	}
+	ticker, err := b.Ticker24(binance.TickerRequest{Symbol: "LTCBTC"})
+	if err != nil || !ticker.PriceChangePercent.Equal(d("-95.96")) || ticker.Count != 76 {
+		t.Errorf("unexpected ticker %+v: %v", ticker, err)
+	}
+	if prices, err := b.TickerAllPrices(); err != nil || len(prices) != 2 || prices[1].Symbol != "ETHBTC" {
+		t.Errorf("unexpected prices %+v: %v", prices, err)
+	}
+	if books, err := b.TickerAllBooks(); err != nil || len(books) != 1 || !books[0].AskQty.Equal(d("9")) {
+		t.Errorf("unexpected books %+v: %v", books, err)
+	}
+	marketTrades, err := b.Trades(binance.TradesRequest{Symbol: "LTCBTC", Limit: 1})
+	if err != nil || len(marketTrades) != 1 || marketTrades[0].ID != 28457 || !marketTrades[0].QuoteQty.Equal(d("48.000012")) || !marketTrades[0].IsBuyerMaker {
+		t.Errorf("unexpected trades %+v: %v", marketTrades, err)
+	}
+	historical, err := b.HistoricalTrades(binance.HistoricalTradesRequest{Symbol: "LTCBTC", Limit: 1, FromID: 28457})
+	if err != nil || len(historical) != 1 || !historical[0].QuoteQty.Equal(d("48.000012")) {
+		t.Errorf("unexpected historical trades %+v: %v", historical, err)
+	}
+	if tickers, err := b.TickerAll24(); err != nil || len(tickers) != 1 || tickers[0].Symbol != "LTCBTC" || tickers[0].Count != 76 {
+		t.Errorf("unexpected tickers %+v: %v", tickers, err)
+	}
+	if price, err := b.TickerPrice(binance.TickerRequest{Symbol: "LTCBTC"}); err != nil || !price.Price.Equal(d("4.000002")) {
+		t.Errorf("unexpected price %+v: %v", price, err)
+	}
+	if book, err := b.TickerBook(binance.TickerRequest{Symbol: "LTCBTC"}); err != nil || !book.BidQty.Equal(d("431")) {
+		t.Errorf("unexpected book %+v: %v", book, err)
+	}
+	if avg, err := b.AvgPrice(binance.AvgPriceRequest{Symbol: "LTCBTC"}); err != nil || avg.Mins != 5 || !avg.Price.Equal(d("9.35751834")) {
+		t.Errorf("unexpected average price %+v: %v", avg, err)
+	}
+	ei, err := b.ExchangeInfo()
+	if err != nil || !ei.ServerTime.Equal(ts) || ei.Limits() != (binance.RateLimits{RequestWeight: 1200, Orders: 50, DailyOrders: 160000, RawRequests: 6100}) {
+		t.Fatalf("unexpected exchange info %+v: %v", ei, err)
+	}
+	if si := ei.Symbol("ETHBTC"); si == nil || si.Status != binance.SymbolTrading || len(si.OrderTypes) != 7 || !si.IcebergAllowed ||
+		!si.Filters.Price.TickSize.Equal(d("0.000001")) || !si.Filters.PercentPrice.MultiplierDown.Equal(d("0.2")) ||
+		!si.Filters.LotSize.StepSize.Equal(d("0.001")) || !si.Filters.MarketLotSize.MaxQty.Equal(d("1000")) ||
+		!si.Filters.MinNotional.MinNotional.Equal(d("0.001")) || !si.Filters.MinNotional.ApplyToMarket ||
+		si.Filters.MinNotional.AvgPriceMins != 5 || si.Filters.IcebergParts.Limit != 10 ||
+		si.Filters.MaxNumOrders.Limit != 200 || si.Filters.MaxNumAlgoOrders.Limit != 5 {
+		t.Errorf("unexpected symbol %+v", si)
+	}
+
+	or := binance.NewOrderRequest{
+		Symbol: "LTCBTC", Side: binance.SideBuy, Type: binance.TypeLimit, TimeInForce: binance.GTC,
+		Quantity: d("1"), Price: d("0.1"), Timestamp: time.Now(),
+	}
+	if err := b.NewOrderTest(or); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	or.NewClientOrderID = "6gCrw2kRUAF9CvJDGP16IP"
+	po, err := b.NewOrder(or)
+	if err != nil || po.OrderID != 28 || po.TransactTime.IsZero() {
+		t.Errorf("unexpected order %+v: %v", po, err)
+	}
+	eo, err := b.QueryOrder(binance.QueryOrderRequest{Symbol: "LTCBTC", OrderID: 28, Timestamp: time.Now()})
+	if err != nil || eo.Status != binance.StatusNew || !eo.Price.Equal(d("0.1")) || !eo.Time.Equal(ts) {
+		t.Errorf("unexpected order %+v: %v", eo, err)
+	}
+	if open, err := b.OpenOrders(binance.OpenOrdersRequest{Symbol: "LTCBTC", Timestamp: time.Now()}); err != nil || len(open) != 1 {
+		t.Errorf("unexpected open orders %+v: %v", open, err)
+	}
+	co, err := b.CancelOrder(binance.CancelOrderRequest{Symbol: "LTCBTC", OrderID: 28, Timestamp: time.Now()})
+	if err != nil || co.ClientOrderID != "cancelMyOrder1" {
+		t.Errorf("unexpected cancel %+v: %v", co, err)
+	}
+	all, err := b.AllOrders(binance.AllOrdersRequest{Symbol: "LTCBTC", Limit: 10, Timestamp: time.Now()})
+	if err != nil || len(all) != 1 || all[0].Status != binance.StatusCancelled {
+		t.Errorf("unexpected orders %+v: %v", all, err)
+	}
+	_, err = b.QueryOrder(binance.QueryOrderRequest{Symbol: "LTCBTC", OrderID: 42, Timestamp: time.Now()})
+	if !errors.Is(err, binance.ErrUnknownOrder) {
+		t.Errorf("expected unknown order, got %v", err)
+	}
+
+	account, err := b.Account(binance.AccountRequest{Timestamp: time.Now()})
+	if err != nil || account.MakerCommision != 15 || len(account.Balances) != 2 || !account.Balances[1].Free.Equal(d("4763368.68006011")) {
+		t.Errorf("unexpected account %+v: %v", account, err)
+	}
+	trades, err := b.MyTrades(binance.MyTradesRequest{Symbol: "LTCBTC", FromID: 28456, Timestamp: time.Now()})
+	if err != nil || len(trades) != 1 || trades[0].ID != 28457 || trades[0].CommissionAsset != "BNB" || !trades[0].IsBuyer {
+		t.Errorf("unexpected trades %+v: %v", trades, err)
+	}
+	wr, err := b.Withdraw(binance.WithdrawRequest{Asset: "ETH", Address: "0x1c3b", Amount: d("1.5"), Timestamp: time.Now()})
+	if err != nil || !wr.Success {
+		t.Errorf("unexpected withdraw %+v: %v", wr, err)
+	}
+	deposits, err := b.DepositHistory(binance.HistoryRequest{
+		Asset:     "ETH",
+		StartTime: time.Unix(1508198500, 0),
+		EndTime:   time.Unix(1508198532, 0),
+		Timestamp: time.Now(),
+	})
+	if err != nil || len(deposits) != 1 || !deposits[0].Amount.Equal(d("0.04670582")) || !deposits[0].InsertTime.Equal(time.Unix(1508198532, 0)) {
+		t.Errorf("unexpected deposits %+v: %v", deposits, err)
+	}
+	withdrawals, err := b.WithdrawHistory(binance.HistoryRequest{Asset: "ETH", Timestamp: time.Now()})
+	if err != nil || len(withdrawals) != 1 || withdrawals[0].Status != 4 || !withdrawals[0].ApplyTime.Equal(time.Unix(1508198532, 0)) {
+		t.Errorf("unexpected withdrawals %+v: %v", withdrawals, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	depth, _, err := b.DepthWebsocketContext(ctx, binance.DepthWebsocketRequest{Symbol: "LTCBTC"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if de := <-depth; de.UpdateID != 160 || len(de.Bids) != 1 || !de.Bids[0].Price.Equal(d("0.0024")) {
+		t.Errorf("unexpected depth event: %+v", de)
+	}
+	s, err := b.StartUserDataStream()
+	if err != nil || s.ListenKey != "listen-key-1" {
+		t.Fatalf("unexpected stream %+v: %v", s, err)
+	}
+	accounts, _, err := b.UserDataWebsocketContext(ctx, binance.UserDataWebsocketRequest{ListenKey: s.ListenKey})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ae := <-accounts; len(ae.Balances) != 2 || !ae.Balances[1].Locked.Equal(d("2.19464093")) || !ae.CanTrade {
+		t.Errorf("unexpected account event: %+v", ae)
+	}
+	if err := b.KeepAliveUserDataStream(s); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := b.CloseUserDataStream(s); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := b.Ping(); err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+		t.Errorf("expected missing recording, got %v", err)
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	srv, _ := newServer()
+	defer srv.Close()
+	srv.Fake.Exchange.AddLiquidity("BNBETH", binance.SideSell, d("0.01"), d("100"))
+
+	apiKey := "vmPUZE6mv9SD5VNHk4HlWFsOr6aKE2zvsw0MuIgwCIPy6utIco14y7Ju91duEh8A"
+	srv.AddAPIKey(apiKey, &binance.HmacSigner{Key: []byte("secret")})
+	rec := NewRecorder(srv.Environment())
+	defer rec.Close()
+	b := binance.NewBinance(binance.NewAPIService("", apiKey, &binance.HmacSigner{Key: []byte("secret")}, nil, nil,
+		binance.WithEnvironment(rec.Environment())))
+	type result struct {
+		listenKey string
+		executed  binance.Decimal
+		updateID  int
+	}
+	run := func(b binance.Binance) (result, error) {
+		ctx, cancel := context.WithCancel(context.Background())
+		depth, done, err := b.DepthWebsocketContext(ctx, binance.DepthWebsocketRequest{Symbol: "BNBETH"})
+		if err != nil {
+			cancel()
+			return result{}, err
+		}
+		defer func() {
+			cancel()
+			<-done
+		}()
+		po, err := b.NewOrder(binance.NewOrderRequest{
+			Symbol: "BNBETH", Side: binance.SideBuy, Type: binance.TypeLimit, TimeInForce: binance.GTC,
+			Quantity: d("10"), Price: d("0.01"), NewClientOrderID: "my-order",
+		})
+		if err != nil {
+			return result{}, err
+		}
+		s, err := b.StartUserDataStream()
+		if err != nil {
+			return result{}, err
+		}
+		eo, err := b.QueryOrder(binance.QueryOrderRequest{Symbol: "BNBETH", OrderID: po.OrderID})
+		if err != nil {
+			return result{}, err
+		}
+		de := <-depth
+		return result{s.ListenKey, eo.ExecutedQty, de.UpdateID}, b.CloseUserDataStream(s)
+	}
+
+	recorded, err := run(b)
+	if err != nil || !recorded.executed.Equal(d("10")) || recorded.updateID != 2 {
+		t.Fatalf("unexpected result %+v: %v", recorded, err)
+	}
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatalf("unable to save cassette: %v", err)
+	}
+	raw, _ := ioutil.ReadFile(path)
+	for _, secret := range []string{"signature", apiKey, recorded.listenKey} {
+		if strings.Contains(string(raw), secret) {
+			t.Errorf("cassette contains %q: %s", secret, raw)
+		}
+	}
+
+	srv.Close()
+	r, b := replay(t, path)
+	defer r.Close()
+	replayed, err := run(b)
+	if err != nil || replayed.listenKey != "listen-key-1" || !replayed.executed.Equal(recorded.executed) ||
+		replayed.updateID != recorded.updateID {
+		t.Errorf("unexpected replay %+v: %v", replayed, err)
+	}
+}
+
+func TestLoadCassetteVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := ioutil.WriteFile(path, []byte(`{"version": 2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCassette(path); err == nil {
+		t.Error("expected error for unsupported version")
+	}
+	if _, err := LoadCassette(filepath.Join(os.TempDir(), "missing-cassette.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,95 @@
+// Package binancetest provides fake implementations of binance.Service for tests.
+package binancetest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rootpd/binance"
+	"github.com/rootpd/binance/sim"
+)
+
+// FakeService implements binance.Service on top of in-memory sim.Exchange.
+//
+// Calls are served by sim.Service: orders are matched by deterministic
+// matching engine, balances move on fills and websockets stream events of the
+// exchange. Every call is recorded and failures can be programmed per method
+// with FailNext and FailWhen.
+type FakeService struct {
+	binance.Service
+	Exchange *sim.Exchange
+
+	mu       sync.Mutex
+	failures map[string][]error
+	failWhen func(call binance.Call) error
+	calls    []binance.Call
+}
+
+var _ binance.Service = (*FakeService)(nil)
+
+// NewFakeService creates service backed by the exchange, new exchange with
+// real clock is created if nil.
+func NewFakeService(exchange *sim.Exchange) *FakeService {
+	if exchange == nil {
+		exchange = sim.NewExchange(nil)
+	}
+	f := &FakeService{
+		Exchange: exchange,
+		failures: make(map[string][]error),
+	}
+	f.Service = binance.InterceptorMiddleware(f.intercept)(sim.NewService(exchange))
+	return f
+}
+
+// FailNext makes next calls of the method, e.g. "NewOrder", return errs one
+// by one before the method is executed.
+func (f *FakeService) FailNext(method string, errs ...error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[method] = append(f.failures[method], errs...)
+}
+
+// FailWhen sets function deciding whether the call fails. Non-nil error is
+// returned instead of executing the call. Errors queued by FailNext take
+// precedence.
+func (f *FakeService) FailWhen(fn func(call binance.Call) error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failWhen = fn
+}
+
+// Calls returns all calls received by the service.
+func (f *FakeService) Calls() []binance.Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]binance.Call(nil), f.calls...)
+}
+
+// intercept records the call and returns programmed failure instead of
+// executing the call.
+func (f *FakeService) intercept(ctx context.Context, call binance.Call, next binance.Invoker) (interface{}, error) {
+	if err := f.fail(call); err != nil {
+		return nil, err
+	}
+	return next(ctx, call.Request)
+}
+
+// fail records the call and returns its programmed failure.
+func (f *FakeService) fail(call binance.Call) error {
+	f.mu.Lock()
+	f.calls = append(f.calls, call)
+	var err error
+	if errs := f.failures[call.Method]; len(errs) > 0 {
+		err, f.failures[call.Method] = errs[0], errs[1:]
+	}
+	failWhen := f.failWhen
+	f.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if failWhen != nil {
+		return failWhen(call)
+	}
+	return nil
+}
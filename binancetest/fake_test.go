@@ -0,0 +1,129 @@
+package binancetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rootpd/binance"
+	"github.com/rootpd/binance/sim"
+)
+
+var d = binance.MustParseDecimal
+
+func newFake() *FakeService {
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := sim.NewExchange(func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	})
+	e.AddSymbol(sim.Symbol{Name: "BNBETH", BaseAsset: "BNB", QuoteAsset: "ETH"})
+	e.SetBalance("ETH", d("10"))
+	return NewFakeService(e)
+}
+
+func TestFakeServiceOrders(t *testing.T) {
+	f := newFake()
+	b := binance.NewBinance(f)
+	f.Exchange.AddLiquidity("BNBETH", binance.SideSell, d("0.01"), d("100"))
+
+	po, err := b.NewOrder(binance.NewOrderRequest{
+		Symbol: "BNBETH", Side: binance.SideBuy, Type: binance.TypeLimit,
+		TimeInForce: binance.GTC, Quantity: d("150"), Price: d("0.01"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	eo, err := b.QueryOrder(binance.QueryOrderRequest{Symbol: "BNBETH", OrderID: po.OrderID})
+	if err != nil || eo.Status != binance.StatusPartiallyFilled {
+		t.Fatalf("unexpected order %+v: %v", eo, err)
+	}
+	trades, _ := b.MyTrades(binance.MyTradesRequest{Symbol: "BNBETH"})
+	if len(trades) != 1 || !trades[0].Qty.Equal(d("100")) {
+		t.Errorf("unexpected trades: %+v", trades)
+	}
+
+	co, err := b.CancelOrder(binance.CancelOrderRequest{Symbol: "BNBETH", OrigClientOrderID: po.ClientOrderID})
+	if err != nil || co.OrderID != po.OrderID {
+		t.Fatalf("unexpected cancel %+v: %v", co, err)
+	}
+	account, _ := b.Account(binance.AccountRequest{})
+	for _, balance := range account.Balances {
+		if balance.Asset == "ETH" && !balance.Free.Equal(d("9")) || balance.Asset == "BNB" && !balance.Free.Equal(d("100")) {
+			t.Errorf("unexpected balance: %+v", balance)
+		}
+	}
+	if _, err := b.QueryOrder(binance.QueryOrderRequest{Symbol: "BNBETH", OrderID: 42}); !errors.Is(err, binance.ErrUnknownOrder) {
+		t.Errorf("expected unknown order, got %v", err)
+	}
+}
+
+func TestFakeServiceFailures(t *testing.T) {
+	f := newFake()
+	b := binance.NewBinance(f)
+	unavailable := &binance.Error{Code: -1001, Message: "Internal error; unable to process your request.", StatusCode: 503}
+	f.FailNext("Account", unavailable)
+	f.FailWhen(func(call binance.Call) error {
+		if or, ok := call.Request.(binance.NewOrderRequest); ok && or.Symbol == "BNBETH" {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+
+	if _, err := b.Account(binance.AccountRequest{}); err != unavailable {
+		t.Errorf("expected programmed failure, got %v", err)
+	}
+	if _, err := b.Account(binance.AccountRequest{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := b.NewOrder(binance.NewOrderRequest{Symbol: "BNBETH"}); err == nil || err.Error() != "rejected" {
+		t.Errorf("expected rejection, got %v", err)
+	}
+	if calls := f.Calls(); len(calls) != 3 || calls[2].Method != "NewOrder" {
+		t.Errorf("unexpected calls: %+v", calls)
+	}
+}
+
+func TestFakeServiceWebsockets(t *testing.T) {
+	f := newFake()
+	b := binance.NewBinance(f)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	depth, _, err := b.DepthWebsocketContext(ctx, binance.DepthWebsocketRequest{Symbol: "BNBETH"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	trades, _, _ := b.TradeWebsocketContext(ctx, binance.TradeWebsocketRequest{Symbol: "BNBETH"})
+	s, _ := b.StartUserDataStream()
+	accounts, done, err := b.UserDataWebsocketContext(ctx, binance.UserDataWebsocketRequest{ListenKey: s.ListenKey})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f.Exchange.AddLiquidity("BNBETH", binance.SideSell, d("0.01"), d("10"))
+	b.NewOrder(binance.NewOrderRequest{Symbol: "BNBETH", Side: binance.SideBuy, Type: binance.TypeMarket, Quantity: d("4")})
+
+	if de := <-depth; len(de.Asks) != 1 || !de.Asks[0].Quantity.Equal(d("10")) {
+		t.Errorf("unexpected depth event: %+v", de)
+	}
+	if de := <-depth; !de.Asks[0].Quantity.Equal(d("6")) || de.UpdateID != 2 {
+		t.Errorf("unexpected depth event: %+v", de)
+	}
+	if te := <-trades; !te.Quantity.Equal(d("4")) {
+		t.Errorf("unexpected trade event: %+v", te)
+	}
+	if ae := <-accounts; len(ae.Balances) != 2 {
+		t.Errorf("unexpected account event: %+v", ae)
+	}
+
+	cancel()
+	<-done
+	if err := b.CloseUserDataStream(s); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := b.KeepAliveUserDataStream(s); err == nil {
+		t.Error("expected error for closed stream")
+	}
+}
@@ -0,0 +1,227 @@
+package binancetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/rootpd/binance"
+)
+
+// Recorder proxies REST calls and websocket streams to upstream environment
+// and records them into Cassette.
+//
+// Point the service to Recorder by binance.WithEnvironment(rec.Environment())
+// and save the cassette once the calls are done:
+//
+//	rec := binancetest.NewRecorder(binance.Testnet)
+//	defer rec.Close()
+//	service := binance.NewAPIService("", apiKey, signer, logger, ctx,
+//		binance.WithEnvironment(rec.Environment()))
+//	...
+//	err := rec.Save("testdata/orders.json")
+type Recorder struct {
+	*httptest.Server
+	Cassette *Cassette
+
+	upstream   binance.Environment
+	client     *http.Client
+	mu         sync.Mutex
+	listenKeys map[string]string
+}
+
+// recordedHeaders are response headers stored in cassette.
+var recordedHeaders = []string{"Content-Type", "Retry-After"}
+
+// NewRecorder starts recorder proxying to the upstream environment. Recorder
+// has to be closed by Close.
+func NewRecorder(upstream binance.Environment) *Recorder {
+	r := &Recorder{
+		Cassette:   &Cassette{Version: CassetteVersion},
+		upstream:   upstream,
+		client:     binance.NewHTTPClient(binance.DefaultHTTPClientConfig()),
+		listenKeys: make(map[string]string),
+	}
+	r.Server = httptest.NewServer(r)
+	return r
+}
+
+// Environment returns environment pointing REST calls and streams to the recorder.
+func (r *Recorder) Environment() binance.Environment {
+	return binance.Environment{
+		Name:      "recorder",
+		RESTURL:   r.URL,
+		StreamURL: "ws" + strings.TrimPrefix(r.URL, "http"),
+	}
+}
+
+// Save writes recorded cassette to the file.
+func (r *Recorder) Save(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.Cassette.Save(path)
+}
+
+// ServeHTTP forwards the request upstream and records the exchange.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path, stream := streamPath(req)
+	if stream {
+		r.recordStream(w, req, path)
+		return
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		writeError(w, apiError(-1000, err.Error()))
+		return
+	}
+	target := fmt.Sprintf("%s/%s", strings.TrimSuffix(r.upstream.RESTURL, "/"), path)
+	if req.URL.RawQuery != "" {
+		target += "?" + req.URL.RawQuery
+	}
+	upReq, err := http.NewRequest(req.Method, target, bytes.NewReader(body))
+	if err != nil {
+		writeError(w, apiError(-1000, err.Error()))
+		return
+	}
+	upReq = upReq.WithContext(req.Context())
+	for _, h := range []string{"X-MBX-APIKEY", "Content-Type"} {
+		if v := req.Header.Get(h); v != "" {
+			upReq.Header.Set(h, v)
+		}
+	}
+	res, err := r.client.Do(upReq)
+	if err != nil {
+		writeError(w, &binance.Error{Code: -1000, Message: err.Error(), StatusCode: http.StatusBadGateway})
+		return
+	}
+	defer res.Body.Close()
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		writeError(w, &binance.Error{Code: -1000, Message: err.Error(), StatusCode: http.StatusBadGateway})
+		return
+	}
+
+	r.record(req, path, body, res, resBody)
+
+	for key, vals := range res.Header {
+		for _, v := range vals {
+			w.Header().Add(key, v)
+		}
+	}
+	w.WriteHeader(res.StatusCode)
+	w.Write(resBody)
+}
+
+func (r *Recorder) record(req *http.Request, path string, body []byte, res *http.Response, resBody []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// listen key is replaced by placeholder the first time it's seen
+	if req.Method == "POST" && path == "api/v1/userDataStream" && res.StatusCode == http.StatusOK {
+		s := struct {
+			ListenKey string `json:"listenKey"`
+		}{}
+		if json.Unmarshal(resBody, &s) == nil && s.ListenKey != "" {
+			if _, ok := r.listenKeys[s.ListenKey]; !ok {
+				r.listenKeys[s.ListenKey] = fmt.Sprintf("listen-key-%d", len(r.listenKeys)+1)
+			}
+		}
+	}
+
+	var query []string
+	for _, part := range strings.Split(req.URL.RawQuery, "&") {
+		if part != "" && !strings.HasPrefix(part, "signature=") {
+			query = append(query, part)
+		}
+	}
+	header := make(map[string]string)
+	for key := range res.Header {
+		if strings.HasPrefix(key, "X-Mbx-") {
+			header[key] = res.Header.Get(key)
+		}
+	}
+	for _, key := range recordedHeaders {
+		if v := res.Header.Get(key); v != "" {
+			header[key] = v
+		}
+	}
+
+	apiKey := req.Header.Get("X-MBX-APIKEY")
+	r.Cassette.Interactions = append(r.Cassette.Interactions, &Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Path:   path,
+			Query:  r.scrub(strings.Join(query, "&"), apiKey),
+			Body:   r.scrub(string(body), apiKey),
+		},
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     header,
+			Body:       r.scrub(string(resBody), apiKey),
+		},
+	})
+}
+
+// scrub replaces API key and listen keys in s. Caller holds the lock.
+func (r *Recorder) scrub(s string, apiKey string) string {
+	if apiKey != "" {
+		s = strings.Replace(s, apiKey, "scrubbed", -1)
+	}
+	for key, placeholder := range r.listenKeys {
+		s = strings.Replace(s, key, placeholder, -1)
+	}
+	return s
+}
+
+// recordStream connects to upstream stream and forwards and records its frames.
+func (r *Recorder) recordStream(w http.ResponseWriter, req *http.Request, path string) {
+	up, _, err := websocket.DefaultDialer.Dial(streamURL(r.upstream.StreamURL, path), nil)
+	if err != nil {
+		writeError(w, &binance.Error{Code: -1000, Message: err.Error(), StatusCode: http.StatusBadGateway})
+		return
+	}
+	defer up.Close()
+	c, err := (&websocket.Upgrader{}).Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	defer c.Close()
+
+	r.mu.Lock()
+	stream := &RecordedStream{Path: r.scrub(path, "")}
+	r.Cassette.Streams = append(r.Cassette.Streams, stream)
+	r.mu.Unlock()
+
+	// messages of the client are forwarded but not recorded
+	go func() {
+		defer up.Close()
+		for {
+			typ, message, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := up.WriteMessage(typ, message); err != nil {
+				return
+			}
+		}
+	}()
+	for {
+		typ, message, err := up.ReadMessage()
+		if err != nil {
+			return
+		}
+		r.mu.Lock()
+		stream.Frames = append(stream.Frames, r.scrub(string(message), ""))
+		r.mu.Unlock()
+		if err := c.WriteMessage(typ, message); err != nil {
+			return
+		}
+	}
+}
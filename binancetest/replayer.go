@@ -0,0 +1,132 @@
+package binancetest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/rootpd/binance"
+)
+
+// Replayer serves REST responses and websocket streams recorded in Cassette.
+//
+// Request is answered by the first not yet replayed interaction with the same
+// method, endpoint, body and parameters. Parameters timestamp and signature are
+// ignored. Stream is served by the first not yet replayed recording of the same
+// path and stays open once all frames are sent. Requests without recording are
+// answered with 500 error.
+type Replayer struct {
+	*httptest.Server
+	Cassette *Cassette
+
+	// IgnoreParams are additional parameters ignored by matching, e.g.
+	// "newClientOrderId" if the client order IDs are generated randomly.
+	IgnoreParams []string
+
+	mu              sync.Mutex
+	replayed        map[*Interaction]bool
+	replayedStreams map[*RecordedStream]bool
+}
+
+// NewReplayer starts server replaying the cassette. Replayer has to be closed
+// by Close.
+func NewReplayer(cassette *Cassette) *Replayer {
+	r := &Replayer{
+		Cassette:        cassette,
+		replayed:        make(map[*Interaction]bool),
+		replayedStreams: make(map[*RecordedStream]bool),
+	}
+	r.Server = httptest.NewServer(r)
+	return r
+}
+
+// Environment returns environment pointing REST calls and streams to the replayer.
+func (r *Replayer) Environment() binance.Environment {
+	return binance.Environment{
+		Name:      "replayer",
+		RESTURL:   r.URL,
+		StreamURL: "ws" + strings.TrimPrefix(r.URL, "http"),
+	}
+}
+
+// ServeHTTP serves recorded response matching the request.
+func (r *Replayer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path, stream := streamPath(req)
+	if stream {
+		r.replayStream(w, req, path)
+		return
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		writeError(w, apiError(-1000, err.Error()))
+		return
+	}
+	actual := RecordedRequest{Method: req.Method, Path: path, Query: req.URL.RawQuery, Body: string(body)}
+
+	r.mu.Lock()
+	var match *Interaction
+	for _, i := range r.Cassette.Interactions {
+		if !r.replayed[i] && i.Request.matches(actual.Method, actual.Path, actual.Query, actual.Body, r.IgnoreParams) {
+			match = i
+			r.replayed[i] = true
+			break
+		}
+	}
+	r.mu.Unlock()
+	if match == nil {
+		writeError(w, &binance.Error{
+			Code:       -1000,
+			Message:    "binancetest: no recorded interaction for " + actual.String(),
+			StatusCode: http.StatusInternalServerError,
+		})
+		return
+	}
+
+	for key, v := range match.Response.Header {
+		w.Header().Set(key, v)
+	}
+	w.WriteHeader(match.Response.StatusCode)
+	w.Write([]byte(match.Response.Body))
+}
+
+func (r *Replayer) replayStream(w http.ResponseWriter, req *http.Request, path string) {
+	r.mu.Lock()
+	var match *RecordedStream
+	for _, s := range r.Cassette.Streams {
+		if !r.replayedStreams[s] && s.Path == path {
+			match = s
+			r.replayedStreams[s] = true
+			break
+		}
+	}
+	r.mu.Unlock()
+	if match == nil {
+		writeError(w, &binance.Error{
+			Code:       -1000,
+			Message:    "binancetest: no recorded stream " + path,
+			StatusCode: http.StatusNotFound,
+		})
+		return
+	}
+
+	c, err := (&websocket.Upgrader{}).Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	defer c.Close()
+	for _, frame := range match.Frames {
+		if err := c.WriteMessage(websocket.TextMessage, []byte(frame)); err != nil {
+			return
+		}
+	}
+	// client closes the stream by closing the connection
+	for {
+		if _, _, err := c.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package binancetest
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rootpd/binance"
+	"github.com/rootpd/binance/sim"
+)
+
+// Server emulates Binance REST API and websocket streams over HTTP.
+//
+// Requests are served by FakeService, so the server matches orders by the same
+// engine and failures programmed on Fake are returned as error responses.
+// Signed requests are verified with signer registered by AddAPIKey, responses
+// carry X-MBX-USED-WEIGHT-1M and X-MBX-ORDER-COUNT-* headers and requests over
+// Limits are rejected with 429.
+type Server struct {
+	*httptest.Server
+	Fake *FakeService
+
+	// Limits enforced by the server. Zero value of the limit disables it.
+	Limits binance.RateLimits
+	// CheckTimestamps makes the server reject signed requests whose timestamp
+	// isn't within recvWindow of the exchange clock.
+	CheckTimestamps bool
+
+	mu      sync.Mutex
+	signers map[string]binance.Signer
+	weight  usage
+	orders  usage
+	daily   usage
+}
+
+// usage counts units used in fixed window.
+type usage struct {
+	start time.Time
+	used  int
+}
+
+func (u *usage) add(now time.Time, interval time.Duration, n int) int {
+	if start := now.Truncate(interval); !start.Equal(u.start) {
+		u.start, u.used = start, 0
+	}
+	u.used += n
+	return u.used
+}
+
+// NewServer starts server backed by the exchange, new exchange with real clock
+// is created if nil. Server has to be closed by Close.
+func NewServer(exchange *sim.Exchange) *Server {
+	s := &Server{
+		Fake:    NewFakeService(exchange),
+		Limits:  binance.DefaultRateLimits(),
+		signers: make(map[string]binance.Signer),
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// AddAPIKey registers API key. Signatures of its requests are verified by
+// signing the payload by the signer, e.g. HmacSigner with the secret.
+func (s *Server) AddAPIKey(apiKey string, signer binance.Signer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signers[apiKey] = signer
+}
+
+// Environment returns environment pointing REST calls and streams to the server.
+func (s *Server) Environment() binance.Environment {
+	return binance.Environment{
+		Name:      "binancetest",
+		RESTURL:   s.URL,
+		StreamURL: "ws" + strings.TrimPrefix(s.URL, "http"),
+	}
+}
+
+type endpoint struct {
+	// security is "" for public endpoints, "apiKey" for endpoints requiring
+	// API key and "signed" for signed endpoints
+	security string
+	handle   func(s *Server, ctx context.Context, p params) (interface{}, error)
+}
+
+var endpoints = map[string]endpoint{
+	"GET api/v1/ping":                      {"", (*Server).ping},
+	"GET api/v1/time":                      {"", (*Server).time},
+	"GET api/v1/depth":                     {"", (*Server).depth},
+	"GET api/v1/aggTrades":                 {"", (*Server).aggTrades},
+	"GET api/v1/trades":                    {"", (*Server).trades},
+	"GET api/v1/historicalTrades":          {"apiKey", (*Server).historicalTrades},
+	"GET api/v1/klines":                    {"", (*Server).klines},
+	"GET api/v1/ticker/24hr":               {"", (*Server).ticker24},
+	"GET api/v1/ticker/allPrices":          {"", (*Server).allPrices},
+	"GET api/v1/ticker/allBookTickers":     {"", (*Server).allBookTickers},
+	"GET api/v3/ticker/price":              {"", (*Server).tickerPrice},
+	"GET api/v3/ticker/bookTicker":         {"", (*Server).tickerBook},
+	"GET api/v3/avgPrice":                  {"", (*Server).avgPrice},
+	"GET api/v1/exchangeInfo":              {"", (*Server).exchangeInfo},
+	"POST api/v3/order":                    {"signed", (*Server).newOrder},
+	"POST api/v3/order/test":               {"signed", (*Server).newOrderTest},
+	"GET api/v3/order":                     {"signed", (*Server).queryOrder},
+	"DELETE api/v3/order":                  {"signed", (*Server).cancelOrder},
+	"GET api/v3/openOrders":                {"signed", (*Server).openOrders},
+	"GET api/v3/allOrders":                 {"signed", (*Server).allOrders},
+	"GET api/v3/account":                   {"signed", (*Server).account},
+	"GET api/v3/myTrades":                  {"signed", (*Server).myTrades},
+	"POST wapi/v1/withdraw.html":           {"signed", (*Server).withdraw},
+	"POST wapi/v1/getDepositHistory.html":  {"signed", (*Server).depositHistory},
+	"POST wapi/v1/getWithdrawHistory.html": {"signed", (*Server).withdrawHistory},
+	"POST api/v1/userDataStream":           {"apiKey", (*Server).startUserDataStream},
+	"PUT api/v1/userDataStream":            {"apiKey", (*Server).keepAliveUserDataStream},
+	"DELETE api/v1/userDataStream":         {"apiKey", (*Server).closeUserDataStream},
+}
+
+// ServeHTTP serves REST endpoints and websocket streams.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path, stream := streamPath(r)
+	if stream {
+		s.serveStream(w, r, path)
+		return
+	}
+
+	e, ok := endpoints[r.Method+" "+path]
+	if !ok {
+		writeError(w, &binance.Error{Code: -1000, Message: "Unknown endpoint.", StatusCode: http.StatusNotFound})
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, apiError(-1000, err.Error()))
+		return
+	}
+	p, err := parseParams(r.URL.RawQuery, body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.limit(w, r.Method, path, p); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.authorize(r, e.security, p, body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	res, err := e.handle(s, r.Context(), p)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	json.NewEncoder(w).Encode(res)
+}
+
+// parseParams returns parameters sent in query string and body.
+func parseParams(query string, body []byte) (params, error) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, apiError(-1100, "Illegal characters found in a parameter.")
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, apiError(-1100, "Illegal characters found in a parameter.")
+	}
+	for key, vals := range form {
+		values[key] = append(values[key], vals...)
+	}
+	return params(values), nil
+}
+
+// authorize checks API key, signature and timestamp required by the security
+// of the endpoint.
+func (s *Server) authorize(r *http.Request, security string, p params, body []byte) error {
+	if security == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	signer, ok := s.signers[r.Header.Get("X-MBX-APIKEY")]
+	s.mu.Unlock()
+	if !ok {
+		return &binance.Error{Code: -2015, Message: "Invalid API-key, IP, or permissions for action.",
+			StatusCode: http.StatusUnauthorized}
+	}
+	if security == "apiKey" {
+		return nil
+	}
+
+	// signed payload is query string without signature followed by the body
+	var signed []string
+	for _, part := range strings.Split(r.URL.RawQuery, "&") {
+		if !strings.HasPrefix(part, "signature=") {
+			signed = append(signed, part)
+		}
+	}
+	payload := strings.Join(signed, "&") + string(body)
+	signature := p.get("signature")
+	if signature == "" || subtle.ConstantTimeCompare([]byte(signer.Sign([]byte(payload))), []byte(signature)) != 1 {
+		return apiError(-1022, "Signature for this request is not valid.")
+	}
+
+	timestamp, err := strconv.ParseInt(p.get("timestamp"), 10, 64)
+	if err != nil {
+		return mandatory("timestamp")
+	}
+	if s.CheckTimestamps {
+		window := int64(5000)
+		if rw := p.get("recvWindow"); rw != "" {
+			window, _ = strconv.ParseInt(rw, 10, 64)
+		}
+		now := millis(s.Fake.Exchange.Now())
+		if timestamp > now+1000 || now-timestamp > window {
+			return apiError(-1021, "Timestamp for this request is outside of the recvWindow.")
+		}
+	}
+	return nil
+}
+
+// limit accounts the request and sets usage headers. It returns error if the
+// request exceeds Limits.
+func (s *Server) limit(w http.ResponseWriter, method, path string, p params) error {
+	flat := make(map[string]string)
+	for key := range p {
+		flat[key] = p.get(key)
+	}
+	now := time.Now()
+	order := method == "POST" && path == "api/v3/order"
+
+	count := 0
+	if order {
+		count = 1
+	}
+
+	s.mu.Lock()
+	weight := s.weight.add(now, time.Minute, binance.RequestWeight(method, path, flat))
+	orders := s.orders.add(now, 10*time.Second, count)
+	daily := s.daily.add(now, 24*time.Hour, count)
+	s.mu.Unlock()
+
+	w.Header().Set("X-MBX-USED-WEIGHT-1M", strconv.Itoa(weight))
+	w.Header().Set("Date", now.UTC().Format(http.TimeFormat))
+	if order {
+		w.Header().Set("X-MBX-ORDER-COUNT-10S", strconv.Itoa(orders))
+		w.Header().Set("X-MBX-ORDER-COUNT-1D", strconv.Itoa(daily))
+	}
+
+	if s.Limits.RequestWeight > 0 && weight > s.Limits.RequestWeight {
+		w.Header().Set("Retry-After", strconv.Itoa(60-now.Second()))
+		return &binance.Error{
+			Code:       -1003,
+			Message:    fmt.Sprintf("Too many requests; current limit is %d request weight per 1 MINUTE.", s.Limits.RequestWeight),
+			StatusCode: http.StatusTooManyRequests,
+		}
+	}
+	if order && s.Limits.Orders > 0 && orders > s.Limits.Orders {
+		return &binance.Error{
+			Code:       -1015,
+			Message:    fmt.Sprintf("Too many new orders; current limit is %d orders per TEN_SECONDS.", s.Limits.Orders),
+			StatusCode: http.StatusTooManyRequests,
+		}
+	}
+	if order && s.Limits.DailyOrders > 0 && daily > s.Limits.DailyOrders {
+		return &binance.Error{
+			Code:       -1015,
+			Message:    fmt.Sprintf("Too many new orders; current limit is %d orders per DAY.", s.Limits.DailyOrders),
+			StatusCode: http.StatusTooManyRequests,
+		}
+	}
+	return nil
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	bErr, ok := err.(*binance.Error)
+	if !ok {
+		bErr = &binance.Error{Code: -1000, Message: err.Error(), StatusCode: http.StatusInternalServerError}
+	}
+	status := bErr.StatusCode
+	if status == 0 {
+		status = http.StatusBadRequest
+	}
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": bErr.Code, "msg": bErr.Message})
+}
+
+func apiError(code int, msg string) error {
+	return &binance.Error{Code: code, Message: msg, StatusCode: http.StatusBadRequest}
+}
+
+func mandatory(param string) error {
+	return apiError(-1102, fmt.Sprintf("Mandatory parameter '%s' was not sent, was empty/null, or malformed.", param))
+}
+
+// params are parameters of the request.
+type params url.Values
+
+func (p params) get(key string) string {
+	return url.Values(p).Get(key)
+}
+
+func (p params) symbol() (string, error) {
+	symbol := p.get("symbol")
+	if symbol == "" {
+		return "", mandatory("symbol")
+	}
+	return symbol, nil
+}
+
+func (p params) int64(key string) (int64, error) {
+	raw := p.get(key)
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, apiError(-1100, fmt.Sprintf("Illegal characters found in parameter '%s'; legal range is '^[0-9]{1,20}$'.", key))
+	}
+	return n, nil
+}
+
+func (p params) decimal(key string) (binance.Decimal, error) {
+	raw := p.get(key)
+	if raw == "" {
+		return binance.Decimal{}, nil
+	}
+	d, err := binance.ParseDecimal(raw)
+	if err != nil {
+		return binance.Decimal{}, apiError(-1100,
+			fmt.Sprintf("Illegal characters found in parameter '%s'; legal range is '^([0-9]{1,20})(\\.[0-9]{1,20})?$'.", key))
+	}
+	return d, nil
+}
+
+func (p params) time(key string) (time.Time, error) {
+	ms, err := p.int64(key)
+	if err != nil || ms == 0 {
+		return time.Time{}, err
+	}
+	return fromMillis(ms), nil
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func fromMillis(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+// price formats decimal the way the API does, with 8 decimal places.
+func price(d binance.Decimal) string {
+	return d.StringFixed(8)
+}
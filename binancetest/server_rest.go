@@ -0,0 +1,704 @@
+package binancetest
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/rootpd/binance"
+	"github.com/rootpd/binance/sim"
+)
+
+// object is JSON object of the response.
+type object map[string]interface{}
+
+func (s *Server) ping(ctx context.Context, p params) (interface{}, error) {
+	return object{}, s.Fake.Ping(ctx)
+}
+
+func (s *Server) time(ctx context.Context, p params) (interface{}, error) {
+	t, err := s.Fake.Time(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return object{"serverTime": millis(t)}, nil
+}
+
+func (s *Server) depth(ctx context.Context, p params) (interface{}, error) {
+	symbol, err := p.symbol()
+	if err != nil {
+		return nil, err
+	}
+	limit, err := p.int64("limit")
+	if err != nil {
+		return nil, err
+	}
+	ob, err := s.Fake.OrderBook(ctx, binance.OrderBookRequest{Symbol: symbol, Limit: int(limit)})
+	if err != nil {
+		return nil, err
+	}
+	return object{
+		"lastUpdateId": ob.LastUpdateID,
+		"bids":         levels(ob.Bids),
+		"asks":         levels(ob.Asks),
+	}, nil
+}
+
+func levels(orders []*binance.Order) [][]interface{} {
+	levels := [][]interface{}{}
+	for _, o := range orders {
+		levels = append(levels, []interface{}{price(o.Price), price(o.Quantity), []interface{}{}})
+	}
+	return levels
+}
+
+func (s *Server) aggTrades(ctx context.Context, p params) (interface{}, error) {
+	symbol, err := p.symbol()
+	if err != nil {
+		return nil, err
+	}
+	atr := binance.AggTradesRequest{Symbol: symbol}
+	if atr.FromID, err = p.int64("fromId"); err != nil {
+		return nil, err
+	}
+	if atr.StartTime, err = p.int64("startTime"); err != nil {
+		return nil, err
+	}
+	if atr.EndTime, err = p.int64("endTime"); err != nil {
+		return nil, err
+	}
+	limit, err := p.int64("limit")
+	if err != nil {
+		return nil, err
+	}
+	atr.Limit = int(limit)
+	trades, err := s.Fake.AggTrades(ctx, atr)
+	if err != nil {
+		return nil, err
+	}
+	res := []object{}
+	for _, t := range trades {
+		res = append(res, object{
+			"a": t.ID,
+			"p": price(t.Price),
+			"q": price(t.Quantity),
+			"f": t.FirstTradeID,
+			"l": t.LastTradeID,
+			"T": millis(t.Timestamp),
+			"m": t.BuyerMaker,
+			"M": t.BestPriceMatch,
+		})
+	}
+	return res, nil
+}
+
+func (s *Server) trades(ctx context.Context, p params) (interface{}, error) {
+	symbol, err := p.symbol()
+	if err != nil {
+		return nil, err
+	}
+	limit, err := p.int64("limit")
+	if err != nil {
+		return nil, err
+	}
+	trades, err := s.Fake.Trades(ctx, binance.TradesRequest{Symbol: symbol, Limit: int(limit)})
+	if err != nil {
+		return nil, err
+	}
+	return marketTrades(trades), nil
+}
+
+func (s *Server) historicalTrades(ctx context.Context, p params) (interface{}, error) {
+	symbol, err := p.symbol()
+	if err != nil {
+		return nil, err
+	}
+	htr := binance.HistoricalTradesRequest{Symbol: symbol}
+	limit, err := p.int64("limit")
+	if err != nil {
+		return nil, err
+	}
+	htr.Limit = int(limit)
+	if htr.FromID, err = p.int64("fromId"); err != nil {
+		return nil, err
+	}
+	trades, err := s.Fake.HistoricalTrades(ctx, htr)
+	if err != nil {
+		return nil, err
+	}
+	return marketTrades(trades), nil
+}
+
+func marketTrades(trades []*binance.MarketTrade) []object {
+	res := []object{}
+	for _, t := range trades {
+		res = append(res, object{
+			"id":           t.ID,
+			"price":        price(t.Price),
+			"qty":          price(t.Qty),
+			"quoteQty":     price(t.QuoteQty),
+			"time":         millis(t.Time),
+			"isBuyerMaker": t.IsBuyerMaker,
+			"isBestMatch":  t.IsBestMatch,
+		})
+	}
+	return res
+}
+
+func (s *Server) klines(ctx context.Context, p params) (interface{}, error) {
+	symbol, err := p.symbol()
+	if err != nil {
+		return nil, err
+	}
+	if p.get("interval") == "" {
+		return nil, mandatory("interval")
+	}
+	kr := binance.KlinesRequest{Symbol: symbol, Interval: binance.Interval(p.get("interval"))}
+	if kr.StartTime, err = p.int64("startTime"); err != nil {
+		return nil, err
+	}
+	if kr.EndTime, err = p.int64("endTime"); err != nil {
+		return nil, err
+	}
+	limit, err := p.int64("limit")
+	if err != nil {
+		return nil, err
+	}
+	kr.Limit = int(limit)
+	klines, err := s.Fake.Klines(ctx, kr)
+	if err != nil {
+		return nil, err
+	}
+	res := [][]interface{}{}
+	for _, k := range klines {
+		res = append(res, []interface{}{
+			millis(k.OpenTime),
+			price(k.Open),
+			price(k.High),
+			price(k.Low),
+			price(k.Close),
+			price(k.Volume),
+			millis(k.CloseTime),
+			price(k.QuoteAssetVolume),
+			k.NumberOfTrades,
+			price(k.TakerBuyBaseAssetVolume),
+			price(k.TakerBuyQuoteAssetVolume),
+			"0",
+		})
+	}
+	return res, nil
+}
+
+// ticker24 returns tickers of all symbols if the symbol is omitted.
+func (s *Server) ticker24(ctx context.Context, p params) (interface{}, error) {
+	if p.get("symbol") == "" {
+		tickers, err := s.Fake.TickerAll24(ctx)
+		if err != nil {
+			return nil, err
+		}
+		res := []object{}
+		for _, t := range tickers {
+			res = append(res, ticker24(t))
+		}
+		return res, nil
+	}
+	t, err := s.Fake.Ticker24(ctx, binance.TickerRequest{Symbol: p.get("symbol")})
+	if err != nil {
+		return nil, err
+	}
+	return ticker24(t), nil
+}
+
+func ticker24(t *binance.Ticker24) object {
+	return object{
+		"symbol":             t.Symbol,
+		"priceChange":        price(t.PriceChange),
+		"priceChangePercent": t.PriceChangePercent.StringFixed(3),
+		"weightedAvgPrice":   price(t.WeightedAvgPrice),
+		"prevClosePrice":     price(t.PrevClosePrice),
+		"lastPrice":          price(t.LastPrice),
+		"bidPrice":           price(t.BidPrice),
+		"askPrice":           price(t.AskPrice),
+		"openPrice":          price(t.OpenPrice),
+		"highPrice":          price(t.HighPrice),
+		"lowPrice":           price(t.LowPrice),
+		"volume":             price(t.Volume),
+		"openTime":           millis(t.OpenTime),
+		"closeTime":          millis(t.CloseTime),
+		"firstId":            t.FirstID,
+		"lastId":             t.LastID,
+		"count":              t.Count,
+	}
+}
+
+func (s *Server) tickerPrice(ctx context.Context, p params) (interface{}, error) {
+	symbol, err := p.symbol()
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.Fake.TickerPrice(ctx, binance.TickerRequest{Symbol: symbol})
+	if err != nil {
+		return nil, err
+	}
+	return object{"symbol": t.Symbol, "price": price(t.Price)}, nil
+}
+
+func (s *Server) tickerBook(ctx context.Context, p params) (interface{}, error) {
+	symbol, err := p.symbol()
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.Fake.TickerBook(ctx, binance.TickerRequest{Symbol: symbol})
+	if err != nil {
+		return nil, err
+	}
+	return bookTicker(t), nil
+}
+
+func (s *Server) avgPrice(ctx context.Context, p params) (interface{}, error) {
+	symbol, err := p.symbol()
+	if err != nil {
+		return nil, err
+	}
+	ap, err := s.Fake.AvgPrice(ctx, binance.AvgPriceRequest{Symbol: symbol})
+	if err != nil {
+		return nil, err
+	}
+	return object{"mins": ap.Mins, "price": price(ap.Price)}, nil
+}
+
+func (s *Server) allPrices(ctx context.Context, p params) (interface{}, error) {
+	prices, err := s.Fake.TickerAllPrices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := []object{}
+	for _, t := range prices {
+		res = append(res, object{"symbol": t.Symbol, "price": price(t.Price)})
+	}
+	return res, nil
+}
+
+func (s *Server) allBookTickers(ctx context.Context, p params) (interface{}, error) {
+	books, err := s.Fake.TickerAllBooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := []object{}
+	for _, t := range books {
+		res = append(res, bookTicker(t))
+	}
+	return res, nil
+}
+
+func bookTicker(t *binance.BookTicker) object {
+	return object{
+		"symbol":   t.Symbol,
+		"bidPrice": price(t.BidPrice),
+		"bidQty":   price(t.BidQty),
+		"askPrice": price(t.AskPrice),
+		"askQty":   price(t.AskQty),
+	}
+}
+
+// exchangeInfo publishes Limits enforced by the server.
+func (s *Server) exchangeInfo(ctx context.Context, p params) (interface{}, error) {
+	ei, err := s.Fake.ExchangeInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	limits := []object{}
+	for _, rl := range sim.RateLimits(s.Limits) {
+		limits = append(limits, object{
+			"rateLimitType": rl.Type,
+			"interval":      rl.Interval,
+			"intervalNum":   rl.IntervalNum,
+			"limit":         rl.Limit,
+		})
+	}
+	symbols := []object{}
+	for _, si := range ei.Symbols {
+		symbols = append(symbols, object{
+			"symbol":             si.Symbol,
+			"status":             si.Status,
+			"baseAsset":          si.BaseAsset,
+			"baseAssetPrecision": si.BaseAssetPrecision,
+			"quoteAsset":         si.QuoteAsset,
+			"quotePrecision":     si.QuotePrecision,
+			"orderTypes":         si.OrderTypes,
+			"icebergAllowed":     si.IcebergAllowed,
+			"filters":            filters(si.Filters),
+		})
+	}
+	return object{
+		"timezone":   ei.Timezone,
+		"serverTime": millis(ei.ServerTime),
+		"rateLimits": limits,
+		"symbols":    symbols,
+	}, nil
+}
+
+func filters(f binance.SymbolFilters) []object {
+	res := []object{}
+	if f.Price != nil {
+		res = append(res, object{
+			"filterType": "PRICE_FILTER",
+			"minPrice":   price(f.Price.MinPrice),
+			"maxPrice":   price(f.Price.MaxPrice),
+			"tickSize":   price(f.Price.TickSize),
+		})
+	}
+	if f.PercentPrice != nil {
+		res = append(res, object{
+			"filterType":     "PERCENT_PRICE",
+			"multiplierUp":   f.PercentPrice.MultiplierUp.String(),
+			"multiplierDown": f.PercentPrice.MultiplierDown.String(),
+			"avgPriceMins":   f.PercentPrice.AvgPriceMins,
+		})
+	}
+	for i, lot := range []*binance.LotSizeFilter{f.LotSize, f.MarketLotSize} {
+		if lot != nil {
+			res = append(res, object{
+				"filterType": []string{"LOT_SIZE", "MARKET_LOT_SIZE"}[i],
+				"minQty":     price(lot.MinQty),
+				"maxQty":     price(lot.MaxQty),
+				"stepSize":   price(lot.StepSize),
+			})
+		}
+	}
+	if f.MinNotional != nil {
+		res = append(res, object{
+			"filterType":    "MIN_NOTIONAL",
+			"minNotional":   price(f.MinNotional.MinNotional),
+			"applyToMarket": f.MinNotional.ApplyToMarket,
+			"avgPriceMins":  f.MinNotional.AvgPriceMins,
+		})
+	}
+	if f.IcebergParts != nil {
+		res = append(res, object{"filterType": "ICEBERG_PARTS", "limit": f.IcebergParts.Limit})
+	}
+	if f.MaxNumOrders != nil {
+		res = append(res, object{"filterType": "MAX_NUM_ORDERS", "maxNumOrders": f.MaxNumOrders.Limit})
+	}
+	if f.MaxNumAlgoOrders != nil {
+		res = append(res, object{"filterType": "MAX_NUM_ALGO_ORDERS", "maxNumAlgoOrders": f.MaxNumAlgoOrders.Limit})
+	}
+	return res
+}
+
+func newOrderRequest(p params) (binance.NewOrderRequest, error) {
+	symbol, err := p.symbol()
+	if err != nil {
+		return binance.NewOrderRequest{}, err
+	}
+	or := binance.NewOrderRequest{
+		Symbol:           symbol,
+		Side:             binance.OrderSide(p.get("side")),
+		Type:             binance.OrderType(p.get("type")),
+		TimeInForce:      binance.TimeInForce(p.get("timeInForce")),
+		NewClientOrderID: p.get("newClientOrderId"),
+	}
+	if or.Quantity, err = p.decimal("quantity"); err != nil {
+		return or, err
+	}
+	if or.Price, err = p.decimal("price"); err != nil {
+		return or, err
+	}
+	if or.StopPrice, err = p.decimal("stopPrice"); err != nil {
+		return or, err
+	}
+	if or.IcebergQty, err = p.decimal("icebergQty"); err != nil {
+		return or, err
+	}
+	if or.Timestamp, err = p.time("timestamp"); err != nil {
+		return or, err
+	}
+	return or, nil
+}
+
+func (s *Server) newOrder(ctx context.Context, p params) (interface{}, error) {
+	or, err := newOrderRequest(p)
+	if err != nil {
+		return nil, err
+	}
+	po, err := s.Fake.NewOrder(ctx, or)
+	if err != nil {
+		return nil, err
+	}
+	return object{
+		"symbol":        po.Symbol,
+		"orderId":       po.OrderID,
+		"clientOrderId": po.ClientOrderID,
+		"transactTime":  millis(po.TransactTime),
+	}, nil
+}
+
+func (s *Server) newOrderTest(ctx context.Context, p params) (interface{}, error) {
+	or, err := newOrderRequest(p)
+	if err != nil {
+		return nil, err
+	}
+	return object{}, s.Fake.NewOrderTest(ctx, or)
+}
+
+// orderID returns orderId and origClientOrderId, at least one of them is required.
+func orderID(p params) (int64, string, error) {
+	id, err := p.int64("orderId")
+	if err != nil {
+		return 0, "", err
+	}
+	if id == 0 && p.get("origClientOrderId") == "" {
+		return 0, "", apiError(-1102, "Param 'origClientOrderId' or 'orderId' must be sent, but both were empty/null!")
+	}
+	return id, p.get("origClientOrderId"), nil
+}
+
+func (s *Server) queryOrder(ctx context.Context, p params) (interface{}, error) {
+	symbol, err := p.symbol()
+	if err != nil {
+		return nil, err
+	}
+	id, clientID, err := orderID(p)
+	if err != nil {
+		return nil, err
+	}
+	eo, err := s.Fake.QueryOrder(ctx, binance.QueryOrderRequest{Symbol: symbol, OrderID: id, OrigClientOrderID: clientID})
+	if err != nil {
+		return nil, err
+	}
+	return executedOrder(eo), nil
+}
+
+func executedOrder(eo *binance.ExecutedOrder) object {
+	return object{
+		"symbol":        eo.Symbol,
+		"orderId":       eo.OrderID,
+		"clientOrderId": eo.ClientOrderID,
+		"price":         price(eo.Price),
+		"origQty":       price(eo.OrigQty),
+		"executedQty":   price(eo.ExecutedQty),
+		"status":        eo.Status,
+		"timeInForce":   eo.TimeInForce,
+		"type":          eo.Type,
+		"side":          eo.Side,
+		"stopPrice":     price(eo.StopPrice),
+		"icebergQty":    price(eo.IcebergQty),
+		"time":          millis(eo.Time),
+		"isWorking":     true,
+	}
+}
+
+func (s *Server) cancelOrder(ctx context.Context, p params) (interface{}, error) {
+	symbol, err := p.symbol()
+	if err != nil {
+		return nil, err
+	}
+	id, clientID, err := orderID(p)
+	if err != nil {
+		return nil, err
+	}
+	co, err := s.Fake.CancelOrder(ctx, binance.CancelOrderRequest{
+		Symbol:            symbol,
+		OrderID:           id,
+		OrigClientOrderID: clientID,
+		NewClientOrderID:  p.get("newClientOrderId"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return object{
+		"symbol":            co.Symbol,
+		"origClientOrderId": co.OrigClientOrderID,
+		"orderId":           co.OrderID,
+		"clientOrderId":     co.ClientOrderID,
+	}, nil
+}
+
+func (s *Server) openOrders(ctx context.Context, p params) (interface{}, error) {
+	orders, err := s.Fake.OpenOrders(ctx, binance.OpenOrdersRequest{Symbol: p.get("symbol")})
+	if err != nil {
+		return nil, err
+	}
+	return executedOrders(orders), nil
+}
+
+func (s *Server) allOrders(ctx context.Context, p params) (interface{}, error) {
+	symbol, err := p.symbol()
+	if err != nil {
+		return nil, err
+	}
+	aor := binance.AllOrdersRequest{Symbol: symbol}
+	if aor.OrderID, err = p.int64("orderId"); err != nil {
+		return nil, err
+	}
+	limit, err := p.int64("limit")
+	if err != nil {
+		return nil, err
+	}
+	aor.Limit = int(limit)
+	orders, err := s.Fake.AllOrders(ctx, aor)
+	if err != nil {
+		return nil, err
+	}
+	return executedOrders(orders), nil
+}
+
+func executedOrders(orders []*binance.ExecutedOrder) []object {
+	res := []object{}
+	for _, eo := range orders {
+		res = append(res, executedOrder(eo))
+	}
+	return res
+}
+
+func (s *Server) account(ctx context.Context, p params) (interface{}, error) {
+	a, err := s.Fake.Account(ctx, binance.AccountRequest{})
+	if err != nil {
+		return nil, err
+	}
+	balances := []object{}
+	for _, b := range a.Balances {
+		balances = append(balances, object{"asset": b.Asset, "free": price(b.Free), "locked": price(b.Locked)})
+	}
+	return object{
+		"makerCommission":  a.MakerCommision,
+		"takerCommission":  a.TakerCommision,
+		"buyerCommission":  a.BuyerCommision,
+		"sellerCommission": a.SellerCommision,
+		"canTrade":         a.CanTrade,
+		"canWithdraw":      a.CanWithdraw,
+		"canDeposit":       a.CanDeposit,
+		"balances":         balances,
+	}, nil
+}
+
+func (s *Server) myTrades(ctx context.Context, p params) (interface{}, error) {
+	symbol, err := p.symbol()
+	if err != nil {
+		return nil, err
+	}
+	mtr := binance.MyTradesRequest{Symbol: symbol}
+	if mtr.FromID, err = p.int64("fromId"); err != nil {
+		return nil, err
+	}
+	limit, err := p.int64("limit")
+	if err != nil {
+		return nil, err
+	}
+	mtr.Limit = int(limit)
+	trades, err := s.Fake.MyTrades(ctx, mtr)
+	if err != nil {
+		return nil, err
+	}
+	res := []object{}
+	for _, t := range trades {
+		res = append(res, object{
+			"id":              t.ID,
+			"price":           price(t.Price),
+			"qty":             price(t.Qty),
+			"commission":      price(t.Commission),
+			"commissionAsset": t.CommissionAsset,
+			"time":            millis(t.Time),
+			"isBuyer":         t.IsBuyer,
+			"isMaker":         t.IsMaker,
+			"isBestMatch":     t.IsBestMatch,
+		})
+	}
+	return res, nil
+}
+
+func (s *Server) withdraw(ctx context.Context, p params) (interface{}, error) {
+	amount, err := p.decimal("amount")
+	if err != nil {
+		return nil, err
+	}
+	wr, err := s.Fake.Withdraw(ctx, binance.WithdrawRequest{
+		Asset:   p.get("asset"),
+		Address: p.get("address"),
+		Amount:  amount,
+		Name:    p.get("name"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return object{"msg": wr.Msg, "success": wr.Success}, nil
+}
+
+func historyRequest(p params) (binance.HistoryRequest, error) {
+	hr := binance.HistoryRequest{Asset: p.get("asset")}
+	if raw := p.get("status"); raw != "" {
+		status, err := strconv.Atoi(raw)
+		if err != nil {
+			return hr, mandatory("status")
+		}
+		hr.Status = &status
+	}
+	var err error
+	if hr.StartTime, err = p.time("startTime"); err != nil {
+		return hr, err
+	}
+	if hr.EndTime, err = p.time("endTime"); err != nil {
+		return hr, err
+	}
+	return hr, nil
+}
+
+func (s *Server) depositHistory(ctx context.Context, p params) (interface{}, error) {
+	hr, err := historyRequest(p)
+	if err != nil {
+		return nil, err
+	}
+	deposits, err := s.Fake.DepositHistory(ctx, hr)
+	if err != nil {
+		return nil, err
+	}
+	list := []object{}
+	for _, d := range deposits {
+		list = append(list, object{
+			"insertTime": millis(d.InsertTime),
+			"amount":     json.Number(d.Amount.String()),
+			"asset":      d.Asset,
+			"status":     d.Status,
+		})
+	}
+	return object{"depositList": list, "success": true}, nil
+}
+
+func (s *Server) withdrawHistory(ctx context.Context, p params) (interface{}, error) {
+	hr, err := historyRequest(p)
+	if err != nil {
+		return nil, err
+	}
+	withdrawals, err := s.Fake.WithdrawHistory(ctx, hr)
+	if err != nil {
+		return nil, err
+	}
+	list := []object{}
+	for _, w := range withdrawals {
+		list = append(list, object{
+			"amount":    json.Number(w.Amount.String()),
+			"address":   w.Address,
+			"txId":      w.TxID,
+			"asset":     w.Asset,
+			"applyTime": millis(w.ApplyTime),
+			"status":    w.Status,
+		})
+	}
+	return object{"withdrawList": list, "success": true}, nil
+}
+
+func (s *Server) startUserDataStream(ctx context.Context, p params) (interface{}, error) {
+	stream, err := s.Fake.StartUserDataStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return object{"listenKey": stream.ListenKey}, nil
+}
+
+func (s *Server) keepAliveUserDataStream(ctx context.Context, p params) (interface{}, error) {
+	return object{}, s.Fake.KeepAliveUserDataStream(ctx, &binance.Stream{ListenKey: p.get("listenKey")})
+}
+
+func (s *Server) closeUserDataStream(ctx context.Context, p params) (interface{}, error) {
+	return object{}, s.Fake.CloseUserDataStream(ctx, &binance.Stream{ListenKey: p.get("listenKey")})
+}
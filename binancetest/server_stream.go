@@ -0,0 +1,273 @@
+package binancetest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/rootpd/binance"
+)
+
+// streamPath returns path of websocket stream requested by req and reports
+// whether req opens a stream. Raw streams are named without "ws/" prefix, e.g.
+// "bnbbtc@depth", combined streams keep their query, e.g.
+// "stream?streams=bnbbtc@depth/ethbtc@depth".
+func streamPath(req *http.Request) (string, bool) {
+	path := strings.TrimPrefix(req.URL.Path, "/")
+	switch {
+	case strings.HasPrefix(path, "ws/"):
+		return strings.TrimPrefix(path, "ws/"), true
+	case path == "stream":
+		return "stream?" + req.URL.RawQuery, true
+	}
+	return path, false
+}
+
+// streamURL returns URL of the stream path returned by streamPath.
+func streamURL(base, path string) string {
+	if strings.HasPrefix(path, "stream?") {
+		return fmt.Sprintf("%s/%s", strings.TrimSuffix(base, "/"), path)
+	}
+	return fmt.Sprintf("%s/ws/%s", strings.TrimSuffix(base, "/"), path)
+}
+
+// serveStream serves websocket stream named by path, e.g. "bnbbtc@depth",
+// "bnbbtc@kline_1m", "bnbbtc@aggTrade", listen key of user data stream or
+// combined stream of those.
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request, path string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		events chan object
+		done   chan struct{}
+		err    error
+	)
+	if strings.HasPrefix(path, "stream?") {
+		events, done, err = s.subscribeCombined(ctx, strings.TrimPrefix(path, "stream?"))
+	} else {
+		events, done, err = s.subscribe(ctx, path)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	c, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer c.Close()
+
+	// client closes the stream by closing the connection
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := c.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		case event := <-events:
+			if err := c.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// subscribeCombined opens streams listed in the query and wraps their events
+// with stream names. Returned channel is closed when any of the streams ends.
+func (s *Server) subscribeCombined(ctx context.Context, query string) (chan object, chan struct{}, error) {
+	values, err := url.ParseQuery(query)
+	if err != nil || values.Get("streams") == "" {
+		return nil, nil, apiError(-1100, "Illegal characters found in parameter 'streams'.")
+	}
+	events := make(chan object)
+	done := make(chan struct{})
+	var once sync.Once
+	for _, name := range strings.Split(values.Get("streams"), "/") {
+		sub, subDone, err := s.subscribe(ctx, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		go func(name string) {
+			for {
+				select {
+				case event := <-sub:
+					select {
+					case events <- object{"stream": name, "data": event}:
+					case <-ctx.Done():
+						return
+					}
+				case <-subDone:
+					once.Do(func() { close(done) })
+					return
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(name)
+	}
+	return events, done, nil
+}
+
+// subscribe opens stream of FakeService named by path and returns its events
+// in wire format.
+func (s *Server) subscribe(ctx context.Context, path string) (chan object, chan struct{}, error) {
+	events := make(chan object)
+	name, kind := path, ""
+	if i := strings.Index(path, "@"); i >= 0 {
+		name, kind = path[:i], path[i+1:]
+	}
+	symbol := name
+	for _, sym := range s.Fake.Exchange.Symbols() {
+		if strings.EqualFold(sym.Name, name) {
+			symbol = sym.Name
+		}
+	}
+
+	forward := func(next func() (object, bool)) {
+		go func() {
+			for {
+				event, ok := next()
+				if !ok {
+					return
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	switch {
+	case kind == "depth":
+		dech, done, err := s.Fake.DepthWebsocket(ctx, binance.DepthWebsocketRequest{Symbol: symbol})
+		if err != nil {
+			return nil, nil, err
+		}
+		forward(func() (object, bool) {
+			select {
+			case de := <-dech:
+				return object{
+					"e": de.Type,
+					"E": millis(de.Time),
+					"s": de.Symbol,
+					"U": de.FirstUpdateID,
+					"u": de.UpdateID,
+					"b": levels(de.Bids),
+					"a": levels(de.Asks),
+				}, true
+			case <-done:
+				return nil, false
+			}
+		})
+		return events, done, nil
+	case strings.HasPrefix(kind, "kline_"):
+		interval := binance.Interval(strings.TrimPrefix(kind, "kline_"))
+		kech, done, err := s.Fake.KlineWebsocket(ctx, binance.KlineWebsocketRequest{Symbol: symbol, Interval: interval})
+		if err != nil {
+			return nil, nil, err
+		}
+		forward(func() (object, bool) {
+			select {
+			case ke := <-kech:
+				return object{
+					"e": ke.Type,
+					"E": millis(ke.Time),
+					"s": ke.Symbol,
+					"k": object{
+						"t": millis(ke.OpenTime),
+						"T": millis(ke.CloseTime),
+						"s": ke.Symbol,
+						"i": ke.Interval,
+						"f": ke.FirstTradeID,
+						"L": ke.LastTradeID,
+						"o": price(ke.Open),
+						"c": price(ke.Close),
+						"h": price(ke.High),
+						"l": price(ke.Low),
+						"v": price(ke.Volume),
+						"n": ke.NumberOfTrades,
+						"x": ke.Final,
+						"q": price(ke.QuoteAssetVolume),
+						"V": price(ke.TakerBuyBaseAssetVolume),
+						"Q": price(ke.TakerBuyQuoteAssetVolume),
+						"B": "0",
+					},
+				}, true
+			case <-done:
+				return nil, false
+			}
+		})
+		return events, done, nil
+	case kind == "aggTrade":
+		aech, done, err := s.Fake.TradeWebsocket(ctx, binance.TradeWebsocketRequest{Symbol: symbol})
+		if err != nil {
+			return nil, nil, err
+		}
+		forward(func() (object, bool) {
+			select {
+			case ae := <-aech:
+				return object{
+					"e": ae.Type,
+					"E": millis(ae.Time),
+					"s": ae.Symbol,
+					"a": ae.ID,
+					"p": price(ae.Price),
+					"q": price(ae.Quantity),
+					"f": ae.FirstTradeID,
+					"l": ae.LastTradeID,
+					"T": millis(ae.Timestamp),
+					"m": ae.BuyerMaker,
+					"M": true,
+				}, true
+			case <-done:
+				return nil, false
+			}
+		})
+		return events, done, nil
+	case kind == "":
+		aech, done, err := s.Fake.UserDataWebsocket(ctx, binance.UserDataWebsocketRequest{ListenKey: path})
+		if err != nil {
+			return nil, nil, err
+		}
+		forward(func() (object, bool) {
+			select {
+			case ae := <-aech:
+				balances := []object{}
+				for _, b := range ae.Balances {
+					balances = append(balances, object{"a": b.Asset, "f": price(b.Free), "l": price(b.Locked)})
+				}
+				return object{
+					"e": ae.Type,
+					"E": millis(ae.Time),
+					"m": ae.MakerCommision,
+					"t": ae.TakerCommision,
+					"b": ae.BuyerCommision,
+					"s": ae.SellerCommision,
+					"T": ae.CanTrade,
+					"W": ae.CanWithdraw,
+					"D": ae.CanDeposit,
+					"B": balances,
+				}, true
+			case <-done:
+				return nil, false
+			}
+		})
+		return events, done, nil
+	}
+	return nil, nil, apiError(-1000, "Unknown stream.")
+}
@@ -0,0 +1,313 @@
+package binancetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rootpd/binance"
+	"github.com/rootpd/binance/sim"
+)
+
+func newServer() (*Server, binance.Binance) {
+	srv := NewServer(newFake().Exchange)
+	srv.AddAPIKey("key", &binance.HmacSigner{Key: []byte("secret")})
+	service := binance.NewAPIService("", "key", &binance.HmacSigner{Key: []byte("secret")}, nil, nil,
+		binance.WithEnvironment(srv.Environment()))
+	return srv, binance.NewBinance(service)
+}
+
+func TestServerREST(t *testing.T) {
+	srv, b := newServer()
+	defer srv.Close()
+	e := srv.Fake.Exchange
+	e.SetCommission(d("0.001"))
+	e.AddLiquidity("BNBETH", binance.SideSell, d("0.01"), d("100"))
+	e.AddLiquidity("BNBETH", binance.SideBuy, d("0.008"), d("50"))
+
+	if err := b.Ping(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ob, err := b.OrderBook(binance.OrderBookRequest{Symbol: "BNBETH", Limit: 5})
+	if err != nil || len(ob.Bids) != 1 || !ob.Bids[0].Price.Equal(d("0.008")) {
+		t.Fatalf("unexpected order book %+v: %v", ob, err)
+	}
+
+	po, err := b.NewOrder(binance.NewOrderRequest{
+		Symbol: "BNBETH", Side: binance.SideBuy, Type: binance.TypeLimit, TimeInForce: binance.GTC,
+		Quantity: d("150"), Price: d("0.01"), NewClientOrderID: "my-order",
+	})
+	if err != nil || po.ClientOrderID != "my-order" {
+		t.Fatalf("unexpected order %+v: %v", po, err)
+	}
+	eo, err := b.QueryOrder(binance.QueryOrderRequest{Symbol: "BNBETH", OrigClientOrderID: "my-order"})
+	if err != nil || eo.Status != binance.StatusPartiallyFilled || !eo.ExecutedQty.Equal(d("100")) {
+		t.Fatalf("unexpected order %+v: %v", eo, err)
+	}
+	if open, err := b.OpenOrders(binance.OpenOrdersRequest{Symbol: "BNBETH"}); err != nil || len(open) != 1 {
+		t.Errorf("unexpected open orders %+v: %v", open, err)
+	}
+	trades, err := b.MyTrades(binance.MyTradesRequest{Symbol: "BNBETH"})
+	if err != nil || len(trades) != 1 || !trades[0].Qty.Equal(d("100")) {
+		t.Fatalf("unexpected trades %+v: %v", trades, err)
+	}
+	if trades, err := b.MyTrades(binance.MyTradesRequest{Symbol: "BNBETH", FromID: trades[0].ID + 1}); err != nil || len(trades) != 0 {
+		t.Errorf("unexpected trades from ID %+v: %v", trades, err)
+	}
+	if aggTrades, err := b.AggTrades(binance.AggTradesRequest{Symbol: "BNBETH"}); err != nil || len(aggTrades) != 1 {
+		t.Errorf("unexpected agg trades %+v: %v", aggTrades, err)
+	}
+	klines, err := b.Klines(binance.KlinesRequest{Symbol: "BNBETH", Interval: binance.Minute})
+	if err != nil || len(klines) != 1 || !klines[0].Volume.Equal(d("100")) {
+		t.Errorf("unexpected klines %+v: %v", klines, err)
+	}
+	if ticker, err := b.Ticker24(binance.TickerRequest{Symbol: "BNBETH"}); err != nil || !ticker.LastPrice.Equal(d("0.01")) {
+		t.Errorf("unexpected ticker %+v: %v", ticker, err)
+	}
+	if prices, err := b.TickerAllPrices(); err != nil || len(prices) != 1 || !prices[0].Price.Equal(d("0.01")) {
+		t.Errorf("unexpected prices %+v: %v", prices, err)
+	}
+	if books, err := b.TickerAllBooks(); err != nil || len(books) != 1 || !books[0].BidPrice.Equal(d("0.01")) {
+		t.Errorf("unexpected books %+v: %v", books, err)
+	}
+	marketTrades, err := b.Trades(binance.TradesRequest{Symbol: "BNBETH"})
+	if err != nil || len(marketTrades) != 1 || !marketTrades[0].QuoteQty.Equal(d("1")) || marketTrades[0].IsBuyerMaker {
+		t.Errorf("unexpected trades %+v: %v", marketTrades, err)
+	}
+	if historical, err := b.HistoricalTrades(binance.HistoricalTradesRequest{Symbol: "BNBETH", FromID: marketTrades[0].ID + 1}); err != nil || len(historical) != 0 {
+		t.Errorf("unexpected historical trades %+v: %v", historical, err)
+	}
+	if tickers, err := b.TickerAll24(); err != nil || len(tickers) != 1 || tickers[0].Symbol != "BNBETH" || tickers[0].Count != 1 {
+		t.Errorf("unexpected tickers %+v: %v", tickers, err)
+	}
+	if price, err := b.TickerPrice(binance.TickerRequest{Symbol: "BNBETH"}); err != nil || !price.Price.Equal(d("0.01")) {
+		t.Errorf("unexpected price %+v: %v", price, err)
+	}
+	if book, err := b.TickerBook(binance.TickerRequest{Symbol: "BNBETH"}); err != nil || !book.BidQty.Equal(d("50")) {
+		t.Errorf("unexpected book %+v: %v", book, err)
+	}
+	if avg, err := b.AvgPrice(binance.AvgPriceRequest{Symbol: "BNBETH"}); err != nil || avg.Mins != 5 || !avg.Price.Equal(d("0.01")) {
+		t.Errorf("unexpected average price %+v: %v", avg, err)
+	}
+
+	e.AddSymbol(sim.Symbol{Name: "BNBBTC", BaseAsset: "BNB", QuoteAsset: "BTC", Filters: binance.SymbolFilters{
+		Price:        &binance.PriceFilter{MinPrice: d("0.0000001"), TickSize: d("0.0000001")},
+		LotSize:      &binance.LotSizeFilter{MinQty: d("0.01"), MaxQty: d("90000"), StepSize: d("0.01")},
+		MinNotional:  &binance.MinNotionalFilter{MinNotional: d("0.001"), AvgPriceMins: 5},
+		MaxNumOrders: &binance.MaxNumOrdersFilter{Limit: 200},
+	}})
+	ei, err := b.ExchangeInfo()
+	if err != nil || len(ei.Symbols) != 2 || ei.Limits() != srv.Limits {
+		t.Fatalf("unexpected exchange info %+v: %v", ei, err)
+	}
+	if f := ei.Symbol("BNBBTC").Filters; !f.Price.TickSize.Equal(d("0.0000001")) || !f.LotSize.MaxQty.Equal(d("90000")) ||
+		f.MaxNumOrders.Limit != 200 || !f.MinNotional.MinNotional.Equal(d("0.001")) || f.MinNotional.ApplyToMarket ||
+		f.MinNotional.AvgPriceMins != 5 {
+		t.Errorf("unexpected filters %+v", f)
+	}
+
+	co, err := b.CancelOrder(binance.CancelOrderRequest{Symbol: "BNBETH", OrderID: po.OrderID})
+	if err != nil || co.OrigClientOrderID != "my-order" {
+		t.Fatalf("unexpected cancel %+v: %v", co, err)
+	}
+	if all, err := b.AllOrders(binance.AllOrdersRequest{Symbol: "BNBETH"}); err != nil || len(all) != 1 || all[0].Status != binance.StatusCancelled {
+		t.Errorf("unexpected orders %+v: %v", all, err)
+	}
+	account, err := b.Account(binance.AccountRequest{})
+	if err != nil || account.MakerCommision != 10 {
+		t.Fatalf("unexpected account %+v: %v", account, err)
+	}
+	for _, balance := range account.Balances {
+		if balance.Asset == "ETH" && !balance.Free.Equal(d("9")) || balance.Asset == "BNB" && !balance.Free.Equal(d("99.9")) {
+			t.Errorf("unexpected balance: %+v", balance)
+		}
+	}
+
+	if _, err := b.Withdraw(binance.WithdrawRequest{Asset: "ETH", Address: "0xabc", Amount: d("2")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	withdrawals, err := b.WithdrawHistory(binance.HistoryRequest{Asset: "ETH", EndTime: e.Now()})
+	if err != nil || len(withdrawals) != 1 || !withdrawals[0].Amount.Equal(d("2")) || withdrawals[0].ApplyTime.IsZero() {
+		t.Errorf("unexpected withdrawals %+v: %v", withdrawals, err)
+	}
+	if withdrawals, err := b.WithdrawHistory(binance.HistoryRequest{Asset: "ETH", EndTime: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)}); err != nil || len(withdrawals) != 0 {
+		t.Errorf("unexpected withdrawals before start %+v: %v", withdrawals, err)
+	}
+	e.Deposit("BNB", d("5"))
+	if deposits, err := b.DepositHistory(binance.HistoryRequest{Asset: "BNB"}); err != nil || len(deposits) != 1 {
+		t.Errorf("unexpected deposits %+v: %v", deposits, err)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	srv, b := newServer()
+	defer srv.Close()
+
+	_, err := b.NewOrder(binance.NewOrderRequest{
+		Symbol: "BNBETH", Side: binance.SideBuy, Type: binance.TypeLimit, TimeInForce: binance.GTC,
+		Quantity: d("1"), Price: d("100"),
+	})
+	if !errors.Is(err, binance.ErrInsufficientBalance) {
+		t.Errorf("expected insufficient balance, got %v", err)
+	}
+	if _, err := b.QueryOrder(binance.QueryOrderRequest{Symbol: "BNBETH", OrderID: 42}); !errors.Is(err, binance.ErrUnknownOrder) {
+		t.Errorf("expected unknown order, got %v", err)
+	}
+
+	srv.Fake.FailNext("Account", &binance.Error{Code: -1001, Message: "Internal error.", StatusCode: 503})
+	_, err = b.Account(binance.AccountRequest{})
+	if bErr, ok := err.(*binance.Error); !ok || bErr.Code != -1001 || bErr.StatusCode != 503 {
+		t.Errorf("expected programmed failure, got %v", err)
+	}
+
+	forged := binance.NewBinance(binance.NewAPIService("", "key", &binance.HmacSigner{Key: []byte("forged")}, nil, nil,
+		binance.WithEnvironment(srv.Environment())))
+	_, err = forged.Account(binance.AccountRequest{})
+	if bErr, ok := err.(*binance.Error); !ok || bErr.Code != -1022 {
+		t.Errorf("expected invalid signature, got %v", err)
+	}
+	unknown := binance.NewBinance(binance.NewAPIService("", "unknown", &binance.HmacSigner{Key: []byte("secret")}, nil, nil,
+		binance.WithEnvironment(srv.Environment())))
+	_, err = unknown.StartUserDataStream()
+	if bErr, ok := err.(*binance.Error); !ok || bErr.Code != -2015 || bErr.StatusCode != 401 {
+		t.Errorf("expected invalid API key, got %v", err)
+	}
+
+	srv.Limits.RequestWeight = 1
+	if err := b.Ping(); !errors.Is(err, binance.ErrRateLimited) {
+		t.Errorf("expected rate limit, got %v", err)
+	}
+}
+
+func TestServerWebsockets(t *testing.T) {
+	srv, b := newServer()
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	depth, _, err := b.DepthWebsocketContext(ctx, binance.DepthWebsocketRequest{Symbol: "BNBETH"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	trades, _, err := b.TradeWebsocketContext(ctx, binance.TradeWebsocketRequest{Symbol: "BNBETH"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	klines, _, err := b.KlineWebsocketContext(ctx, binance.KlineWebsocketRequest{Symbol: "BNBETH", Interval: binance.Minute})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s, err := b.StartUserDataStream()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	accounts, done, err := b.UserDataWebsocketContext(ctx, binance.UserDataWebsocketRequest{ListenKey: s.ListenKey})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	srv.Fake.Exchange.AddLiquidity("BNBETH", binance.SideBuy, d("0.01"), d("10"))
+	srv.Fake.Exchange.ExecuteTrade("BNBETH", d("0.01"), d("4"), false)
+
+	if de := <-depth; de.Symbol != "BNBETH" || len(de.Bids) != 1 || !de.Bids[0].Quantity.Equal(d("10")) {
+		t.Errorf("unexpected depth event: %+v", de)
+	}
+	if te := <-trades; !te.Quantity.Equal(d("4")) || !te.Price.Equal(d("0.01")) {
+		t.Errorf("unexpected trade event: %+v", te)
+	}
+	if ke := <-klines; !ke.Volume.Equal(d("4")) || ke.Interval != binance.Minute {
+		t.Errorf("unexpected kline event: %+v", ke)
+	}
+
+	srv.Fake.Exchange.PlaceOrder(binance.NewOrderRequest{Symbol: "BNBETH", Side: binance.SideBuy, Type: binance.TypeLimit,
+		TimeInForce: binance.GTC, Quantity: d("1"), Price: d("0.001")})
+	if ae := <-accounts; len(ae.Balances) != 1 || !ae.Balances[0].Locked.Equal(d("0.001")) {
+		t.Errorf("unexpected account event: %+v", ae)
+	}
+
+	if err := b.CloseUserDataStream(s); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	cancel()
+	<-done
+}
+
+func TestServerLocalOrderBook(t *testing.T) {
+	srv, _ := newServer()
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv.Fake.Exchange.AddLiquidity("BNBETH", binance.SideBuy, d("0.01"), d("10"))
+	book := binance.NewLocalOrderBook(binance.NewAPIService("", "", nil, nil, nil, binance.WithEnvironment(srv.Environment())), "BNBETH", nil)
+	updates, unsubscribe := book.Subscribe()
+	defer unsubscribe()
+	go book.Run(ctx)
+
+	<-updates
+	for _, price := range []string{"0.02", "0.03"} {
+		srv.Fake.Exchange.AddLiquidity("BNBETH", binance.SideSell, d(price), d("5"))
+	}
+	srv.Fake.Exchange.SetLiquidity("BNBETH", binance.SideSell, d("0.02"), d("3"))
+
+	deadline := time.After(2 * time.Second)
+	for {
+		ask, ok := book.BestAsk()
+		if ok && ask.Quantity.Equal(d("3")) {
+			break
+		}
+		select {
+		case <-updates:
+		case <-deadline:
+			t.Fatalf("book not updated: %+v", book.Depth(0))
+		}
+	}
+	ob := book.Depth(0)
+	if len(ob.Bids) != 1 || len(ob.Asks) != 2 || !ob.Bids[0].Price.Equal(d("0.01")) || ob.LastUpdateID != 4 {
+		t.Errorf("unexpected book %+v", ob)
+	}
+}
+
+func TestServerOrderBookManager(t *testing.T) {
+	srv, _ := newServer()
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv.Fake.Exchange.AddSymbol(sim.Symbol{Name: "BNBBTC", BaseAsset: "BNB", QuoteAsset: "BTC"})
+	srv.Fake.Exchange.AddLiquidity("BNBBTC", binance.SideBuy, d("0.001"), d("10"))
+	m := binance.NewOrderBookManager(binance.NewAPIService("", "", nil, nil, nil, binance.WithEnvironment(srv.Environment())), nil)
+	m.Add("BNBETH", "BNBBTC")
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for h := m.Health(); h["BNBETH"] != binance.BookSynced || h["BNBBTC"] != binance.BookSynced; h = m.Health() {
+		if time.Now().After(deadline) {
+			t.Fatalf("book not synced: %v", m.Health())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	book, _ := m.Book("BNBETH")
+	updates, unsubscribe := book.Subscribe()
+	defer unsubscribe()
+	srv.Fake.Exchange.AddLiquidity("BNBETH", binance.SideSell, d("0.02"), d("5"))
+	for ask, ok := book.BestAsk(); !ok || !ask.Price.Equal(d("0.02")); ask, ok = book.BestAsk() {
+		select {
+		case <-updates:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("book not updated: %+v", book.Depth(0))
+		}
+	}
+	other, _ := m.Book("BNBBTC")
+	if bid, ok := other.BestBid(); !ok || !bid.Quantity.Equal(d("10")) {
+		t.Errorf("unexpected BNBBTC bid %+v", bid)
+	}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,105 @@
+package binance
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPClientConfig represents configuration of HTTP client shared by all REST calls.
+//
+// Zero values of numeric fields fall back to the values of DefaultHTTPClientConfig.
+type HTTPClientConfig struct {
+	// Timeout limits the whole request including reading of the response body.
+	Timeout time.Duration
+	// DialTimeout limits establishing of TCP connection.
+	DialTimeout time.Duration
+	// KeepAlive sets TCP keep-alive period of opened connections.
+	KeepAlive time.Duration
+	// TLSHandshakeTimeout limits TLS handshake.
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout limits waiting for response headers after the request was written.
+	ResponseHeaderTimeout time.Duration
+	// IdleConnTimeout sets how long idle connection remains in the pool.
+	IdleConnTimeout time.Duration
+	// MaxIdleConns limits number of idle connections across all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost limits number of idle connections kept per host.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits total number of connections per host, 0 means no limit.
+	MaxConnsPerHost int
+	// Proxy returns proxy for given request. If nil, proxy is read from environment.
+	Proxy func(*http.Request) (*url.URL, error)
+	// DisableHTTP2 disables attempt to negotiate HTTP/2 with the server.
+	DisableHTTP2 bool
+}
+
+// DefaultHTTPClientConfig returns configuration used when no HTTP client is provided.
+func DefaultHTTPClientConfig() HTTPClientConfig {
+	return HTTPClientConfig{
+		Timeout:               30 * time.Second,
+		DialTimeout:           10 * time.Second,
+		KeepAlive:             30 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 20 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+	}
+}
+
+// NewHTTPClient creates HTTP client with connection pooling configured by cfg.
+//
+// Client is safe for concurrent use and should be created once and shared.
+func NewHTTPClient(cfg HTTPClientConfig) *http.Client {
+	def := DefaultHTTPClientConfig()
+	if cfg.Timeout == 0 {
+		cfg.Timeout = def.Timeout
+	}
+	if cfg.DialTimeout == 0 {
+		cfg.DialTimeout = def.DialTimeout
+	}
+	if cfg.KeepAlive == 0 {
+		cfg.KeepAlive = def.KeepAlive
+	}
+	if cfg.TLSHandshakeTimeout == 0 {
+		cfg.TLSHandshakeTimeout = def.TLSHandshakeTimeout
+	}
+	if cfg.ResponseHeaderTimeout == 0 {
+		cfg.ResponseHeaderTimeout = def.ResponseHeaderTimeout
+	}
+	if cfg.IdleConnTimeout == 0 {
+		cfg.IdleConnTimeout = def.IdleConnTimeout
+	}
+	if cfg.MaxIdleConns == 0 {
+		cfg.MaxIdleConns = def.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost == 0 {
+		cfg.MaxIdleConnsPerHost = def.MaxIdleConnsPerHost
+	}
+	if cfg.Proxy == nil {
+		cfg.Proxy = http.ProxyFromEnvironment
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   cfg.DialTimeout,
+		KeepAlive: cfg.KeepAlive,
+	}
+	transport := &http.Transport{
+		Proxy:                 cfg.Proxy,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     !cfg.DisableHTTP2,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		ExpectContinueTimeout: time.Second,
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   cfg.Timeout,
+	}
+}
@@ -0,0 +1,88 @@
+// Command binance-keystore manages encrypted keystore with Binance API credentials.
+//
+//	binance-keystore -keystore binance.keystore create
+//	binance-keystore -keystore binance.keystore put main hmac < secret
+//	binance-keystore -keystore binance.keystore list
+//	binance-keystore -keystore binance.keystore delete main
+//
+// Passphrase is read from key file set by -keyfile, or from BINANCE_KEYSTORE_PASSPHRASE
+// environment variable. Command put reads API key from BINANCE_APIKEY and the
+// secret (HMAC secret or PEM private key) from standard input.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/rootpd/binance/keystore"
+)
+
+func main() {
+	path := flag.String("keystore", "binance.keystore", "path to keystore")
+	keyFile := flag.String("keyfile", "", "path to key file with passphrase")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] create|list|put <name> <hmac|rsa|ed25519>|delete <name>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*path, *keyFile, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(path, keyFile string, args []string) error {
+	passphrase := []byte(os.Getenv("BINANCE_KEYSTORE_PASSPHRASE"))
+	if keyFile != "" {
+		var err error
+		if passphrase, err = keystore.ReadKeyFile(keyFile); err != nil {
+			return err
+		}
+	}
+
+	if args[0] == "create" {
+		_, err := keystore.Create(path, passphrase)
+		return err
+	}
+	ks, err := keystore.Open(path, passphrase)
+	if err != nil {
+		return err
+	}
+	switch {
+	case args[0] == "list":
+		for _, name := range ks.Names() {
+			fmt.Println(name)
+		}
+		return nil
+	case args[0] == "put" && len(args) == 3:
+		secret, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		c := keystore.Credential{
+			APIKey:  os.Getenv("BINANCE_APIKEY"),
+			KeyType: args[2],
+			Secret:  secret,
+		}
+		if c.KeyType == keystore.KeyTypeHMAC {
+			c.Secret = bytes.TrimSpace(secret)
+		}
+		if _, err := c.Signer(); err != nil {
+			return err
+		}
+		return ks.Put(args[1], c)
+	case args[0] == "delete" && len(args) == 2:
+		return ks.Delete(args[1])
+	}
+	flag.Usage()
+	os.Exit(2)
+	return nil
+}
@@ -0,0 +1,170 @@
+// Command binance-signerd runs signing daemon for binance.RemoteSigner.
+//
+// Clients are configured by JSON file:
+//
+//	{
+//		"clients": [{
+//			"id": "bot",
+//			"token": "client token",
+//			"keyType": "ed25519",
+//			"keyFile": "/etc/binance-signerd/bot.pem",
+//			"passphraseEnv": "BOT_KEY_PASSPHRASE",
+//			"policy": {
+//				"endpoints": ["POST api/v3/order", "DELETE api/v3/order"],
+//				"symbols": ["BNBETH"],
+//				"maxNotional": "10",
+//				"maxTimestampSkew": "1m"
+//			}
+//		}]
+//	}
+//
+// Key type is one of "hmac" (key file contains API secret), "rsa" or "ed25519"
+// (key file contains PEM encoded PKCS#8 private key).
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/rootpd/binance"
+	"github.com/rootpd/binance/signerd"
+)
+
+type config struct {
+	Clients []clientConfig `json:"clients"`
+}
+
+type clientConfig struct {
+	ID            string       `json:"id"`
+	Token         string       `json:"token"`
+	KeyType       string       `json:"keyType"`
+	KeyFile       string       `json:"keyFile"`
+	PassphraseEnv string       `json:"passphraseEnv"`
+	Policy        policyConfig `json:"policy"`
+}
+
+type policyConfig struct {
+	Endpoints        []string        `json:"endpoints"`
+	Symbols          []string        `json:"symbols"`
+	MaxNotional      binance.Decimal `json:"maxNotional"`
+	AllowWithdrawals bool            `json:"allowWithdrawals"`
+	MaxTimestampSkew string          `json:"maxTimestampSkew"`
+}
+
+func main() {
+	configFile := flag.String("config", "/etc/binance-signerd/config.json", "path to configuration file")
+	socket := flag.String("socket", "/run/binance-signerd.sock", "path to unix socket to listen on")
+	auditFile := flag.String("audit", "", "path to audit log, stderr if empty")
+	flag.Parse()
+
+	var logger log.Logger
+	logger = log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
+	logger = log.With(logger, "time", log.DefaultTimestampUTC)
+
+	audit := logger
+	if *auditFile != "" {
+		f, err := os.OpenFile(*auditFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			fatal(logger, err)
+		}
+		defer f.Close()
+		audit = log.NewLogfmtLogger(log.NewSyncWriter(f))
+	}
+
+	clients, err := loadClients(*configFile)
+	if err != nil {
+		fatal(logger, err)
+	}
+
+	os.Remove(*socket)
+	l, err := net.Listen("unix", *socket)
+	if err != nil {
+		fatal(logger, err)
+	}
+	if err := os.Chmod(*socket, 0600); err != nil {
+		fatal(logger, err)
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sig
+		l.Close()
+	}()
+
+	logger.Log("msg", "listening", "socket", *socket, "clients", len(clients))
+	err = signerd.NewServer(audit, clients...).Serve(l)
+	logger.Log("msg", "stopped", "err", err)
+}
+
+func loadClients(file string) ([]signerd.Client, error) {
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var cfg config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse config: %v", err)
+	}
+
+	var clients []signerd.Client
+	for _, cc := range cfg.Clients {
+		key, err := ioutil.ReadFile(cc.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		var passphrase []byte
+		if cc.PassphraseEnv != "" {
+			passphrase = []byte(os.Getenv(cc.PassphraseEnv))
+		}
+
+		var signer binance.Signer
+		switch cc.KeyType {
+		case "hmac":
+			signer = &binance.HmacSigner{Key: bytes.TrimSpace(key)}
+		case "rsa":
+			signer, err = binance.NewRSASigner(key, passphrase)
+		case "ed25519":
+			signer, err = binance.NewEd25519Signer(key, passphrase)
+		default:
+			err = fmt.Errorf("unknown key type %q", cc.KeyType)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("client %s: %v", cc.ID, err)
+		}
+
+		var skew time.Duration
+		if cc.Policy.MaxTimestampSkew != "" {
+			if skew, err = time.ParseDuration(cc.Policy.MaxTimestampSkew); err != nil {
+				return nil, fmt.Errorf("client %s: %v", cc.ID, err)
+			}
+		}
+		clients = append(clients, signerd.Client{
+			ID:     cc.ID,
+			Token:  cc.Token,
+			Signer: signer,
+			Policy: signerd.Policy{
+				Endpoints:        cc.Policy.Endpoints,
+				Symbols:          cc.Policy.Symbols,
+				MaxNotional:      cc.Policy.MaxNotional,
+				AllowWithdrawals: cc.Policy.AllowWithdrawals,
+				MaxTimestampSkew: skew,
+			},
+		})
+	}
+	return clients, nil
+}
+
+func fatal(logger log.Logger, err error) {
+	logger.Log("msg", "failed to start", "err", err)
+	os.Exit(1)
+}
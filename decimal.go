@@ -0,0 +1,352 @@
+package binance
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Decimal represents exact decimal number used for prices, quantities and
+// amounts. Zero value is 0.
+//
+// Decimal is immutable, all operations return new value. Use Cmp or Equal for
+// comparison, == compares internal representation.
+type Decimal struct {
+	value *big.Int
+	scale int32
+}
+
+// RoundingMode specifies how the value is rounded.
+type RoundingMode int
+
+var (
+	// RoundDown rounds towards zero.
+	RoundDown = RoundingMode(0)
+	// RoundUp rounds away from zero.
+	RoundUp = RoundingMode(1)
+	// RoundFloor rounds towards negative infinity.
+	RoundFloor = RoundingMode(2)
+	// RoundCeiling rounds towards positive infinity.
+	RoundCeiling = RoundingMode(3)
+	// RoundHalfUp rounds to nearest value, ties away from zero.
+	RoundHalfUp = RoundingMode(4)
+	// RoundHalfEven rounds to nearest value, ties to even.
+	RoundHalfEven = RoundingMode(5)
+)
+
+var bigTen = big.NewInt(10)
+
+// maxDecimalExponent bounds exponent of parsed decimals, so malformed input
+// can't make the value grow beyond any sane precision.
+const maxDecimalExponent = 1000
+
+// NewDecimal returns decimal equal to unscaled * 10^-scale.
+func NewDecimal(unscaled int64, scale int32) Decimal {
+	d := Decimal{value: big.NewInt(unscaled), scale: scale}
+	if scale < 0 {
+		return d.rescale(0)
+	}
+	return d
+}
+
+// NewDecimalFromInt returns decimal equal to i.
+func NewDecimalFromInt(i int64) Decimal {
+	return NewDecimal(i, 0)
+}
+
+// NewDecimalFromFloat returns decimal equal to the shortest decimal
+// representation of f. It's meant as migration helper, parse decimal from
+// string wherever possible. NaN and infinities are converted to zero.
+func NewDecimalFromFloat(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}
+	}
+	d, _ := ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+	return d
+}
+
+// ParseDecimal parses decimal number, e.g. "0.00100000", "-12" or "1e-8".
+func ParseDecimal(s string) (Decimal, error) {
+	str := s
+	var exp int64
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(str[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, errors.Wrap(err, fmt.Sprintf("unable to parse exponent of decimal: %s", s))
+		}
+		if e > maxDecimalExponent || e < -maxDecimalExponent {
+			return Decimal{}, errors.New(fmt.Sprintf("decimal exponent out of range: %s", s))
+		}
+		exp = e
+		str = str[:i]
+	}
+	digits := str
+	var frac string
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		digits = str[:i]
+		frac = str[i+1:]
+	}
+	if (digits == "" || digits == "-" || digits == "+") && frac == "" {
+		return Decimal{}, errors.New(fmt.Sprintf("unable to parse decimal: %s", s))
+	}
+	if frac != "" && (frac[0] == '-' || frac[0] == '+') {
+		return Decimal{}, errors.New(fmt.Sprintf("unable to parse decimal: %s", s))
+	}
+	value, ok := new(big.Int).SetString(digits+frac, 10)
+	if !ok {
+		return Decimal{}, errors.New(fmt.Sprintf("unable to parse decimal: %s", s))
+	}
+	scale := int64(len(frac)) - exp
+	if scale > math.MaxInt32 {
+		return Decimal{}, errors.New(fmt.Sprintf("decimal exponent out of range: %s", s))
+	}
+	d := Decimal{value: value, scale: int32(scale)}
+	if d.scale < 0 {
+		return d.rescale(0), nil
+	}
+	return d, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics if the string can't be parsed.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) unscaled() *big.Int {
+	if d.value == nil {
+		return new(big.Int)
+	}
+	return d.value
+}
+
+// rescale returns decimal with increased scale, the value doesn't change.
+func (d Decimal) rescale(scale int32) Decimal {
+	if scale <= d.scale {
+		return d
+	}
+	mul := new(big.Int).Exp(bigTen, big.NewInt(int64(scale-d.scale)), nil)
+	return Decimal{value: mul.Mul(mul, d.unscaled()), scale: scale}
+}
+
+func align(a, b Decimal) (Decimal, Decimal) {
+	if a.scale < b.scale {
+		return a.rescale(b.scale), b
+	}
+	return a, b.rescale(a.scale)
+}
+
+// Scale returns number of digits after decimal point in current representation.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	return d.unscaled().Sign()
+}
+
+// IsZero reports whether d is 0.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp compares d and o and returns -1, 0 or +1.
+func (d Decimal) Cmp(o Decimal) int {
+	a, b := align(d, o)
+	return a.unscaled().Cmp(b.unscaled())
+}
+
+// Equal reports whether d and o represent the same number.
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+// Add returns d + o.
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b := align(d, o)
+	return Decimal{value: new(big.Int).Add(a.unscaled(), b.unscaled()), scale: a.scale}
+}
+
+// Sub returns d - o.
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b := align(d, o)
+	return Decimal{value: new(big.Int).Sub(a.unscaled(), b.unscaled()), scale: a.scale}
+}
+
+// Mul returns d * o.
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{value: new(big.Int).Mul(d.unscaled(), o.unscaled()), scale: d.scale + o.scale}
+}
+
+// Div returns d / o rounded half-even to given number of decimal places.
+// Div panics if o is zero.
+func (d Decimal) Div(o Decimal, places int32) Decimal {
+	if o.IsZero() {
+		panic("binance: decimal division by zero")
+	}
+	// d/o = (dv * 10^shift) / ov * 10^-(d.scale + shift - o.scale), shift keeps one extra digit
+	shift := places + o.scale - d.scale + 1
+	num := d.rescale(d.scale + max32(shift, 0)).unscaled()
+	scale := d.scale + max32(shift, 0) - o.scale
+	q, r := new(big.Int).QuoRem(num, o.unscaled(), new(big.Int))
+	if r.Sign() != 0 {
+		// append sticky digit so the rounding sees the remainder
+		q.Mul(q, bigTen)
+		if q.Sign() < 0 || (q.Sign() == 0 && num.Sign()*o.unscaled().Sign() < 0) {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+		scale++
+	}
+	return Decimal{value: q, scale: scale}.Round(places, RoundHalfEven)
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: new(big.Int).Neg(d.unscaled()), scale: d.scale}
+}
+
+// Abs returns absolute value of d.
+func (d Decimal) Abs() Decimal {
+	return Decimal{value: new(big.Int).Abs(d.unscaled()), scale: d.scale}
+}
+
+// Round rounds d to given number of decimal places.
+func (d Decimal) Round(places int32, mode RoundingMode) Decimal {
+	if places < 0 {
+		places = 0
+	}
+	if places >= d.scale {
+		return d
+	}
+	div := new(big.Int).Exp(bigTen, big.NewInt(int64(d.scale-places)), nil)
+	q, r := new(big.Int).QuoRem(d.unscaled(), div, new(big.Int))
+	return Decimal{value: roundQuotient(q, r, div, mode), scale: places}
+}
+
+// RoundStep rounds d to the nearest multiple of step in given direction, e.g.
+// to tick size or step size of the symbol. Step must not be zero.
+func (d Decimal) RoundStep(step Decimal, mode RoundingMode) Decimal {
+	if step.IsZero() {
+		return d
+	}
+	a, b := align(d, step.Abs())
+	div := b.unscaled()
+	q, r := new(big.Int).QuoRem(a.unscaled(), div, new(big.Int))
+	q = roundQuotient(q, r, div, mode)
+	return Decimal{value: q.Mul(q, div), scale: a.scale}
+}
+
+// roundQuotient adjusts quotient truncated towards zero according to the
+// remainder and rounding mode. Divisor must be positive.
+func roundQuotient(q, r, div *big.Int, mode RoundingMode) *big.Int {
+	if r.Sign() == 0 {
+		return q
+	}
+	away := false
+	switch mode {
+	case RoundUp:
+		away = true
+	case RoundFloor:
+		away = r.Sign() < 0
+	case RoundCeiling:
+		away = r.Sign() > 0
+	case RoundHalfUp, RoundHalfEven:
+		twice := new(big.Int).Abs(r)
+		twice.Lsh(twice, 1)
+		c := twice.Cmp(div)
+		away = c > 0 || (c == 0 && (mode == RoundHalfUp || q.Bit(0) == 1))
+	}
+	if !away {
+		return q
+	}
+	if r.Sign() < 0 {
+		return q.Sub(q, big.NewInt(1))
+	}
+	return q.Add(q, big.NewInt(1))
+}
+
+// Float64 returns the nearest float64 value of d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.StringFixed(d.scale), 64)
+	return f
+}
+
+// String returns d in plain notation without trailing zeros, e.g. "0.001".
+func (d Decimal) String() string {
+	s := d.StringFixed(d.scale)
+	if strings.IndexByte(s, '.') >= 0 {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	return s
+}
+
+// StringFixed returns d rounded half-even to given number of decimal places
+// in plain notation, e.g. "0.00100000".
+func (d Decimal) StringFixed(places int32) string {
+	d = d.Round(places, RoundHalfEven).rescale(places)
+	digits := new(big.Int).Abs(d.unscaled()).String()
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale <= 0 {
+		return sign + digits
+	}
+	if len(digits) <= int(d.scale) {
+		digits = strings.Repeat("0", int(d.scale)-len(digits)+1) + digits
+	}
+	pos := len(digits) - int(d.scale)
+	return sign + digits[:pos] + "." + digits[pos:]
+}
+
+// MarshalJSON encodes d as JSON string.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON decodes d from JSON string or number.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	parsed, err := ParseDecimal(strings.Trim(s, `"`))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalText encodes d as text.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes d from text.
+func (d *Decimal) UnmarshalText(b []byte) error {
+	parsed, err := ParseDecimal(string(b))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func max32(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
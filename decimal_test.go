@@ -0,0 +1,124 @@
+package binance
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	cases := []struct {
+		in, out string
+	}{
+		{"0.00100000", "0.001"},
+		{"1000.00000000", "1000"},
+		{"-12", "-12"},
+		{".5", "0.5"},
+		{"1e-8", "0.00000001"},
+		{"1.5E3", "1500"},
+		{"92233720368.54775807", "92233720368.54775807"},
+	}
+	for _, c := range cases {
+		d, err := ParseDecimal(c.in)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.in, err)
+		}
+		if d.String() != c.out {
+			t.Errorf("%s: expected %s, got %s", c.in, c.out, d)
+		}
+	}
+	for _, in := range []string{"", "abc", "1.2.3", "1e", "1.-2", "1e2147483647", "1e-1001"} {
+		if _, err := ParseDecimal(in); err == nil {
+			t.Errorf("%q: expected error", in)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a := MustParseDecimal("0.1")
+	b := MustParseDecimal("0.2")
+	if sum := a.Add(b); !sum.Equal(MustParseDecimal("0.3")) {
+		t.Errorf("expected 0.3, got %s", sum)
+	}
+	if diff := a.Sub(b); diff.String() != "-0.1" {
+		t.Errorf("expected -0.1, got %s", diff)
+	}
+	if prod := MustParseDecimal("0.00012").Mul(NewDecimalFromInt(3)); prod.String() != "0.00036" {
+		t.Errorf("expected 0.00036, got %s", prod)
+	}
+	if q := NewDecimalFromInt(2).Div(NewDecimalFromInt(3), 8); q.String() != "0.66666667" {
+		t.Errorf("expected 0.66666667, got %s", q)
+	}
+	if q := NewDecimalFromInt(-1).Div(NewDecimalFromInt(8), 2); q.String() != "-0.12" {
+		t.Errorf("expected -0.12, got %s", q)
+	}
+	if a.Cmp(b) != -1 || b.Cmp(a) != 1 || a.Cmp(MustParseDecimal("0.10")) != 0 {
+		t.Error("unexpected comparison result")
+	}
+	var zero Decimal
+	if !zero.IsZero() || zero.String() != "0" || !zero.Add(a).Equal(a) {
+		t.Error("zero value is not usable as 0")
+	}
+}
+
+func TestDecimalRounding(t *testing.T) {
+	cases := []struct {
+		in     string
+		mode   RoundingMode
+		places int32
+		out    string
+	}{
+		{"1.2345", RoundDown, 2, "1.23"},
+		{"-1.2345", RoundDown, 2, "-1.23"},
+		{"1.2345", RoundUp, 2, "1.24"},
+		{"-1.2345", RoundFloor, 2, "-1.24"},
+		{"-1.2345", RoundCeiling, 2, "-1.23"},
+		{"1.125", RoundHalfUp, 2, "1.13"},
+		{"1.125", RoundHalfEven, 2, "1.12"},
+		{"1.135", RoundHalfEven, 2, "1.14"},
+	}
+	for _, c := range cases {
+		if r := MustParseDecimal(c.in).Round(c.places, c.mode); r.String() != c.out {
+			t.Errorf("%s rounded by %d: expected %s, got %s", c.in, c.mode, c.out, r)
+		}
+	}
+
+	step := MustParseDecimal("0.00500000")
+	if r := MustParseDecimal("1.23456").RoundStep(step, RoundDown); r.String() != "1.23" {
+		t.Errorf("expected 1.23, got %s", r)
+	}
+	if r := MustParseDecimal("1.23456").RoundStep(step, RoundUp); r.String() != "1.235" {
+		t.Errorf("expected 1.235, got %s", r)
+	}
+	if s := MustParseDecimal("0.1").StringFixed(8); s != "0.10000000" {
+		t.Errorf("expected 0.10000000, got %s", s)
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var v struct {
+		Price  Decimal `json:"price"`
+		Amount Decimal `json:"amount"`
+	}
+	if err := json.Unmarshal([]byte(`{"price":"0.00012345","amount":0.1}`), &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Price.String() != "0.00012345" || v.Amount.String() != "0.1" {
+		t.Errorf("unexpected values: %s, %s", v.Price, v.Amount)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(out) != `{"price":"0.00012345","amount":"0.1"}` {
+		t.Errorf("unexpected json: %s", out)
+	}
+}
+
+func TestDecimalFromFloat(t *testing.T) {
+	if d := NewDecimalFromFloat(0.1); d.String() != "0.1" {
+		t.Errorf("expected 0.1, got %s", d)
+	}
+	if f := MustParseDecimal("0.1").Float64(); f != 0.1 {
+		t.Errorf("expected 0.1, got %v", f)
+	}
+}
@@ -0,0 +1,54 @@
+package binance
+
+// Environment groups base URLs of Binance hosts the service talks to.
+//
+// Use Production or Testnet, or define custom environment, e.g. pointing to
+// regional endpoint or local mock server:
+//
+//	env := binance.Environment{
+//		Name:      "local",
+//		RESTURL:   "http://127.0.0.1:8080",
+//		StreamURL: "ws://127.0.0.1:8080",
+//	}
+type Environment struct {
+	// Name identifies the environment.
+	Name string
+	// RESTURL is base URL of REST API, e.g. "https://api.binance.com".
+	RESTURL string
+	// StreamURL is base URL of websocket market and user data streams, without
+	// "/ws" suffix, e.g. "wss://stream.binance.com:9443".
+	StreamURL string
+}
+
+var (
+	// Production is the live Binance spot exchange.
+	Production = Environment{
+		Name:      "production",
+		RESTURL:   "https://api.binance.com",
+		StreamURL: "wss://stream.binance.com:9443",
+	}
+	// Testnet is the Binance spot test network. It requires separate API keys.
+	//
+	// Testnet serves only api/v3 endpoints. Calls working against it are
+	// TickerPrice, TickerBook, AvgPrice, NewOrder, NewOrderTest, QueryOrder,
+	// CancelOrder, OpenOrders, AllOrders, Account, MyTrades and market streams.
+	// Other calls use api/v1 or wapi endpoints and fail with 404, including
+	// OrderBook, Klines, ExchangeInfo, user data stream and withdrawals.
+	Testnet = Environment{
+		Name:      "testnet",
+		RESTURL:   "https://testnet.binance.vision",
+		StreamURL: "wss://testnet.binance.vision",
+	}
+)
+
+// WithEnvironment points REST calls and streams of the service to hosts of the
+// environment. Non-empty RESTURL overrides url passed to NewAPIService, empty
+// URLs fall back to Production.
+func WithEnvironment(env Environment) ServiceOption {
+	return func(as *apiService) {
+		if env.RESTURL != "" {
+			as.URL = env.RESTURL
+		}
+		as.StreamURL = env.StreamURL
+	}
+}
@@ -0,0 +1,61 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestEnvironmentStreamURL(t *testing.T) {
+	paths := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.URL.Path
+		c, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		c.WriteMessage(websocket.TextMessage, []byte(`{"e":"depthUpdate","E":1499404630606,"s":"BNBETH","U":7913452,"u":7913455,`+
+			`"b":[["0.10376590","59.15767010",[]]],"a":[["0.10376586","159.15767010",[]]]}`))
+		c.ReadMessage()
+	}))
+	defer ts.Close()
+
+	env := Environment{
+		Name:      "local",
+		RESTURL:   ts.URL,
+		StreamURL: "ws" + strings.TrimPrefix(ts.URL, "http"),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	as := NewAPIService("https://api.binance.com", "", nil, nil, ctx, WithEnvironment(env)).(*apiService)
+	if as.URL != ts.URL {
+		t.Errorf("expected REST URL %s, got %s", ts.URL, as.URL)
+	}
+
+	dech, _, err := as.DepthWebsocket(ctx, DepthWebsocketRequest{Symbol: "BNBETH"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path := <-paths; path != "/ws/bnbeth@depth" {
+		t.Errorf("unexpected stream path: %s", path)
+	}
+	if de := <-dech; de.Symbol != "BNBETH" || len(de.Bids) != 1 || len(de.Asks) != 1 || de.FirstUpdateID != 7913452 {
+		t.Errorf("unexpected event: %+v", de)
+	}
+}
+
+func TestEnvironmentDefaults(t *testing.T) {
+	as := NewAPIService("", "", nil, nil, nil).(*apiService)
+	if as.URL != Production.RESTURL || as.StreamURL != Production.StreamURL {
+		t.Errorf("expected production URLs, got %s and %s", as.URL, as.StreamURL)
+	}
+	as = NewAPIService("", "", nil, nil, nil, WithEnvironment(Testnet)).(*apiService)
+	if as.URL != Testnet.RESTURL || as.StreamURL != Testnet.StreamURL {
+		t.Errorf("expected testnet URLs, got %s and %s", as.URL, as.StreamURL)
+	}
+}
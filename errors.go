@@ -0,0 +1,63 @@
+package binance
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Categories of errors returned by the API. Use errors.Is to check whether
+// returned error falls into the category:
+//
+//	if errors.Is(err, binance.ErrInsufficientBalance) {
+//		...
+//	}
+var (
+	// ErrRateLimited is returned when request rate limits were exceeded (HTTP 429,
+	// -1003, -1015) or the request was held back by RateLimiter.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrIPBanned is returned when IP was banned for violating rate limits (HTTP 418).
+	ErrIPBanned = errors.New("ip banned")
+	// ErrInsufficientBalance is returned when order was rejected because of insufficient balance.
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	// ErrUnknownOrder is returned when order doesn't exist or isn't known (-2011, -2013).
+	ErrUnknownOrder = errors.New("unknown order")
+	// ErrFilterFailure is returned when request failed symbol filters (-1013).
+	ErrFilterFailure = errors.New("filter failure")
+	// ErrTimestampOutOfWindow is returned when timestamp was outside of recvWindow (-1021).
+	ErrTimestampOutOfWindow = errors.New("timestamp outside of recv window")
+	// ErrServiceUnavailable is returned when Binance failed internally (HTTP 5xx, -1001, -1016).
+	ErrServiceUnavailable = errors.New("service unavailable")
+	// ErrUnknownExecutionStatus is returned when request may or may not have been
+	// executed (HTTP 5xx, -1006, -1007). Query the state before repeating the request.
+	ErrUnknownExecutionStatus = errors.New("unknown execution status")
+)
+
+// Is reports whether error falls into the target category.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests || e.Code == -1003 || e.Code == -1015
+	case ErrIPBanned:
+		return e.StatusCode == http.StatusTeapot
+	case ErrInsufficientBalance:
+		return e.Code == -2010 && strings.Contains(strings.ToLower(e.Message), "insufficient balance")
+	case ErrUnknownOrder:
+		return e.Code == -2011 || e.Code == -2013
+	case ErrFilterFailure:
+		return e.Code == -1013 || strings.HasPrefix(e.Message, "Filter failure")
+	case ErrTimestampOutOfWindow:
+		return e.Code == -1021
+	case ErrServiceUnavailable:
+		return e.StatusCode >= 500 || e.Code == -1001 || e.Code == -1016
+	case ErrUnknownExecutionStatus:
+		return e.StatusCode >= 500 || e.Code == -1006 || e.Code == -1007
+	}
+	return false
+}
+
+// Is reports whether error falls into the target category.
+func (e RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
@@ -0,0 +1,65 @@
+package binance
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestErrorCategories(t *testing.T) {
+	as := NewAPIService("", "", nil, nil, nil).(*apiService)
+	cases := []struct {
+		status   int
+		body     string
+		category error
+	}{
+		{429, `{"code":-1003,"msg":"Too many requests."}`, ErrRateLimited},
+		{418, `{"code":-1003,"msg":"Way too many requests; IP banned."}`, ErrIPBanned},
+		{400, `{"code":-2010,"msg":"Account has insufficient balance for requested action."}`, ErrInsufficientBalance},
+		{400, `{"code":-2013,"msg":"Order does not exist."}`, ErrUnknownOrder},
+		{400, `{"code":-2011,"msg":"Unknown order sent."}`, ErrUnknownOrder},
+		{400, `{"code":-1013,"msg":"Filter failure: LOT_SIZE"}`, ErrFilterFailure},
+		{400, `{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`, ErrTimestampOutOfWindow},
+		{503, `{"code":-1001,"msg":"Internal error; unable to process your request."}`, ErrServiceUnavailable},
+		{504, `{"code":-1007,"msg":"Timeout waiting for response from backend server."}`, ErrUnknownExecutionStatus},
+	}
+	for _, c := range cases {
+		err := as.handleError("api/v3/order", &http.Response{StatusCode: c.status}, []byte(c.body))
+		if !errors.Is(err, c.category) {
+			t.Errorf("%s: expected category %q", c.body, c.category)
+		}
+	}
+
+	err := as.handleError("api/v3/order", &http.Response{StatusCode: 400}, []byte(`{"code":-1013,"msg":"Filter failure: LOT_SIZE"}`))
+	if errors.Is(err, ErrInsufficientBalance) || errors.Is(err, ErrRateLimited) {
+		t.Errorf("error matches unrelated category")
+	}
+	if !errors.Is(RateLimitError{Limit: LimitRequestWeight, RetryAfter: time.Second}, ErrRateLimited) {
+		t.Errorf("RateLimitError should match ErrRateLimited")
+	}
+}
+
+func TestErrorHandlerNonJSON(t *testing.T) {
+	as := NewAPIService("", "", nil, nil, nil).(*apiService)
+	header := http.Header{"Content-Type": []string{"text/html"}}
+	body := []byte("<html>\n<body>\n<h1>502 Bad Gateway</h1>\n</body>\n</html>")
+	err := as.handleError("api/v3/order", &http.Response{StatusCode: 502, Header: header}, body)
+
+	var bErr *Error
+	if !errors.As(err, &bErr) {
+		t.Fatalf("invalid type of error returned: %T", err)
+	}
+	if bErr.StatusCode != 502 || bErr.Header.Get("Content-Type") != "text/html" {
+		t.Errorf("response details not attached: %#v", bErr)
+	}
+	if string(bErr.Body) != string(body) {
+		t.Errorf("raw body not attached")
+	}
+	if bErr.Error() != "HTTP 502: <html> <body> <h1>502 Bad Gateway</h1> </body> </html>" {
+		t.Errorf("unexpected message: %s", bErr.Error())
+	}
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Errorf("gateway error should match ErrServiceUnavailable")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/rootpd/binance"
+	"github.com/rootpd/binance/keystore"
 )
 
 func main() {
@@ -18,17 +19,32 @@ func main() {
 	logger = level.NewFilter(logger, level.AllowAll())
 	logger = log.With(logger, "time", log.DefaultTimestampUTC, "caller", log.DefaultCaller)
 
-	hmacSigner := &binance.HmacSigner{
-		Key: []byte(os.Getenv("BINANCE_SECRET")),
+	var apiKey string
+	var signer binance.Signer
+	if path := os.Getenv("BINANCE_KEYSTORE"); path != "" {
+		ks, err := keystore.Open(path, []byte(os.Getenv("BINANCE_KEYSTORE_PASSPHRASE")))
+		if err != nil {
+			panic(err)
+		}
+		apiKey, signer, err = ks.Signer(os.Getenv("BINANCE_ACCOUNT"))
+		if err != nil {
+			panic(err)
+		}
+	} else {
+		apiKey = os.Getenv("BINANCE_APIKEY")
+		signer = &binance.HmacSigner{
+			Key: []byte(os.Getenv("BINANCE_SECRET")),
+		}
 	}
 	ctx, cancelCtx := context.WithCancel(context.Background())
 	// use second return value for cancelling request
 	binanceService := binance.NewAPIService(
 		"https://www.binance.com",
-		os.Getenv("BINANCE_APIKEY"),
-		hmacSigner,
+		apiKey,
+		signer,
 		logger,
 		ctx,
+		binance.WithHTTPClient(binance.NewHTTPClient(binance.DefaultHTTPClientConfig())),
 	)
 	b := binance.NewBinance(binanceService)
 
@@ -59,8 +75,11 @@ func main() {
 	fmt.Println("waiting for signal")
 	<-done
 	fmt.Println("exit")
-	return
+}
 
+// restExamples shows usage of REST calls. It's not called by default as it
+// places real orders.
+func restExamples(b binance.Binance) {
 	kl, err := b.Klines(binance.KlinesRequest{
 		Symbol:   "BNBETH",
 		Interval: binance.Hour,
@@ -72,8 +91,8 @@ func main() {
 
 	newOrder, err := b.NewOrder(binance.NewOrderRequest{
 		Symbol:      "BNBETH",
-		Quantity:    1,
-		Price:       999,
+		Quantity:    binance.NewDecimalFromInt(1),
+		Price:       binance.MustParseDecimal("999"),
 		Side:        binance.SideSell,
 		TimeInForce: binance.GTC,
 		Type:        binance.TypeLimit,
@@ -0,0 +1,319 @@
+// Package keystore stores named Binance API credentials encrypted at rest.
+//
+// Keystore is a JSON file. Encryption key is derived from passphrase (or
+// content of key file) by scrypt, each credential is encrypted by AES-256-GCM
+// separately, so names of credentials can be listed without unlocking them.
+//
+//	ks, err := keystore.Open("binance.keystore", passphrase)
+//	if err != nil {
+//		panic(err)
+//	}
+//	apiKey, signer, err := ks.Signer("main")
+//	if err != nil {
+//		panic(err)
+//	}
+//	binanceService := binance.NewAPIService(url, apiKey, signer, logger, ctx)
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/rootpd/binance"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	// ErrNotFound is returned when credential with requested name doesn't exist.
+	ErrNotFound = errors.New("credential not found")
+	// ErrWrongPassphrase is returned when keystore can't be unlocked by provided passphrase.
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+)
+
+// Key types of credentials.
+const (
+	KeyTypeHMAC    = "hmac"
+	KeyTypeRSA     = "rsa"
+	KeyTypeEd25519 = "ed25519"
+)
+
+// Default scrypt parameters of new keystores.
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+	keyLen  = 32
+)
+
+// verifier is encrypted to check the passphrase when keystore is opened.
+var verifier = []byte("binance keystore")
+
+// Credential holds API key and secret of single account.
+type Credential struct {
+	// APIKey is sent in X-MBX-APIKEY header.
+	APIKey string `json:"apiKey"`
+	// KeyType is one of KeyTypeHMAC, KeyTypeRSA or KeyTypeEd25519.
+	KeyType string `json:"keyType"`
+	// Secret is HMAC secret, or PEM encoded unencrypted PKCS#8 private key.
+	Secret []byte `json:"secret"`
+}
+
+// Signer returns signer using the secret of credential.
+func (c Credential) Signer() (binance.Signer, error) {
+	switch c.KeyType {
+	case KeyTypeHMAC, "":
+		return &binance.HmacSigner{Key: c.Secret}, nil
+	case KeyTypeRSA:
+		return binance.NewRSASigner(c.Secret, nil)
+	case KeyTypeEd25519:
+		return binance.NewEd25519Signer(c.Secret, nil)
+	}
+	return nil, errors.New(fmt.Sprintf("unknown key type: %s", c.KeyType))
+}
+
+type kdfParams struct {
+	Name string `json:"name"`
+	Salt []byte `json:"salt"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+}
+
+type sealed struct {
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type keystoreFile struct {
+	Version     int               `json:"version"`
+	KDF         kdfParams         `json:"kdf"`
+	Verifier    sealed            `json:"verifier"`
+	Credentials map[string]sealed `json:"credentials"`
+}
+
+// Keystore is unlocked keystore file. It's safe for concurrent use.
+type Keystore struct {
+	path string
+	aead cipher.AEAD
+
+	mu   sync.Mutex
+	file keystoreFile
+}
+
+// Create creates new empty keystore protected by passphrase. Existing file is
+// not overwritten.
+func Create(path string, passphrase []byte) (*Keystore, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, errors.New(fmt.Sprintf("keystore already exists: %s", path))
+	}
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, errors.Wrap(err, "unable to generate salt")
+	}
+	ks := &Keystore{
+		path: path,
+		file: keystoreFile{
+			Version:     1,
+			KDF:         kdfParams{Name: "scrypt", Salt: salt, N: scryptN, R: scryptR, P: scryptP},
+			Credentials: make(map[string]sealed),
+		},
+	}
+	if err := ks.unlock(passphrase); err != nil {
+		return nil, err
+	}
+	v, err := ks.seal("", verifier)
+	if err != nil {
+		return nil, err
+	}
+	ks.file.Verifier = v
+	if err := ks.save(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Open opens existing keystore and unlocks it by passphrase.
+func Open(path string, passphrase []byte) (*Keystore, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read keystore")
+	}
+	ks := &Keystore{path: path}
+	if err := json.Unmarshal(raw, &ks.file); err != nil {
+		return nil, errors.Wrap(err, "keystore unmarshal failed")
+	}
+	if ks.file.Version != 1 || ks.file.KDF.Name != "scrypt" {
+		return nil, errors.New(fmt.Sprintf("unsupported keystore version %d with %s", ks.file.Version, ks.file.KDF.Name))
+	}
+	if ks.file.Credentials == nil {
+		ks.file.Credentials = make(map[string]sealed)
+	}
+	if err := ks.unlock(passphrase); err != nil {
+		return nil, err
+	}
+	v, err := ks.open("", ks.file.Verifier)
+	if err != nil || !bytes.Equal(v, verifier) {
+		return nil, ErrWrongPassphrase
+	}
+	return ks, nil
+}
+
+// ReadKeyFile reads passphrase from key file, e.g. mounted secret. Trailing
+// newline is removed.
+func ReadKeyFile(path string) ([]byte, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read key file")
+	}
+	return bytes.TrimRight(raw, "\r\n"), nil
+}
+
+// Names returns sorted names of stored credentials.
+func (ks *Keystore) Names() []string {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	var names []string
+	for name := range ks.file.Credentials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get decrypts credential stored under the name.
+func (ks *Keystore) Get(name string) (*Credential, error) {
+	ks.mu.Lock()
+	s, ok := ks.file.Credentials[name]
+	ks.mu.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	raw, err := ks.open(name, s)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("unable to decrypt credential %s", name))
+	}
+	c := &Credential{}
+	if err := json.Unmarshal(raw, c); err != nil {
+		return nil, errors.Wrap(err, "credential unmarshal failed")
+	}
+	return c, nil
+}
+
+// Put encrypts and stores credential under the name, replacing existing one.
+// Keystore file is written immediately.
+func (ks *Keystore) Put(name string, c Credential) error {
+	if name == "" {
+		return errors.New("credential name required")
+	}
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return errors.Wrap(err, "credential marshal failed")
+	}
+	s, err := ks.seal(name, raw)
+	if err != nil {
+		return err
+	}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.file.Credentials[name] = s
+	return ks.save()
+}
+
+// Delete removes credential stored under the name.
+func (ks *Keystore) Delete(name string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if _, ok := ks.file.Credentials[name]; !ok {
+		return ErrNotFound
+	}
+	delete(ks.file.Credentials, name)
+	return ks.save()
+}
+
+// Signer returns API key and signer of credential stored under the name,
+// ready to be passed to binance.NewAPIService.
+func (ks *Keystore) Signer(name string) (string, binance.Signer, error) {
+	c, err := ks.Get(name)
+	if err != nil {
+		return "", nil, err
+	}
+	signer, err := c.Signer()
+	if err != nil {
+		return "", nil, err
+	}
+	return c.APIKey, signer, nil
+}
+
+func (ks *Keystore) unlock(passphrase []byte) error {
+	if len(passphrase) == 0 {
+		return errors.New("passphrase required")
+	}
+	kdf := ks.file.KDF
+	key, err := scrypt.Key(passphrase, kdf.Salt, kdf.N, kdf.R, kdf.P, keyLen)
+	if err != nil {
+		return errors.Wrap(err, "unable to derive key")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return errors.Wrap(err, "unable to create cipher")
+	}
+	ks.aead, err = cipher.NewGCM(block)
+	if err != nil {
+		return errors.Wrap(err, "unable to create cipher")
+	}
+	return nil
+}
+
+// seal encrypts data, name is authenticated so entries can't be swapped.
+func (ks *Keystore) seal(name string, data []byte) (sealed, error) {
+	nonce := make([]byte, ks.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return sealed{}, errors.Wrap(err, "unable to generate nonce")
+	}
+	return sealed{
+		Nonce:      nonce,
+		Ciphertext: ks.aead.Seal(nil, nonce, data, []byte(name)),
+	}, nil
+}
+
+func (ks *Keystore) open(name string, s sealed) ([]byte, error) {
+	if len(s.Nonce) != ks.aead.NonceSize() {
+		return nil, errors.New("invalid nonce")
+	}
+	return ks.aead.Open(nil, s.Nonce, s.Ciphertext, []byte(name))
+}
+
+// save writes keystore to temporary file and renames it, so the keystore isn't
+// corrupted by interrupted write.
+func (ks *Keystore) save() error {
+	raw, err := json.MarshalIndent(ks.file, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "keystore marshal failed")
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(ks.path), filepath.Base(ks.path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "unable to write keystore")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "unable to write keystore")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "unable to write keystore")
+	}
+	if err := os.Rename(tmp.Name(), ks.path); err != nil {
+		return errors.Wrap(err, "unable to write keystore")
+	}
+	return nil
+}
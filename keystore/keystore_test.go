@@ -0,0 +1,87 @@
+package keystore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeystore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "binance.keystore")
+	ks, err := Create(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	secret := "NhqPtmdSJYdKjVHjA7PZj4Mge3R5YNiP1e3UZjInClVN65XAbvqqM6A7H5fATj0j"
+	if err := ks.Put("main", Credential{APIKey: "key", KeyType: KeyTypeHMAC, Secret: []byte(secret)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ks.Put("second", Credential{APIKey: "key2", Secret: []byte("other")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(raw), secret) || strings.Contains(string(raw), `"key"`) {
+		t.Error("keystore contains plain credential")
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("unexpected keystore permissions: %v", fi.Mode())
+	}
+
+	if _, err := Open(path, []byte("wrong")); err != ErrWrongPassphrase {
+		t.Errorf("expected ErrWrongPassphrase, got %v", err)
+	}
+	ks, err = Open(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := ks.Names(); len(names) != 2 || names[0] != "main" || names[1] != "second" {
+		t.Errorf("unexpected names: %v", names)
+	}
+	apiKey, signer, err := ks.Signer("main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payload := []byte("symbol=LTCBTC&side=BUY&type=LIMIT&timeInForce=GTC&quantity=1&price=0.1&recvWindow=5000&timestamp=1499827319559")
+	if apiKey != "key" || signer.Sign(payload) != "c8db56825ae71d6d79447849e617115f4a920fa2acdcab2b053c4b2838bd6b71" {
+		t.Errorf("unexpected credential: %s", apiKey)
+	}
+
+	if err := ks.Delete("second"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ks.Get("second"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := Create(path, []byte("passphrase")); err == nil {
+		t.Error("expected error when overwriting keystore")
+	}
+}
+
+func TestKeystoreSwappedEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "binance.keystore")
+	ks, err := Create(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ks.Put("a", Credential{APIKey: "a"})
+	ks.Put("b", Credential{APIKey: "b"})
+	ks.file.Credentials["a"], ks.file.Credentials["b"] = ks.file.Credentials["b"], ks.file.Credentials["a"]
+	if _, err := ks.Get("a"); err == nil {
+		t.Error("expected error for swapped entry")
+	}
+}
+
+func TestReadKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	ioutil.WriteFile(path, []byte("secret passphrase\n"), 0600)
+	key, err := ReadKeyFile(path)
+	if err != nil || string(key) != "secret passphrase" {
+		t.Errorf("unexpected key %q: %v", key, err)
+	}
+}
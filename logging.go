@@ -0,0 +1,165 @@
+package binance
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// LogCategory groups log records of the service by their content.
+type LogCategory string
+
+var (
+	// LogRequests covers query strings of sent requests, retries and clock resyncs.
+	LogRequests = LogCategory("requests")
+	// LogResponses covers bodies of error responses and other responses logged for debugging.
+	LogResponses = LogCategory("responses")
+	// LogStreams covers websocket connections and messages which couldn't be parsed.
+	LogStreams = LogCategory("streams")
+)
+
+// LogVerbosity is the most detailed level logged in the category.
+type LogVerbosity int
+
+var (
+	// LogOff disables logging of the category.
+	LogOff = LogVerbosity(0)
+	// LogErrors logs only errors.
+	LogErrors = LogVerbosity(1)
+	// LogInfo logs errors and info records.
+	LogInfo = LogVerbosity(2)
+	// LogDebug logs all records, subject to filtering by the logger itself.
+	LogDebug = LogVerbosity(3)
+)
+
+// DefaultRedactedKeys lists parameters and fields redacted by DefaultLogPolicy.
+var DefaultRedactedKeys = []string{
+	"signature", "apiKey", "secret", "listenKey", "address", "addressTag", "name", "txId",
+}
+
+// LogPolicy controls what the service logs and which values are redacted.
+type LogPolicy struct {
+	// Verbosity limits logging per category. Categories not listed are logged at LogDebug.
+	Verbosity map[LogCategory]LogVerbosity
+	// RedactedKeys lists parameters, JSON fields and struct fields whose values
+	// are replaced in logged values. Keys are matched case-insensitively.
+	RedactedKeys []string
+
+	// literals are values redacted wherever they appear, e.g. API key.
+	literals []string
+}
+
+// DefaultLogPolicy returns policy logging all categories with DefaultRedactedKeys redacted.
+func DefaultLogPolicy() LogPolicy {
+	return LogPolicy{
+		RedactedKeys: DefaultRedactedKeys,
+	}
+}
+
+// WithLogPolicy sets logging policy of the service. API key of the service is
+// always redacted. DefaultLogPolicy is used if not set.
+func WithLogPolicy(policy LogPolicy) ServiceOption {
+	return func(as *apiService) {
+		as.logPolicy = &policy
+	}
+}
+
+// log returns logger of the category at given level, NopLogger if the level
+// isn't allowed by the policy.
+func (as *apiService) log(category LogCategory, verbosity LogVerbosity) log.Logger {
+	if allowed, ok := as.logPolicy.Verbosity[category]; ok && verbosity > allowed {
+		return log.NewNopLogger()
+	}
+	logger := as.redactor
+	switch verbosity {
+	case LogErrors:
+		return level.Error(logger)
+	case LogInfo:
+		return level.Info(logger)
+	}
+	return level.Debug(logger)
+}
+
+// RedactingLogger returns logger which redacts values according to the policy
+// before passing them to provided logger.
+func RedactingLogger(logger log.Logger, policy LogPolicy) log.Logger {
+	r := &redactingLogger{
+		next: logger,
+		keys: make(map[string]bool),
+	}
+	var alternatives []string
+	for _, key := range policy.RedactedKeys {
+		r.keys[strings.ToLower(key)] = true
+		alternatives = append(alternatives, regexp.QuoteMeta(key))
+	}
+	if len(alternatives) > 0 {
+		keys := strings.Join(alternatives, "|")
+		r.patterns = []*regexp.Regexp{
+			// query strings, key=value
+			regexp.MustCompile(`(?i)(^|[?&\s])(` + keys + `)=([^&\s"]*)`),
+			// JSON, "key":"value" or "key":value
+			regexp.MustCompile(`(?i)("(?:` + keys + `)"\s*:\s*)("(?:[^"\\]|\\.)*"|[^,}\s]*)`),
+			// structs formatted by %+v, Key:value
+			regexp.MustCompile(`(?i)([{\s&]|^)(` + keys + `):([^\s}]*)`),
+		}
+	}
+	for _, literal := range policy.literals {
+		if literal != "" {
+			r.literals = append(r.literals, literal)
+		}
+	}
+	return r
+}
+
+const redacted = "[REDACTED]"
+
+type redactingLogger struct {
+	next     log.Logger
+	keys     map[string]bool
+	patterns []*regexp.Regexp
+	literals []string
+}
+
+func (r *redactingLogger) Log(keyvals ...interface{}) error {
+	out := make([]interface{}, len(keyvals))
+	for i, v := range keyvals {
+		if i%2 == 1 {
+			if key, ok := keyvals[i-1].(string); ok && r.keys[strings.ToLower(key)] {
+				out[i] = redacted
+				continue
+			}
+		}
+		out[i] = r.value(v)
+	}
+	return r.next.Log(out...)
+}
+
+func (r *redactingLogger) value(v interface{}) interface{} {
+	var s string
+	switch val := v.(type) {
+	case string:
+		s = val
+	case []byte:
+		s = string(val)
+	case error:
+		s = val.Error()
+	default:
+		// keep level values, numbers and other values unchanged
+		return v
+	}
+	return r.redact(s)
+}
+
+func (r *redactingLogger) redact(s string) string {
+	for _, literal := range r.literals {
+		s = strings.Replace(s, literal, redacted, -1)
+	}
+	if len(r.patterns) == 3 {
+		s = r.patterns[0].ReplaceAllString(s, "${1}${2}="+redacted)
+		s = r.patterns[1].ReplaceAllString(s, `${1}"`+redacted+`"`)
+		s = r.patterns[2].ReplaceAllString(s, "${1}${2}:"+redacted)
+	}
+	return s
+}
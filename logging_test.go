@@ -0,0 +1,63 @@
+package binance
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+func TestRedactingLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := RedactingLogger(log.NewLogfmtLogger(&buf), DefaultLogPolicy())
+	logger.Log("queryString", "asset=ETH&address=0x123&amount=1&timestamp=1&signature=abc")
+	logger.Log("body", []byte(`{"listenKey":"pqia91ma19a5s61cv6a81va65sdf19v8a65a1a5s61cv6a81va65sdf19v8a65a1"}`))
+	logger.Log("request", fmt.Sprintf("%+v", WithdrawRequest{Asset: "ETH", Address: "0x123", Name: "cold"}))
+	logger.Log("signature", "abc", "symbol", "BNBETH")
+
+	out := buf.String()
+	for _, secret := range []string{"0x123", "abc", "pqia91ma", "cold"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log contains %q: %s", secret, out)
+		}
+	}
+	for _, kept := range []string{"asset=ETH", "amount=1", "symbol=BNBETH", "Asset:ETH"} {
+		if !strings.Contains(out, kept) {
+			t.Errorf("log doesn't contain %q: %s", kept, out)
+		}
+	}
+}
+
+func TestServiceLogPolicy(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":-1100,"msg":"Illegal characters found in parameter 'listenKey'."}`))
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	logger := level.NewFilter(log.NewLogfmtLogger(&buf), level.AllowAll())
+	policy := DefaultLogPolicy()
+	policy.Verbosity = map[LogCategory]LogVerbosity{LogResponses: LogOff}
+	as := NewAPIService(ts.URL, "secret-api-key", &HmacSigner{Key: []byte("key")}, logger, nil, WithLogPolicy(policy))
+
+	as.KeepAliveUserDataStream(context.Background(), &Stream{ListenKey: "listen-key-value"})
+	as.Account(context.Background(), AccountRequest{})
+
+	out := buf.String()
+	if !strings.Contains(out, "level=debug") || !strings.Contains(out, "signature=[REDACTED]") {
+		t.Errorf("expected redacted debug log of requests: %s", out)
+	}
+	if strings.Contains(out, "listen-key-value") || strings.Contains(out, "secret-api-key") {
+		t.Errorf("log contains secrets: %s", out)
+	}
+	if strings.Contains(out, "errorResponse") {
+		t.Errorf("log contains disabled category: %s", out)
+	}
+}
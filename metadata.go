@@ -0,0 +1,88 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ResponseMetadata contains details about single HTTP exchange with the API.
+type ResponseMetadata struct {
+	// Method is HTTP method of the request.
+	Method string
+	// Endpoint is called endpoint, e.g. "api/v3/order".
+	Endpoint string
+	// Attempt is number of the attempt if the request was retried, starting from 1.
+	Attempt int
+	// StatusCode is HTTP status code, 0 if no response was received.
+	StatusCode int
+	// Header contains response headers.
+	Header http.Header
+	// Duration is time between sending the request and receiving response headers.
+	Duration time.Duration
+	// Weight is request weight of the call.
+	Weight int
+	// UsedWeight is request weight used in current minute as reported by X-MBX-USED-WEIGHT-1M.
+	UsedWeight int
+	// OrderCount10s is number of orders in current 10 seconds as reported by X-MBX-ORDER-COUNT-10S.
+	OrderCount10s int
+	// OrderCount1d is number of orders in current day as reported by X-MBX-ORDER-COUNT-1D.
+	OrderCount1d int
+	// ServerTime is time reported by server in Date header.
+	ServerTime time.Time
+	// Err is error of the request if no response was received.
+	Err error
+}
+
+// ResponseHook receives metadata of each HTTP exchange.
+type ResponseHook func(md *ResponseMetadata)
+
+// WithResponseHook sets hook called after each HTTP exchange of the service.
+//
+// Hook is called synchronously and should return quickly.
+func WithResponseHook(hook ResponseHook) ServiceOption {
+	return func(as *apiService) {
+		as.responseHooks = append(as.responseHooks, hook)
+	}
+}
+
+type responseHookKey struct{}
+
+// ContextWithResponseHook returns context which makes the call report its
+// metadata to provided hook.
+//
+//	var md *binance.ResponseMetadata
+//	ctx := binance.ContextWithResponseHook(ctx, func(m *binance.ResponseMetadata) {
+//		md = m
+//	})
+//	ob, err := b.OrderBookContext(ctx, obr)
+func ContextWithResponseHook(ctx context.Context, hook ResponseHook) context.Context {
+	hooks, _ := ctx.Value(responseHookKey{}).([]ResponseHook)
+	hooks = append(hooks[:len(hooks):len(hooks)], hook)
+	return context.WithValue(ctx, responseHookKey{}, hooks)
+}
+
+// reportResponse passes metadata of the exchange to all registered hooks.
+func (as *apiService) reportResponse(ctx context.Context, md *ResponseMetadata, res *http.Response) {
+	ctxHooks, _ := ctx.Value(responseHookKey{}).([]ResponseHook)
+	if len(as.responseHooks) == 0 && len(ctxHooks) == 0 {
+		return
+	}
+	if res != nil {
+		md.StatusCode = res.StatusCode
+		md.Header = res.Header
+		md.UsedWeight, _ = strconv.Atoi(res.Header.Get("X-MBX-USED-WEIGHT-1M"))
+		md.OrderCount10s, _ = strconv.Atoi(res.Header.Get("X-MBX-ORDER-COUNT-10S"))
+		md.OrderCount1d, _ = strconv.Atoi(res.Header.Get("X-MBX-ORDER-COUNT-1D"))
+		if date := res.Header.Get("Date"); date != "" {
+			md.ServerTime, _ = http.ParseTime(date)
+		}
+	}
+	for _, hook := range as.responseHooks {
+		hook(md)
+	}
+	for _, hook := range ctxHooks {
+		hook(md)
+	}
+}
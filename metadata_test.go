@@ -0,0 +1,50 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseHooks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-MBX-USED-WEIGHT-1M", "42")
+		w.Header().Set("Date", "Mon, 01 Jan 2018 10:00:00 GMT")
+		w.Write([]byte(`{"lastUpdateId":1,"bids":[],"asks":[]}`))
+	}))
+	defer ts.Close()
+
+	var serviceCalls int
+	s := NewAPIService(ts.URL, "", nil, nil, nil, WithResponseHook(func(md *ResponseMetadata) {
+		serviceCalls++
+	}))
+
+	var md *ResponseMetadata
+	ctx := ContextWithResponseHook(context.Background(), func(m *ResponseMetadata) {
+		md = m
+	})
+	if _, err := s.OrderBook(ctx, OrderBookRequest{Symbol: "BNBETH", Limit: 500}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if serviceCalls != 1 {
+		t.Errorf("service hook not called")
+	}
+	if md == nil {
+		t.Fatalf("context hook not called")
+	}
+	if md.Endpoint != "api/v1/depth" || md.StatusCode != 200 || md.Weight != 25 || md.UsedWeight != 42 {
+		t.Errorf("invalid metadata: %#v", md)
+	}
+	if md.ServerTime.Unix() != 1514800800 {
+		t.Errorf("server time not parsed: %s", md.ServerTime)
+	}
+
+	md = nil
+	if err := s.Ping(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if md != nil || serviceCalls != 2 {
+		t.Errorf("context hook must apply only to its call")
+	}
+}
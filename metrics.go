@@ -0,0 +1,167 @@
+package binance
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+)
+
+// Metrics groups instruments used to monitor REST and websocket traffic.
+//
+// Instruments are go-kit metrics, so they can be backed by Prometheus, expvar
+// or any other supported backend. Nil instruments are replaced by no-op ones.
+// Label names used with each instrument are listed in its comment.
+type Metrics struct {
+	// Requests counts HTTP exchanges; labels "endpoint", "method", "status".
+	Requests metrics.Counter
+	// RequestErrors counts failed requests; labels "endpoint", "code" (Binance
+	// error code, HTTP status for non-JSON errors or "network").
+	RequestErrors metrics.Counter
+	// RequestLatency observes duration of HTTP exchanges in seconds; label "endpoint".
+	RequestLatency metrics.Histogram
+	// UsedWeight is request weight used in current minute as reported by the server.
+	UsedWeight metrics.Gauge
+	// OrderCount is number of orders in current 10 seconds as reported by the server.
+	OrderCount metrics.Gauge
+
+	// StreamConnections is number of opened websocket connections; label "stream".
+	StreamConnections metrics.Gauge
+	// StreamMessages counts received websocket messages; label "stream".
+	StreamMessages metrics.Counter
+	// StreamParseFailures counts websocket messages which couldn't be parsed; label "stream".
+	StreamParseFailures metrics.Counter
+	// StreamReconnects counts repeated connections to the same stream; label "stream".
+	StreamReconnects metrics.Counter
+}
+
+// NopMetrics returns Metrics with all instruments discarding the values.
+func NopMetrics() *Metrics {
+	m := &Metrics{}
+	m.setDefaults()
+	return m
+}
+
+func (m *Metrics) setDefaults() {
+	if m.Requests == nil {
+		m.Requests = discard.NewCounter()
+	}
+	if m.RequestErrors == nil {
+		m.RequestErrors = discard.NewCounter()
+	}
+	if m.RequestLatency == nil {
+		m.RequestLatency = discard.NewHistogram()
+	}
+	if m.UsedWeight == nil {
+		m.UsedWeight = discard.NewGauge()
+	}
+	if m.OrderCount == nil {
+		m.OrderCount = discard.NewGauge()
+	}
+	if m.StreamConnections == nil {
+		m.StreamConnections = discard.NewGauge()
+	}
+	if m.StreamMessages == nil {
+		m.StreamMessages = discard.NewCounter()
+	}
+	if m.StreamParseFailures == nil {
+		m.StreamParseFailures = discard.NewCounter()
+	}
+	if m.StreamReconnects == nil {
+		m.StreamReconnects = discard.NewCounter()
+	}
+}
+
+// WithMetrics instruments the service with provided metrics.
+func WithMetrics(m *Metrics) ServiceOption {
+	return func(as *apiService) {
+		as.Metrics = m
+	}
+}
+
+// observeResponse records metrics of single HTTP exchange.
+func (m *Metrics) observeResponse(md *ResponseMetadata) {
+	m.Requests.With("endpoint", md.Endpoint, "method", md.Method, "status", strconv.Itoa(md.StatusCode)).Add(1)
+	m.RequestLatency.With("endpoint", md.Endpoint).Observe(md.Duration.Seconds())
+	if md.Err != nil {
+		m.RequestErrors.With("endpoint", md.Endpoint, "code", "network").Add(1)
+		return
+	}
+	if used := md.Header.Get("X-MBX-USED-WEIGHT-1M"); used != "" {
+		if n, err := strconv.Atoi(used); err == nil {
+			m.UsedWeight.Set(float64(n))
+		}
+	}
+	if count := md.Header.Get("X-MBX-ORDER-COUNT-10S"); count != "" {
+		if n, err := strconv.Atoi(count); err == nil {
+			m.OrderCount.Set(float64(n))
+		}
+	}
+}
+
+// observeError records error returned by the API for the endpoint, e.g.
+// "api/v3/order".
+func (m *Metrics) observeError(endpoint string, err *Error) {
+	code := strconv.Itoa(err.Code)
+	if err.Code == 0 {
+		code = "http_" + strconv.Itoa(err.StatusCode)
+	}
+	m.RequestErrors.With("endpoint", endpoint, "code", code).Add(1)
+}
+
+// streamTracker tracks opened streams to recognize reconnects and assigns
+// slots to combined streams.
+type streamTracker struct {
+	mu     sync.Mutex
+	opened map[string]bool
+	slots  []bool
+}
+
+// open records opened stream and reports whether it was opened before.
+func (st *streamTracker) open(stream string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.opened == nil {
+		st.opened = make(map[string]bool)
+	}
+	reopened := st.opened[stream]
+	st.opened[stream] = true
+	return reopened
+}
+
+// acquire returns the lowest slot not used by open combined stream.
+func (st *streamTracker) acquire() int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for i, used := range st.slots {
+		if !used {
+			st.slots[i] = true
+			return i
+		}
+	}
+	st.slots = append(st.slots, true)
+	return len(st.slots) - 1
+}
+
+// release frees the slot of closed combined stream.
+func (st *streamTracker) release(slot int) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.slots[slot] = false
+}
+
+// MetricsMiddleware counts calls of the Service and observes their duration in
+// seconds. Both instruments get labels "method" and "error" ("true"/"false").
+func MetricsMiddleware(calls metrics.Counter, latency metrics.Histogram) Middleware {
+	return InterceptorMiddleware(func(ctx context.Context, call Call, next Invoker) (interface{}, error) {
+		start := time.Now()
+		res, err := next(ctx, call.Request)
+		failed := strconv.FormatBool(err != nil)
+		calls.With("method", call.Method, "error", failed).Add(1)
+		latency.With("method", call.Method, "error", failed).Observe(time.Since(start).Seconds())
+		return res, err
+	})
+}
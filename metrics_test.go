@@ -0,0 +1,79 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+)
+
+// testCounter records sums of values by labels.
+type testCounter struct {
+	mu     *sync.Mutex
+	values map[string]float64
+	labels []string
+}
+
+func newTestCounter() *testCounter {
+	return &testCounter{
+		mu:     &sync.Mutex{},
+		values: make(map[string]float64),
+	}
+}
+
+func (c *testCounter) With(labelValues ...string) metrics.Counter {
+	return &testCounter{
+		mu:     c.mu,
+		values: c.values,
+		labels: append(append([]string{}, c.labels...), labelValues...),
+	}
+}
+
+func (c *testCounter) Add(delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[strings.Join(c.labels, ",")] += delta
+}
+
+func (c *testCounter) value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[strings.Join(labelValues, ",")]
+}
+
+func TestRequestMetrics(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/proxy/api/v3/order" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-1013,"msg":"Filter failure: LOT_SIZE"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	requests := newTestCounter()
+	errs := newTestCounter()
+	// errors are labeled by endpoint, not by path of the URL behind proxy
+	s := NewAPIService(ts.URL+"/proxy", "", &HmacSigner{}, nil, nil, WithMetrics(&Metrics{
+		Requests:      requests,
+		RequestErrors: errs,
+	}))
+	s.Ping(context.Background())
+	s.Ping(context.Background())
+	s.NewOrder(context.Background(), NewOrderRequest{Symbol: "BNBETH"})
+
+	if v := requests.value("endpoint", "api/v1/ping", "method", "GET", "status", "200"); v != 2 {
+		t.Errorf("expected 2 ping requests, got %f", v)
+	}
+	if v := requests.value("endpoint", "api/v3/order", "method", "POST", "status", "400"); v != 1 {
+		t.Errorf("expected 1 order request, got %f", v)
+	}
+	if v := errs.value("endpoint", "api/v3/order", "code", "-1013"); v != 1 {
+		t.Errorf("expected error code to be counted, got %f", v)
+	}
+}
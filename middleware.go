@@ -0,0 +1,378 @@
+package binance
+
+import (
+	"context"
+	"time"
+)
+
+// Middleware decorates Service with additional behavior.
+type Middleware func(Service) Service
+
+// Chain composes middlewares into single one. The first middleware is the
+// outermost, i.e. it's the first to see the call.
+func Chain(outer Middleware, others ...Middleware) Middleware {
+	return func(next Service) Service {
+		for i := len(others) - 1; i >= 0; i-- {
+			next = others[i](next)
+		}
+		return outer(next)
+	}
+}
+
+// Call describes single call of Service method.
+type Call struct {
+	// Method is name of the called Service method, e.g. "NewOrder".
+	Method string
+	// Request is request struct passed to the method (e.g. NewOrderRequest),
+	// *Stream for user data stream calls and nil for methods without params.
+	Request interface{}
+}
+
+// Invoker executes the call with provided request. Request must be of the
+// same type as Call.Request.
+//
+// Result is the first return value of the method. Websocket methods return
+// event channel as the result.
+type Invoker func(ctx context.Context, req interface{}) (interface{}, error)
+
+// Interceptor intercepts every call of the Service. It can inspect or modify
+// the request, call next any number of times, or respond without calling it.
+type Interceptor func(ctx context.Context, call Call, next Invoker) (interface{}, error)
+
+// InterceptorMiddleware creates Middleware which passes every call of the
+// decorated Service through the interceptor.
+func InterceptorMiddleware(interceptor Interceptor) Middleware {
+	return func(next Service) Service {
+		return &interceptedService{
+			next:        next,
+			interceptor: interceptor,
+		}
+	}
+}
+
+type interceptedService struct {
+	next        Service
+	interceptor Interceptor
+}
+
+func (s *interceptedService) intercept(ctx context.Context, method string, req interface{},
+	next Invoker) (interface{}, error) {
+	return s.interceptor(ctx, Call{Method: method, Request: req}, next)
+}
+
+func (s *interceptedService) Ping(ctx context.Context) error {
+	_, err := s.intercept(ctx, "Ping", nil,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, s.next.Ping(ctx)
+		})
+	return err
+}
+
+func (s *interceptedService) Time(ctx context.Context) (time.Time, error) {
+	res, err := s.intercept(ctx, "Time", nil,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.Time(ctx)
+		})
+	r, _ := res.(time.Time)
+	return r, err
+}
+
+func (s *interceptedService) OrderBook(ctx context.Context, obr OrderBookRequest) (*OrderBook, error) {
+	res, err := s.intercept(ctx, "OrderBook", obr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.OrderBook(ctx, req.(OrderBookRequest))
+		})
+	r, _ := res.(*OrderBook)
+	return r, err
+}
+
+func (s *interceptedService) AggTrades(ctx context.Context, atr AggTradesRequest) ([]*AggTrade, error) {
+	res, err := s.intercept(ctx, "AggTrades", atr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.AggTrades(ctx, req.(AggTradesRequest))
+		})
+	r, _ := res.([]*AggTrade)
+	return r, err
+}
+
+func (s *interceptedService) Trades(ctx context.Context, tr TradesRequest) ([]*MarketTrade, error) {
+	res, err := s.intercept(ctx, "Trades", tr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.Trades(ctx, req.(TradesRequest))
+		})
+	r, _ := res.([]*MarketTrade)
+	return r, err
+}
+
+func (s *interceptedService) HistoricalTrades(ctx context.Context, htr HistoricalTradesRequest) ([]*MarketTrade, error) {
+	res, err := s.intercept(ctx, "HistoricalTrades", htr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.HistoricalTrades(ctx, req.(HistoricalTradesRequest))
+		})
+	r, _ := res.([]*MarketTrade)
+	return r, err
+}
+
+func (s *interceptedService) Klines(ctx context.Context, kr KlinesRequest) ([]*Kline, error) {
+	res, err := s.intercept(ctx, "Klines", kr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.Klines(ctx, req.(KlinesRequest))
+		})
+	r, _ := res.([]*Kline)
+	return r, err
+}
+
+func (s *interceptedService) Ticker24(ctx context.Context, tr TickerRequest) (*Ticker24, error) {
+	res, err := s.intercept(ctx, "Ticker24", tr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.Ticker24(ctx, req.(TickerRequest))
+		})
+	r, _ := res.(*Ticker24)
+	return r, err
+}
+
+func (s *interceptedService) TickerAll24(ctx context.Context) ([]*Ticker24, error) {
+	res, err := s.intercept(ctx, "TickerAll24", nil,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.TickerAll24(ctx)
+		})
+	r, _ := res.([]*Ticker24)
+	return r, err
+}
+
+func (s *interceptedService) TickerPrice(ctx context.Context, tr TickerRequest) (*PriceTicker, error) {
+	res, err := s.intercept(ctx, "TickerPrice", tr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.TickerPrice(ctx, req.(TickerRequest))
+		})
+	r, _ := res.(*PriceTicker)
+	return r, err
+}
+
+func (s *interceptedService) TickerAllPrices(ctx context.Context) ([]*PriceTicker, error) {
+	res, err := s.intercept(ctx, "TickerAllPrices", nil,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.TickerAllPrices(ctx)
+		})
+	r, _ := res.([]*PriceTicker)
+	return r, err
+}
+
+func (s *interceptedService) TickerBook(ctx context.Context, tr TickerRequest) (*BookTicker, error) {
+	res, err := s.intercept(ctx, "TickerBook", tr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.TickerBook(ctx, req.(TickerRequest))
+		})
+	r, _ := res.(*BookTicker)
+	return r, err
+}
+
+func (s *interceptedService) TickerAllBooks(ctx context.Context) ([]*BookTicker, error) {
+	res, err := s.intercept(ctx, "TickerAllBooks", nil,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.TickerAllBooks(ctx)
+		})
+	r, _ := res.([]*BookTicker)
+	return r, err
+}
+
+func (s *interceptedService) AvgPrice(ctx context.Context, apr AvgPriceRequest) (*AvgPrice, error) {
+	res, err := s.intercept(ctx, "AvgPrice", apr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.AvgPrice(ctx, req.(AvgPriceRequest))
+		})
+	r, _ := res.(*AvgPrice)
+	return r, err
+}
+
+func (s *interceptedService) ExchangeInfo(ctx context.Context) (*ExchangeInfo, error) {
+	res, err := s.intercept(ctx, "ExchangeInfo", nil,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.ExchangeInfo(ctx)
+		})
+	r, _ := res.(*ExchangeInfo)
+	return r, err
+}
+
+func (s *interceptedService) NewOrder(ctx context.Context, or NewOrderRequest) (*ProcessedOrder, error) {
+	res, err := s.intercept(ctx, "NewOrder", or,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.NewOrder(ctx, req.(NewOrderRequest))
+		})
+	r, _ := res.(*ProcessedOrder)
+	return r, err
+}
+
+func (s *interceptedService) NewOrderTest(ctx context.Context, or NewOrderRequest) error {
+	_, err := s.intercept(ctx, "NewOrderTest", or,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, s.next.NewOrderTest(ctx, req.(NewOrderRequest))
+		})
+	return err
+}
+
+func (s *interceptedService) QueryOrder(ctx context.Context, qor QueryOrderRequest) (*ExecutedOrder, error) {
+	res, err := s.intercept(ctx, "QueryOrder", qor,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.QueryOrder(ctx, req.(QueryOrderRequest))
+		})
+	r, _ := res.(*ExecutedOrder)
+	return r, err
+}
+
+func (s *interceptedService) CancelOrder(ctx context.Context, cor CancelOrderRequest) (*CanceledOrder, error) {
+	res, err := s.intercept(ctx, "CancelOrder", cor,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.CancelOrder(ctx, req.(CancelOrderRequest))
+		})
+	r, _ := res.(*CanceledOrder)
+	return r, err
+}
+
+func (s *interceptedService) OpenOrders(ctx context.Context, oor OpenOrdersRequest) ([]*ExecutedOrder, error) {
+	res, err := s.intercept(ctx, "OpenOrders", oor,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.OpenOrders(ctx, req.(OpenOrdersRequest))
+		})
+	r, _ := res.([]*ExecutedOrder)
+	return r, err
+}
+
+func (s *interceptedService) AllOrders(ctx context.Context, aor AllOrdersRequest) ([]*ExecutedOrder, error) {
+	res, err := s.intercept(ctx, "AllOrders", aor,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.AllOrders(ctx, req.(AllOrdersRequest))
+		})
+	r, _ := res.([]*ExecutedOrder)
+	return r, err
+}
+
+func (s *interceptedService) Account(ctx context.Context, ar AccountRequest) (*Account, error) {
+	res, err := s.intercept(ctx, "Account", ar,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.Account(ctx, req.(AccountRequest))
+		})
+	r, _ := res.(*Account)
+	return r, err
+}
+
+func (s *interceptedService) MyTrades(ctx context.Context, mtr MyTradesRequest) ([]*Trade, error) {
+	res, err := s.intercept(ctx, "MyTrades", mtr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.MyTrades(ctx, req.(MyTradesRequest))
+		})
+	r, _ := res.([]*Trade)
+	return r, err
+}
+
+func (s *interceptedService) Withdraw(ctx context.Context, wr WithdrawRequest) (*WithdrawResult, error) {
+	res, err := s.intercept(ctx, "Withdraw", wr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.Withdraw(ctx, req.(WithdrawRequest))
+		})
+	r, _ := res.(*WithdrawResult)
+	return r, err
+}
+
+func (s *interceptedService) DepositHistory(ctx context.Context, hr HistoryRequest) ([]*Deposit, error) {
+	res, err := s.intercept(ctx, "DepositHistory", hr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.DepositHistory(ctx, req.(HistoryRequest))
+		})
+	r, _ := res.([]*Deposit)
+	return r, err
+}
+
+func (s *interceptedService) WithdrawHistory(ctx context.Context, hr HistoryRequest) ([]*Withdrawal, error) {
+	res, err := s.intercept(ctx, "WithdrawHistory", hr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.WithdrawHistory(ctx, req.(HistoryRequest))
+		})
+	r, _ := res.([]*Withdrawal)
+	return r, err
+}
+
+func (s *interceptedService) StartUserDataStream(ctx context.Context) (*Stream, error) {
+	res, err := s.intercept(ctx, "StartUserDataStream", nil,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.next.StartUserDataStream(ctx)
+		})
+	r, _ := res.(*Stream)
+	return r, err
+}
+
+func (s *interceptedService) KeepAliveUserDataStream(ctx context.Context, stream *Stream) error {
+	_, err := s.intercept(ctx, "KeepAliveUserDataStream", stream,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, s.next.KeepAliveUserDataStream(ctx, req.(*Stream))
+		})
+	return err
+}
+
+func (s *interceptedService) CloseUserDataStream(ctx context.Context, stream *Stream) error {
+	_, err := s.intercept(ctx, "CloseUserDataStream", stream,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, s.next.CloseUserDataStream(ctx, req.(*Stream))
+		})
+	return err
+}
+
+func (s *interceptedService) DepthWebsocket(ctx context.Context, dwr DepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error) {
+	var done chan struct{}
+	res, err := s.intercept(ctx, "DepthWebsocket", dwr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			ech, d, err := s.next.DepthWebsocket(ctx, req.(DepthWebsocketRequest))
+			done = d
+			return ech, err
+		})
+	ech, _ := res.(chan *DepthEvent)
+	return ech, done, err
+}
+
+func (s *interceptedService) CombinedDepthWebsocket(ctx context.Context, cdwr CombinedDepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error) {
+	var done chan struct{}
+	res, err := s.intercept(ctx, "CombinedDepthWebsocket", cdwr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			ech, d, err := s.next.CombinedDepthWebsocket(ctx, req.(CombinedDepthWebsocketRequest))
+			done = d
+			return ech, err
+		})
+	ech, _ := res.(chan *DepthEvent)
+	return ech, done, err
+}
+
+func (s *interceptedService) KlineWebsocket(ctx context.Context, kwr KlineWebsocketRequest) (chan *KlineEvent, chan struct{}, error) {
+	var done chan struct{}
+	res, err := s.intercept(ctx, "KlineWebsocket", kwr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			ech, d, err := s.next.KlineWebsocket(ctx, req.(KlineWebsocketRequest))
+			done = d
+			return ech, err
+		})
+	ech, _ := res.(chan *KlineEvent)
+	return ech, done, err
+}
+
+func (s *interceptedService) TradeWebsocket(ctx context.Context, twr TradeWebsocketRequest) (chan *AggTradeEvent, chan struct{}, error) {
+	var done chan struct{}
+	res, err := s.intercept(ctx, "TradeWebsocket", twr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			ech, d, err := s.next.TradeWebsocket(ctx, req.(TradeWebsocketRequest))
+			done = d
+			return ech, err
+		})
+	ech, _ := res.(chan *AggTradeEvent)
+	return ech, done, err
+}
+
+func (s *interceptedService) UserDataWebsocket(ctx context.Context, udwr UserDataWebsocketRequest) (chan *AccountEvent, chan struct{}, error) {
+	var done chan struct{}
+	res, err := s.intercept(ctx, "UserDataWebsocket", udwr,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			ech, d, err := s.next.UserDataWebsocket(ctx, req.(UserDataWebsocketRequest))
+			done = d
+			return ech, err
+		})
+	ech, _ := res.(chan *AccountEvent)
+	return ech, done, err
+}
@@ -0,0 +1,406 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// callEndpoints maps Service methods to HTTP method and endpoint they call.
+var callEndpoints = map[string][2]string{
+	"Ping":                    {"GET", "api/v1/ping"},
+	"Time":                    {"GET", "api/v1/time"},
+	"OrderBook":               {"GET", "api/v1/depth"},
+	"AggTrades":               {"GET", "api/v1/aggTrades"},
+	"Trades":                  {"GET", "api/v1/trades"},
+	"HistoricalTrades":        {"GET", "api/v1/historicalTrades"},
+	"Klines":                  {"GET", "api/v1/klines"},
+	"Ticker24":                {"GET", "api/v1/ticker/24hr"},
+	"TickerAll24":             {"GET", "api/v1/ticker/24hr"},
+	"TickerPrice":             {"GET", "api/v3/ticker/price"},
+	"TickerAllPrices":         {"GET", "api/v1/ticker/allPrices"},
+	"TickerBook":              {"GET", "api/v3/ticker/bookTicker"},
+	"TickerAllBooks":          {"GET", "api/v1/ticker/allBookTickers"},
+	"AvgPrice":                {"GET", "api/v3/avgPrice"},
+	"ExchangeInfo":            {"GET", "api/v1/exchangeInfo"},
+	"NewOrder":                {"POST", "api/v3/order"},
+	"NewOrderTest":            {"POST", "api/v3/order/test"},
+	"QueryOrder":              {"GET", "api/v3/order"},
+	"CancelOrder":             {"DELETE", "api/v3/order"},
+	"OpenOrders":              {"GET", "api/v3/openOrders"},
+	"AllOrders":               {"GET", "api/v3/allOrders"},
+	"Account":                 {"GET", "api/v3/account"},
+	"MyTrades":                {"GET", "api/v3/myTrades"},
+	"Withdraw":                {"POST", "wapi/v1/withdraw.html"},
+	"DepositHistory":          {"POST", "wapi/v1/getDepositHistory.html"},
+	"WithdrawHistory":         {"POST", "wapi/v1/getWithdrawHistory.html"},
+	"StartUserDataStream":     {"POST", "api/v1/userDataStream"},
+	"KeepAliveUserDataStream": {"PUT", "api/v1/userDataStream"},
+	"CloseUserDataStream":     {"DELETE", "api/v1/userDataStream"},
+}
+
+// callParams returns params of the call named as in the API requests. Params
+// are used to compute request weight and to identify the call for caching.
+func callParams(call Call) map[string]string {
+	params := make(map[string]string)
+	switch req := call.Request.(type) {
+	case OrderBookRequest:
+		params["symbol"] = req.Symbol
+		params["limit"] = strconv.Itoa(req.Limit)
+	case AggTradesRequest:
+		params["symbol"] = req.Symbol
+		params["fromId"] = strconv.FormatInt(req.FromID, 10)
+		params["startTime"] = strconv.FormatInt(req.StartTime, 10)
+		params["endTime"] = strconv.FormatInt(req.EndTime, 10)
+		params["limit"] = strconv.Itoa(req.Limit)
+	case TradesRequest:
+		params["symbol"] = req.Symbol
+		params["limit"] = strconv.Itoa(req.Limit)
+	case HistoricalTradesRequest:
+		params["symbol"] = req.Symbol
+		params["limit"] = strconv.Itoa(req.Limit)
+		params["fromId"] = strconv.FormatInt(req.FromID, 10)
+	case KlinesRequest:
+		params["symbol"] = req.Symbol
+		params["interval"] = string(req.Interval)
+		params["limit"] = strconv.Itoa(req.Limit)
+		params["startTime"] = strconv.FormatInt(req.StartTime, 10)
+		params["endTime"] = strconv.FormatInt(req.EndTime, 10)
+	case TickerRequest:
+		params["symbol"] = req.Symbol
+	case AvgPriceRequest:
+		params["symbol"] = req.Symbol
+	case NewOrderRequest:
+		params["symbol"] = req.Symbol
+		params["side"] = string(req.Side)
+		params["type"] = string(req.Type)
+		params["timeInForce"] = string(req.TimeInForce)
+		params["quantity"] = req.Quantity.String()
+		params["price"] = req.Price.String()
+		params["newClientOrderId"] = req.NewClientOrderID
+		params["stopPrice"] = req.StopPrice.String()
+		params["icebergQty"] = req.IcebergQty.String()
+		params["timestamp"] = strconv.FormatInt(unixMillis(req.Timestamp), 10)
+	case QueryOrderRequest:
+		params["symbol"] = req.Symbol
+		params["orderId"] = strconv.FormatInt(req.OrderID, 10)
+		params["origClientOrderId"] = req.OrigClientOrderID
+		params["recvWindow"] = strconv.FormatInt(recvWindow(req.RecvWindow), 10)
+		params["timestamp"] = strconv.FormatInt(unixMillis(req.Timestamp), 10)
+	case CancelOrderRequest:
+		params["symbol"] = req.Symbol
+		params["orderId"] = strconv.FormatInt(req.OrderID, 10)
+		params["origClientOrderId"] = req.OrigClientOrderID
+		params["newClientOrderId"] = req.NewClientOrderID
+		params["recvWindow"] = strconv.FormatInt(recvWindow(req.RecvWindow), 10)
+		params["timestamp"] = strconv.FormatInt(unixMillis(req.Timestamp), 10)
+	case OpenOrdersRequest:
+		params["symbol"] = req.Symbol
+		params["recvWindow"] = strconv.FormatInt(recvWindow(req.RecvWindow), 10)
+		params["timestamp"] = strconv.FormatInt(unixMillis(req.Timestamp), 10)
+	case AllOrdersRequest:
+		params["symbol"] = req.Symbol
+		params["orderId"] = strconv.FormatInt(req.OrderID, 10)
+		params["limit"] = strconv.Itoa(req.Limit)
+		params["recvWindow"] = strconv.FormatInt(recvWindow(req.RecvWindow), 10)
+		params["timestamp"] = strconv.FormatInt(unixMillis(req.Timestamp), 10)
+	case AccountRequest:
+		params["recvWindow"] = strconv.FormatInt(recvWindow(req.RecvWindow), 10)
+		params["timestamp"] = strconv.FormatInt(unixMillis(req.Timestamp), 10)
+	case MyTradesRequest:
+		params["symbol"] = req.Symbol
+		params["limit"] = strconv.Itoa(req.Limit)
+		params["fromId"] = strconv.FormatInt(req.FromID, 10)
+		params["recvWindow"] = strconv.FormatInt(recvWindow(req.RecvWindow), 10)
+		params["timestamp"] = strconv.FormatInt(unixMillis(req.Timestamp), 10)
+	case WithdrawRequest:
+		params["asset"] = req.Asset
+		params["address"] = req.Address
+		params["amount"] = req.Amount.String()
+		params["name"] = req.Name
+		params["recvWindow"] = strconv.FormatInt(recvWindow(req.RecvWindow), 10)
+		params["timestamp"] = strconv.FormatInt(unixMillis(req.Timestamp), 10)
+	case HistoryRequest:
+		params["asset"] = req.Asset
+		if req.Status != nil {
+			params["status"] = strconv.Itoa(*req.Status)
+		}
+		params["startTime"] = strconv.FormatInt(unixMillis(req.StartTime), 10)
+		params["endTime"] = strconv.FormatInt(unixMillis(req.EndTime), 10)
+		params["recvWindow"] = strconv.FormatInt(recvWindow(req.RecvWindow), 10)
+		params["timestamp"] = strconv.FormatInt(unixMillis(req.Timestamp), 10)
+	}
+	return params
+}
+
+// callKey identifies the call by its method and params, so the equal requests
+// share the key regardless of pointers held by their values.
+func callKey(call Call) string {
+	params := callParams(call)
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(call.Method)
+	for _, k := range keys {
+		b.WriteString("&" + k + "=" + params[k])
+	}
+	return b.String()
+}
+
+// LoggingMiddleware logs every call with its duration and error. Logged requests
+// are redacted according to DefaultLogPolicy.
+func LoggingMiddleware(logger log.Logger) Middleware {
+	logger = RedactingLogger(logger, DefaultLogPolicy())
+	return InterceptorMiddleware(func(ctx context.Context, call Call, next Invoker) (interface{}, error) {
+		start := time.Now()
+		res, err := next(ctx, call.Request)
+		if err != nil {
+			level.Error(logger).Log("call", call.Method, "request", fmt.Sprintf("%+v", call.Request),
+				"took", time.Since(start), "err", err)
+			return res, err
+		}
+		level.Debug(logger).Log("call", call.Method, "request", fmt.Sprintf("%+v", call.Request),
+			"took", time.Since(start))
+		return res, err
+	})
+}
+
+// RetryMiddleware retries calls failed on transient errors according to policy.
+//
+// Only calls considered retryable by the policy are retried, see DefaultRetryable.
+// Retried are only errors with unknown outcome, i.e. network failures and
+// ErrUnknownExecutionStatus including any 5xx response. Other API errors and
+// 429 responses aren't retried. DefaultRetryable doesn't allow new orders, so
+// they aren't retried on 5xx unless the policy says otherwise.
+// Use WithRetryPolicy instead if the Service is created by NewAPIService, so
+// the signed requests are stamped again and new orders are resolved safely.
+func RetryMiddleware(policy RetryPolicy) Middleware {
+	return InterceptorMiddleware(func(ctx context.Context, call Call, next Invoker) (interface{}, error) {
+		endpoint, ok := callEndpoints[call.Method]
+		if !ok || !policy.retryable(endpoint[0], endpoint[1], callParams(call)) {
+			return next(ctx, call.Request)
+		}
+		for attempt := 1; ; attempt++ {
+			res, err := next(ctx, call.Request)
+			if err == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil || !unknownOutcome(err) {
+				return res, err
+			}
+			if werr := policy.wait(ctx, attempt); werr != nil {
+				return res, err
+			}
+		}
+	})
+}
+
+// RateLimitMiddleware throttles REST calls by the limiter. Usage of the limits
+// is accounted by request weight of called endpoints only, as response headers
+// aren't available at the Service level.
+func RateLimitMiddleware(limiter *RateLimiter) Middleware {
+	return InterceptorMiddleware(func(ctx context.Context, call Call, next Invoker) (interface{}, error) {
+		if endpoint, ok := callEndpoints[call.Method]; ok {
+			if err := limiter.Wait(ctx, endpoint[0], endpoint[1], callParams(call)); err != nil {
+				return nil, err
+			}
+		}
+		return next(ctx, call.Request)
+	})
+}
+
+// DryRunMiddleware prevents any changes on the account. New orders are
+// validated by NewOrderTest and reported as processed, cancels and withdrawals
+// are only logged.
+func DryRunMiddleware(logger log.Logger) Middleware {
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+	return func(next Service) Service {
+		return &dryRunService{
+			Service: next,
+			logger:  logger,
+		}
+	}
+}
+
+type dryRunService struct {
+	Service
+	logger log.Logger
+}
+
+func (s *dryRunService) NewOrder(ctx context.Context, or NewOrderRequest) (*ProcessedOrder, error) {
+	if err := s.Service.NewOrderTest(ctx, or); err != nil {
+		return nil, err
+	}
+	level.Info(s.logger).Log("dryRun", "NewOrder", "symbol", or.Symbol, "side", or.Side,
+		"quantity", or.Quantity, "price", or.Price)
+	return &ProcessedOrder{
+		Symbol:        or.Symbol,
+		ClientOrderID: or.NewClientOrderID,
+		TransactTime:  time.Now(),
+	}, nil
+}
+
+func (s *dryRunService) CancelOrder(ctx context.Context, cor CancelOrderRequest) (*CanceledOrder, error) {
+	level.Info(s.logger).Log("dryRun", "CancelOrder", "symbol", cor.Symbol, "orderId", cor.OrderID)
+	return &CanceledOrder{
+		Symbol:            cor.Symbol,
+		OrigClientOrderID: cor.OrigClientOrderID,
+		OrderID:           cor.OrderID,
+		ClientOrderID:     cor.NewClientOrderID,
+	}, nil
+}
+
+func (s *dryRunService) Withdraw(ctx context.Context, wr WithdrawRequest) (*WithdrawResult, error) {
+	level.Info(s.logger).Log("dryRun", "Withdraw", "asset", wr.Asset, "amount", wr.Amount)
+	return &WithdrawResult{
+		Success: true,
+		Msg:     "dry run",
+	}, nil
+}
+
+// RiskError is returned when order is rejected by risk check.
+type RiskError struct {
+	Reason string
+}
+
+// Error returns formatted error message.
+func (e RiskError) Error() string {
+	return fmt.Sprintf("risk check failed: %s", e.Reason)
+}
+
+// OrderCheck checks the order before it's placed. Returned error rejects the order.
+type OrderCheck func(ctx context.Context, or NewOrderRequest) error
+
+// MaxNotional rejects orders with notional value (price * quantity) above max.
+// Orders without price (e.g. market orders) are not checked.
+func MaxNotional(max Decimal) OrderCheck {
+	return func(ctx context.Context, or NewOrderRequest) error {
+		if notional := or.Price.Mul(or.Quantity); notional.Cmp(max) > 0 {
+			return RiskError{Reason: fmt.Sprintf("notional %s exceeds %s", notional, max)}
+		}
+		return nil
+	}
+}
+
+// MaxQuantity rejects orders with quantity above max.
+func MaxQuantity(max Decimal) OrderCheck {
+	return func(ctx context.Context, or NewOrderRequest) error {
+		if or.Quantity.Cmp(max) > 0 {
+			return RiskError{Reason: fmt.Sprintf("quantity %s exceeds %s", or.Quantity, max)}
+		}
+		return nil
+	}
+}
+
+// AllowedSymbols rejects orders for symbols not listed.
+func AllowedSymbols(symbols ...string) OrderCheck {
+	allowed := make(map[string]bool)
+	for _, s := range symbols {
+		allowed[s] = true
+	}
+	return func(ctx context.Context, or NewOrderRequest) error {
+		if !allowed[or.Symbol] {
+			return RiskError{Reason: fmt.Sprintf("symbol %s not allowed", or.Symbol)}
+		}
+		return nil
+	}
+}
+
+// RiskMiddleware runs checks before each new order, including test orders.
+func RiskMiddleware(checks ...OrderCheck) Middleware {
+	return InterceptorMiddleware(func(ctx context.Context, call Call, next Invoker) (interface{}, error) {
+		if or, ok := call.Request.(NewOrderRequest); ok {
+			for _, check := range checks {
+				if err := check(ctx, or); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return next(ctx, call.Request)
+	})
+}
+
+// cachedCalls lists calls of public market data which can be cached.
+var cachedCalls = map[string]bool{
+	"OrderBook":        true,
+	"AggTrades":        true,
+	"Trades":           true,
+	"HistoricalTrades": true,
+	"Klines":           true,
+	"Ticker24":         true,
+	"TickerAll24":      true,
+	"TickerPrice":      true,
+	"TickerAllPrices":  true,
+	"TickerBook":       true,
+	"TickerAllBooks":   true,
+	"AvgPrice":         true,
+	"ExchangeInfo":     true,
+}
+
+// CachingMiddleware caches successful results of public market data calls for
+// ttl. Cached results are shared by all callers and must not be modified.
+func CachingMiddleware(ttl time.Duration) Middleware {
+	c := &callCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+	return InterceptorMiddleware(func(ctx context.Context, call Call, next Invoker) (interface{}, error) {
+		if !cachedCalls[call.Method] {
+			return next(ctx, call.Request)
+		}
+		key := callKey(call)
+		if res, ok := c.get(key); ok {
+			return res, nil
+		}
+		res, err := next(ctx, call.Request)
+		if err == nil {
+			c.set(key, res)
+		}
+		return res, err
+	})
+}
+
+type cacheEntry struct {
+	result  interface{}
+	expires time.Time
+}
+
+type callCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func (c *callCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.result, true
+}
+
+func (c *callCache) set(key string, result interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry{
+		result:  result,
+		expires: now.Add(c.ttl),
+	}
+}
@@ -0,0 +1,161 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stubService implements only methods used by tests, others panic.
+type stubService struct {
+	Service
+	calls      []string
+	orderBooks int
+	orderErr   error
+}
+
+func (s *stubService) OrderBook(ctx context.Context, obr OrderBookRequest) (*OrderBook, error) {
+	s.calls = append(s.calls, "OrderBook")
+	s.orderBooks++
+	return &OrderBook{LastUpdateID: s.orderBooks}, nil
+}
+
+func (s *stubService) NewOrder(ctx context.Context, or NewOrderRequest) (*ProcessedOrder, error) {
+	s.calls = append(s.calls, "NewOrder")
+	if s.orderErr != nil {
+		return nil, s.orderErr
+	}
+	return &ProcessedOrder{Symbol: or.Symbol, OrderID: 1}, nil
+}
+
+func (s *stubService) NewOrderTest(ctx context.Context, or NewOrderRequest) error {
+	s.calls = append(s.calls, "NewOrderTest")
+	return nil
+}
+
+func recordingMiddleware(name string, log *[]string) Middleware {
+	return InterceptorMiddleware(func(ctx context.Context, call Call, next Invoker) (interface{}, error) {
+		*log = append(*log, name+":"+call.Method)
+		return next(ctx, call.Request)
+	})
+}
+
+func TestChainOrder(t *testing.T) {
+	var log []string
+	stub := &stubService{}
+	s := Chain(recordingMiddleware("outer", &log), recordingMiddleware("inner", &log))(stub)
+	if _, err := s.OrderBook(context.Background(), OrderBookRequest{Symbol: "BNBETH"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(log) != 2 || log[0] != "outer:OrderBook" || log[1] != "inner:OrderBook" {
+		t.Errorf("invalid order of middlewares: %v", log)
+	}
+}
+
+func TestInterceptorModifiesRequest(t *testing.T) {
+	stub := &stubService{}
+	s := InterceptorMiddleware(func(ctx context.Context, call Call, next Invoker) (interface{}, error) {
+		or := call.Request.(NewOrderRequest)
+		or.Symbol = "ETHBTC"
+		return next(ctx, or)
+	})(stub)
+	po, err := s.NewOrder(context.Background(), NewOrderRequest{Symbol: "BNBETH"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if po.Symbol != "ETHBTC" {
+		t.Errorf("modified request not passed: %s", po.Symbol)
+	}
+}
+
+func TestRiskMiddleware(t *testing.T) {
+	stub := &stubService{}
+	s := RiskMiddleware(AllowedSymbols("BNBETH"), MaxNotional(NewDecimalFromInt(100)))(stub)
+
+	_, err := s.NewOrder(context.Background(), NewOrderRequest{Symbol: "BNBETH", Quantity: NewDecimalFromInt(10), Price: NewDecimalFromInt(20)})
+	if _, ok := err.(RiskError); !ok {
+		t.Errorf("expected RiskError, got %v", err)
+	}
+	_, err = s.NewOrder(context.Background(), NewOrderRequest{Symbol: "ETHBTC", Quantity: NewDecimalFromInt(1), Price: NewDecimalFromInt(1)})
+	if _, ok := err.(RiskError); !ok {
+		t.Errorf("expected RiskError, got %v", err)
+	}
+	if _, err := s.NewOrder(context.Background(), NewOrderRequest{Symbol: "BNBETH", Quantity: NewDecimalFromInt(1), Price: NewDecimalFromInt(20)}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if len(stub.calls) != 1 {
+		t.Errorf("rejected orders must not be sent: %v", stub.calls)
+	}
+}
+
+func TestDryRunMiddleware(t *testing.T) {
+	stub := &stubService{}
+	s := DryRunMiddleware(nil)(stub)
+	if _, err := s.NewOrder(context.Background(), NewOrderRequest{Symbol: "BNBETH"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(stub.calls) != 1 || stub.calls[0] != "NewOrderTest" {
+		t.Errorf("dry run should place test order only: %v", stub.calls)
+	}
+}
+
+func TestCachingMiddleware(t *testing.T) {
+	stub := &stubService{}
+	s := CachingMiddleware(time.Minute)(stub)
+	ob1, _ := s.OrderBook(context.Background(), OrderBookRequest{Symbol: "BNBETH"})
+	ob2, _ := s.OrderBook(context.Background(), OrderBookRequest{Symbol: "BNBETH"})
+	ob3, _ := s.OrderBook(context.Background(), OrderBookRequest{Symbol: "ETHBTC"})
+	if ob1 != ob2 || stub.orderBooks != 2 || ob3.LastUpdateID != 2 {
+		t.Errorf("order book not cached by request")
+	}
+}
+
+func TestCallKey(t *testing.T) {
+	or1 := NewOrderRequest{Symbol: "BNBETH", Quantity: MustParseDecimal("1.5"), Price: MustParseDecimal("0.01")}
+	or2 := NewOrderRequest{Symbol: "BNBETH", Quantity: MustParseDecimal("1.5"), Price: MustParseDecimal("0.01")}
+	if k1, k2 := callKey(Call{"NewOrder", or1}), callKey(Call{"NewOrder", or2}); k1 != k2 {
+		t.Errorf("equal requests with different keys: %s, %s", k1, k2)
+	}
+	or2.Price = MustParseDecimal("0.02")
+	if k1, k2 := callKey(Call{"NewOrder", or1}), callKey(Call{"NewOrder", or2}); k1 == k2 {
+		t.Errorf("different requests with equal key: %s", k1)
+	}
+	kr := KlinesRequest{Symbol: "BNBETH", Interval: Hour, StartTime: 1}
+	if callKey(Call{"Klines", kr}) == callKey(Call{"Klines", KlinesRequest{Symbol: "BNBETH", Interval: Hour}}) {
+		t.Errorf("klines key ignores start time")
+	}
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	stub := &stubService{orderErr: errors.New("connection reset")}
+	s := RetryMiddleware(testRetryPolicy())(stub)
+	if _, err := s.NewOrder(context.Background(), NewOrderRequest{Symbol: "BNBETH"}); err == nil {
+		t.Fatalf("expected error")
+	}
+	if len(stub.calls) != 1 {
+		t.Errorf("new order must not be retried: %v", stub.calls)
+	}
+}
+
+func TestRetryMiddlewareServerError(t *testing.T) {
+	stub := &stubService{orderErr: &Error{StatusCode: 503}}
+	s := RetryMiddleware(testRetryPolicy())(stub)
+	if _, err := s.NewOrder(context.Background(), NewOrderRequest{Symbol: "BNBETH"}); err == nil || len(stub.calls) != 1 {
+		t.Errorf("new order must not be retried on 5xx by default: %v", stub.calls)
+	}
+
+	policy := testRetryPolicy()
+	policy.Retryable = func(method, endpoint string, params map[string]string) bool { return true }
+	stub = &stubService{orderErr: &Error{StatusCode: 503}}
+	s = RetryMiddleware(policy)(stub)
+	if _, err := s.NewOrder(context.Background(), NewOrderRequest{Symbol: "BNBETH"}); err == nil || len(stub.calls) != 3 {
+		t.Errorf("retryable new order must be retried on 5xx: %v", stub.calls)
+	}
+
+	stub = &stubService{orderErr: &Error{StatusCode: 400, Code: -1013}}
+	s = RetryMiddleware(policy)(stub)
+	if _, err := s.NewOrder(context.Background(), NewOrderRequest{Symbol: "BNBETH"}); err == nil || len(stub.calls) != 1 {
+		t.Errorf("new order must not be retried on API error: %v", stub.calls)
+	}
+}
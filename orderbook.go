@@ -0,0 +1,398 @@
+package binance
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+)
+
+// LocalOrderBook maintains order book of the symbol from REST snapshot and
+// diff depth stream.
+//
+// Events of the stream are buffered while the snapshot is fetched. Events
+// older than the snapshot are dropped and the rest is applied in order of
+// update IDs. Gap in the update IDs resyncs the book from a new snapshot,
+// closed stream is reconnected. State of the book is readable from multiple
+// goroutines.
+type LocalOrderBook struct {
+	// SnapshotLimit is number of levels per side fetched by the snapshot,
+	// 1000 if zero.
+	SnapshotLimit int
+	// RetryDelay is delay before reconnecting after failed stream or
+	// snapshot, 1 second if zero.
+	RetryDelay time.Duration
+
+	service Service
+	symbol  string
+	logger  log.Logger
+
+	mu          sync.RWMutex
+	bids        []*Order
+	asks        []*Order
+	updateID    int
+	synced      bool
+	updatedAt   time.Time
+	loading     bool
+	buffered    []*DepthEvent
+	subscribers map[int]chan struct{}
+	nextSub     int
+}
+
+// NewLocalOrderBook creates order book of the symbol maintained using provided
+// service. The book is empty until Run synchronizes it.
+func NewLocalOrderBook(service Service, symbol string, logger log.Logger) *LocalOrderBook {
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+	return &LocalOrderBook{
+		service:     service,
+		symbol:      symbol,
+		logger:      logger,
+		subscribers: make(map[int]chan struct{}),
+	}
+}
+
+// Run keeps the book synchronized until ctx is done.
+func (b *LocalOrderBook) Run(ctx context.Context) {
+	for {
+		err := b.stream(ctx)
+		b.reset()
+		if ctx.Err() != nil {
+			return
+		}
+		level.Error(b.logger).Log("symbol", b.symbol, "orderBookStream", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(b.retryDelay()):
+		}
+	}
+}
+
+type snapshotResult struct {
+	ob  *OrderBook
+	err error
+}
+
+// stream synchronizes the book using single stream connection until the
+// stream fails.
+func (b *LocalOrderBook) stream(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	dech, done, err := b.service.DepthWebsocket(ctx, DepthWebsocketRequest{Symbol: b.symbol})
+	if err != nil {
+		return err
+	}
+
+	snapshots := make(chan snapshotResult, 1)
+	fetch := func() {
+		go func() {
+			ob, err := b.service.OrderBook(ctx, OrderBookRequest{Symbol: b.symbol, Limit: b.snapshotLimit()})
+			snapshots <- snapshotResult{ob: ob, err: err}
+		}()
+	}
+	b.begin()
+	fetch()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-done:
+			return errors.New("depth stream closed")
+		case res := <-snapshots:
+			if res.err != nil {
+				return errors.Wrap(res.err, "cannot fetch order book snapshot")
+			}
+			if !b.handleSnapshot(res.ob) {
+				fetch()
+			}
+		case de := <-dech:
+			if !b.handleEvent(de) {
+				fetch()
+			}
+		}
+	}
+}
+
+// begin clears the book and starts buffering of events until snapshot is
+// handled. It's called after the stream is opened and before the snapshot is
+// requested.
+func (b *LocalOrderBook) begin() {
+	b.mu.Lock()
+	synced := b.synced
+	b.clear()
+	b.loading = true
+	b.mu.Unlock()
+	if synced {
+		b.notify()
+	}
+}
+
+// handleEvent buffers the event while snapshot is loaded or applies it. It
+// returns false if the event doesn't follow the book and new snapshot has to
+// be handled.
+func (b *LocalOrderBook) handleEvent(de *DepthEvent) bool {
+	b.mu.Lock()
+	if b.loading {
+		b.buffered = append(b.buffered, de)
+		b.mu.Unlock()
+		return true
+	}
+	ok := b.apply(de)
+	if !ok {
+		b.resync(de.FirstUpdateID, []*DepthEvent{de})
+	}
+	b.mu.Unlock()
+	b.notify()
+	return ok
+}
+
+// handleSnapshot loads the snapshot and applies buffered events following it.
+// It returns false if the snapshot is older than the buffered events and new
+// snapshot has to be handled. Snapshot is ignored if the book doesn't wait
+// for one.
+func (b *LocalOrderBook) handleSnapshot(ob *OrderBook) bool {
+	b.mu.Lock()
+	if !b.loading {
+		b.mu.Unlock()
+		return true
+	}
+	b.bids = snapshotLevels(ob.Bids, true)
+	b.asks = snapshotLevels(ob.Asks, false)
+	b.updateID = ob.LastUpdateID
+	b.synced = true
+	b.loading = false
+	b.updatedAt = time.Now()
+	buffered := b.buffered
+	b.buffered = nil
+	ok := true
+	for i, de := range buffered {
+		if !b.apply(de) {
+			b.resync(de.FirstUpdateID, buffered[i:])
+			ok = false
+			break
+		}
+	}
+	b.mu.Unlock()
+	b.notify()
+	return ok
+}
+
+// apply applies the event to the book, events older than the book are
+// ignored. It returns false if the event doesn't follow the last update.
+// The book has to be locked.
+func (b *LocalOrderBook) apply(de *DepthEvent) bool {
+	if de.UpdateID <= b.updateID {
+		return true
+	}
+	if de.FirstUpdateID > b.updateID+1 {
+		return false
+	}
+	for _, o := range de.Bids {
+		b.bids = setLevel(b.bids, o, true)
+	}
+	for _, o := range de.Asks {
+		b.asks = setLevel(b.asks, o, false)
+	}
+	b.updateID = de.UpdateID
+	b.updatedAt = time.Now()
+	return true
+}
+
+// resync clears the book after gap in update IDs and buffers events until
+// new snapshot is handled. The book has to be locked.
+func (b *LocalOrderBook) resync(firstUpdateID int, buffered []*DepthEvent) {
+	level.Debug(b.logger).Log("orderBookResync", b.symbol, "updateID", b.updateID, "firstUpdateID", firstUpdateID)
+	b.clear()
+	b.loading = true
+	b.buffered = append([]*DepthEvent(nil), buffered...)
+}
+
+// reset clears the book and notifies subscribers if it was synced.
+func (b *LocalOrderBook) reset() {
+	b.mu.Lock()
+	synced := b.synced
+	b.clear()
+	b.mu.Unlock()
+	if synced {
+		b.notify()
+	}
+}
+
+// clear removes all levels and buffered events. The book has to be locked.
+func (b *LocalOrderBook) clear() {
+	b.bids, b.asks = nil, nil
+	b.updateID = 0
+	b.synced = false
+	b.loading = false
+	b.buffered = nil
+}
+
+// awaitsSnapshot reports whether events are buffered until snapshot is
+// handled.
+func (b *LocalOrderBook) awaitsSnapshot() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.loading
+}
+
+// Subscribe returns channel receiving a value after the book changes and
+// function which unsubscribes it. Notifications are coalesced, current state
+// is read using methods of the book.
+func (b *LocalOrderBook) Subscribe() (<-chan struct{}, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextSub
+	b.nextSub++
+	ch := make(chan struct{}, 1)
+	b.subscribers[id] = ch
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers, id)
+	}
+}
+
+func (b *LocalOrderBook) notify() {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Symbol returns symbol of the book.
+func (b *LocalOrderBook) Symbol() string {
+	return b.symbol
+}
+
+// Synced reports whether the book reflects current state of the exchange.
+func (b *LocalOrderBook) Synced() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.synced
+}
+
+// UpdateID returns ID of the last applied update, zero if not synced.
+func (b *LocalOrderBook) UpdateID() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.updateID
+}
+
+// UpdatedAt returns time of the last applied snapshot or event.
+func (b *LocalOrderBook) UpdatedAt() time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.updatedAt
+}
+
+// BestBid returns the highest bid, false if the book isn't synced or has no
+// bids.
+func (b *LocalOrderBook) BestBid() (*Order, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.synced || len(b.bids) == 0 {
+		return nil, false
+	}
+	return b.bids[0], true
+}
+
+// BestAsk returns the lowest ask, false if the book isn't synced or has no
+// asks.
+func (b *LocalOrderBook) BestAsk() (*Order, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.synced || len(b.asks) == 0 {
+		return nil, false
+	}
+	return b.asks[0], true
+}
+
+// Depth returns copy of the book with at most n best levels per side, all
+// levels if n isn't positive. Nil is returned if the book isn't synced.
+func (b *LocalOrderBook) Depth(n int) *OrderBook {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.synced {
+		return nil
+	}
+	return &OrderBook{
+		LastUpdateID: b.updateID,
+		Bids:         topLevels(b.bids, n),
+		Asks:         topLevels(b.asks, n),
+	}
+}
+
+func (b *LocalOrderBook) snapshotLimit() int {
+	if b.SnapshotLimit == 0 {
+		return 1000
+	}
+	return b.SnapshotLimit
+}
+
+func (b *LocalOrderBook) retryDelay() time.Duration {
+	if b.RetryDelay == 0 {
+		return time.Second
+	}
+	return b.RetryDelay
+}
+
+// snapshotLevels returns sorted non-empty levels of the snapshot, bids are
+// sorted by descending price.
+func snapshotLevels(orders []*Order, desc bool) []*Order {
+	levels := make([]*Order, 0, len(orders))
+	for _, o := range orders {
+		if !o.Quantity.IsZero() {
+			levels = append(levels, &Order{Price: o.Price, Quantity: o.Quantity})
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		if desc {
+			return levels[i].Price.Cmp(levels[j].Price) > 0
+		}
+		return levels[i].Price.Cmp(levels[j].Price) < 0
+	})
+	return levels
+}
+
+// setLevel sets quantity of the price level, zero quantity removes it. Levels
+// are replaced rather than modified, so copies returned by the book stay
+// unchanged.
+func setLevel(levels []*Order, o *Order, desc bool) []*Order {
+	i := sort.Search(len(levels), func(i int) bool {
+		if desc {
+			return levels[i].Price.Cmp(o.Price) <= 0
+		}
+		return levels[i].Price.Cmp(o.Price) >= 0
+	})
+	found := i < len(levels) && levels[i].Price.Equal(o.Price)
+	switch {
+	case o.Quantity.IsZero() && found:
+		return append(levels[:i], levels[i+1:]...)
+	case o.Quantity.IsZero():
+		return levels
+	case found:
+		levels[i] = &Order{Price: o.Price, Quantity: o.Quantity}
+		return levels
+	}
+	levels = append(levels, nil)
+	copy(levels[i+1:], levels[i:])
+	levels[i] = &Order{Price: o.Price, Quantity: o.Quantity}
+	return levels
+}
+
+func topLevels(levels []*Order, n int) []*Order {
+	if n <= 0 || n > len(levels) {
+		n = len(levels)
+	}
+	return append([]*Order(nil), levels[:n]...)
+}
@@ -0,0 +1,142 @@
+package binance
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// depthStub serves depth streams and snapshots controlled by the test.
+type depthStub struct {
+	Service
+	dials     int32
+	events    chan *DepthEvent
+	closes    chan struct{}
+	requests  chan OrderBookRequest
+	snapshots chan *OrderBook
+}
+
+func (s *depthStub) DepthWebsocket(ctx context.Context, dwr DepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error) {
+	atomic.AddInt32(&s.dials, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		select {
+		case <-s.closes:
+		case <-ctx.Done():
+		}
+	}()
+	return s.events, done, nil
+}
+
+func (s *depthStub) OrderBook(ctx context.Context, obr OrderBookRequest) (*OrderBook, error) {
+	s.requests <- obr
+	return <-s.snapshots, nil
+}
+
+func levels(pq ...string) []*Order {
+	var orders []*Order
+	for i := 0; i < len(pq); i += 2 {
+		orders = append(orders, &Order{Price: MustParseDecimal(pq[i]), Quantity: MustParseDecimal(pq[i+1])})
+	}
+	return orders
+}
+
+func depthEvent(first, last int, bids, asks []*Order) *DepthEvent {
+	return &DepthEvent{FirstUpdateID: first, UpdateID: last, OrderBook: OrderBook{Bids: bids, Asks: asks}}
+}
+
+func TestLocalOrderBook(t *testing.T) {
+	d := MustParseDecimal
+	stub := &depthStub{
+		events:    make(chan *DepthEvent),
+		closes:    make(chan struct{}),
+		requests:  make(chan OrderBookRequest),
+		snapshots: make(chan *OrderBook),
+	}
+	b := NewLocalOrderBook(stub, "ETHBTC", nil)
+	b.RetryDelay = time.Millisecond
+	updates, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+	wait := func() {
+		select {
+		case <-updates:
+		case <-time.After(2 * time.Second):
+			t.Fatal("no update notification")
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	exited := make(chan struct{})
+	go func() {
+		b.Run(ctx)
+		close(exited)
+	}()
+
+	// events received before the snapshot are buffered
+	if obr := <-stub.requests; obr.Symbol != "ETHBTC" || obr.Limit != 1000 {
+		t.Errorf("unexpected snapshot request %+v", obr)
+	}
+	stub.events <- depthEvent(1, 3, levels("8", "1"), nil)
+	stub.events <- depthEvent(4, 5, levels("10", "0"), levels("11", "2", "11.5", "1"))
+	if b.Synced() || b.Depth(0) != nil {
+		t.Error("expected book not synced before snapshot")
+	}
+	stub.snapshots <- &OrderBook{LastUpdateID: 4, Bids: levels("10", "1", "9", "2"), Asks: levels("12", "3", "11", "1")}
+	wait()
+	bid, _ := b.BestBid()
+	ask, _ := b.BestAsk()
+	if !b.Synced() || b.UpdateID() != 5 || !bid.Price.Equal(d("9")) || !ask.Quantity.Equal(d("2")) {
+		t.Errorf("unexpected book %d: bid %+v, ask %+v", b.UpdateID(), bid, ask)
+	}
+	ob := b.Depth(2)
+	if len(ob.Bids) != 1 || len(ob.Asks) != 2 || !ob.Asks[1].Price.Equal(d("11.5")) || ob.LastUpdateID != 5 {
+		t.Errorf("unexpected depth %+v", ob)
+	}
+
+	// gap in update IDs resyncs the book
+	stub.events <- depthEvent(6, 6, nil, levels("11", "0"))
+	wait()
+	stub.events <- depthEvent(8, 8, levels("9.5", "1"), nil)
+	<-stub.requests
+	wait()
+	if b.Synced() {
+		t.Error("expected book not synced after gap")
+	}
+	stub.snapshots <- &OrderBook{LastUpdateID: 7, Bids: levels("9", "2"), Asks: levels("12", "3")}
+	wait()
+	if bid, _ := b.BestBid(); !bid.Price.Equal(d("9.5")) || b.UpdateID() != 8 {
+		t.Errorf("unexpected bid after resync %+v", bid)
+	}
+	if !ob.Asks[0].Quantity.Equal(d("2")) || len(ob.Asks) != 2 {
+		t.Error("expected depth copy unchanged")
+	}
+
+	// closed stream is reconnected
+	stub.closes <- struct{}{}
+	<-stub.requests
+	wait()
+	if n := atomic.LoadInt32(&stub.dials); n != 2 {
+		t.Errorf("expected stream reconnected, got %d dials", n)
+	}
+	stub.snapshots <- &OrderBook{LastUpdateID: 10}
+	wait()
+
+	cancel()
+	<-exited
+	if b.Synced() {
+		t.Error("expected book not synced after exit")
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	d := MustParseDecimal
+	var bids []*Order
+	for _, o := range levels("10", "1", "12", "1", "11", "1", "12", "2", "10", "0", "13", "0") {
+		bids = setLevel(bids, o, true)
+	}
+	if len(bids) != 2 || !bids[0].Price.Equal(d("12")) || !bids[0].Quantity.Equal(d("2")) || !bids[1].Price.Equal(d("11")) {
+		t.Errorf("unexpected bids %v", bids)
+	}
+}
@@ -0,0 +1,430 @@
+package binance
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+)
+
+// BookStatus represents health of order book maintained by OrderBookManager.
+type BookStatus string
+
+var (
+	// BookSynced is status of book reflecting current state of the exchange.
+	BookSynced = BookStatus("SYNCED")
+	// BookResyncing is status of book waiting for stream or snapshot.
+	BookResyncing = BookStatus("RESYNCING")
+	// BookStale is status of synced book which wasn't updated for StaleAfter.
+	BookStale = BookStatus("STALE")
+)
+
+// OrderBookManager maintains local order books of many symbols.
+//
+// Depth streams of the symbols are multiplexed over combined stream
+// connections, symbols added together share connections. Snapshots are
+// fetched one by one and their request weight is limited by SnapshotWeight, so
+// that syncing hundreds of books doesn't exhaust request weight of the
+// account. Failed connections are reopened with their current symbols and
+// their books are resynced.
+type OrderBookManager struct {
+	// StreamsPerConnection is maximum number of symbols multiplexed over
+	// single connection, 200 if zero.
+	StreamsPerConnection int
+	// SnapshotLimit is number of levels per side fetched by snapshots, 100
+	// if zero.
+	SnapshotLimit int
+	// SnapshotWeight is request weight per minute available to snapshots,
+	// 1200 if zero.
+	SnapshotWeight int
+	// StaleAfter is duration without updates after which synced book is
+	// reported as stale, 1 minute if zero. Books of illiquid symbols may be
+	// reported as stale even if their connection is healthy.
+	StaleAfter time.Duration
+	// RetryDelay is delay before reopening failed connection or retrying
+	// failed snapshot, 1 second if zero.
+	RetryDelay time.Duration
+
+	service Service
+	logger  log.Logger
+	wake    chan struct{}
+	fetches chan struct{}
+
+	mu      sync.RWMutex
+	books   map[string]*managedBook
+	pending []string
+	queue   []string
+	queued  map[string]bool
+}
+
+// managedBook is book and connection delivering its events.
+type managedBook struct {
+	book *LocalOrderBook
+	conn *bookConn
+}
+
+// bookConn is combined stream connection shared by books. Its context is
+// cancelled when all its symbols are removed.
+type bookConn struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	symbols map[string]bool
+}
+
+// NewOrderBookManager creates manager maintaining books using provided service.
+func NewOrderBookManager(service Service, logger log.Logger) *OrderBookManager {
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+	return &OrderBookManager{
+		service: service,
+		logger:  logger,
+		wake:    make(chan struct{}, 1),
+		fetches: make(chan struct{}, 1),
+		books:   make(map[string]*managedBook),
+		queued:  make(map[string]bool),
+	}
+}
+
+// Add starts maintaining books of the symbols, symbols already managed are
+// ignored. Books are synced by Run, they can be added before or while it runs.
+func (m *OrderBookManager) Add(symbols ...string) {
+	m.mu.Lock()
+	for _, symbol := range symbols {
+		if _, ok := m.books[symbol]; ok {
+			continue
+		}
+		m.books[symbol] = &managedBook{book: NewLocalOrderBook(m.service, symbol, m.logger)}
+		m.pending = append(m.pending, symbol)
+	}
+	m.mu.Unlock()
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Remove stops maintaining books of the symbols. Connection is closed when all
+// its symbols are removed.
+func (m *OrderBookManager) Remove(symbols ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, symbol := range symbols {
+		mb, ok := m.books[symbol]
+		if !ok {
+			continue
+		}
+		delete(m.books, symbol)
+		mb.book.reset()
+		if mb.conn == nil {
+			continue
+		}
+		delete(mb.conn.symbols, symbol)
+		if len(mb.conn.symbols) == 0 {
+			mb.conn.cancel()
+		}
+	}
+}
+
+// Book returns book of the symbol, false if the symbol isn't managed.
+func (m *OrderBookManager) Book(symbol string) (*LocalOrderBook, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	mb, ok := m.books[symbol]
+	if !ok {
+		return nil, false
+	}
+	return mb.book, true
+}
+
+// Symbols returns sorted managed symbols.
+func (m *OrderBookManager) Symbols() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	symbols := make([]string, 0, len(m.books))
+	for symbol := range m.books {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Status returns health of the book, false if the symbol isn't managed.
+func (m *OrderBookManager) Status(symbol string) (BookStatus, bool) {
+	book, ok := m.Book(symbol)
+	if !ok {
+		return "", false
+	}
+	switch {
+	case !book.Synced():
+		return BookResyncing, true
+	case time.Since(book.UpdatedAt()) > m.staleAfter():
+		return BookStale, true
+	}
+	return BookSynced, true
+}
+
+// Health returns status of every managed book.
+func (m *OrderBookManager) Health() map[string]BookStatus {
+	health := make(map[string]BookStatus)
+	for _, symbol := range m.Symbols() {
+		if status, ok := m.Status(symbol); ok {
+			health[symbol] = status
+		}
+	}
+	return health
+}
+
+// Run maintains the books until ctx is done.
+func (m *OrderBookManager) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer m.detach()
+	defer wg.Wait()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.fetchSnapshots(ctx)
+	}()
+
+	for {
+		for _, conn := range m.connect(ctx) {
+			wg.Add(1)
+			go func(conn *bookConn) {
+				defer wg.Done()
+				m.runConn(conn)
+			}(conn)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.wake:
+		}
+	}
+}
+
+// connect assigns pending symbols to new connections.
+func (m *OrderBookManager) connect(ctx context.Context) []*bookConn {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var conns []*bookConn
+	for len(m.pending) > 0 {
+		n := m.streamsPerConnection()
+		if n > len(m.pending) {
+			n = len(m.pending)
+		}
+		conn := &bookConn{symbols: make(map[string]bool)}
+		for _, symbol := range m.pending[:n] {
+			if mb, ok := m.books[symbol]; ok && mb.conn == nil {
+				mb.conn = conn
+				conn.symbols[symbol] = true
+			}
+		}
+		m.pending = m.pending[n:]
+		if len(conn.symbols) > 0 {
+			conn.ctx, conn.cancel = context.WithCancel(ctx)
+			conns = append(conns, conn)
+		}
+	}
+	return conns
+}
+
+// detach unassigns books from connections of finished Run, so that next Run
+// connects them again.
+func (m *OrderBookManager) detach() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending = m.pending[:0]
+	for symbol, mb := range m.books {
+		mb.conn = nil
+		m.pending = append(m.pending, symbol)
+	}
+	sort.Strings(m.pending)
+	m.queue, m.queued = nil, make(map[string]bool)
+}
+
+// runConn keeps the connection open until all its symbols are removed or Run
+// finishes.
+func (m *OrderBookManager) runConn(conn *bookConn) {
+	defer conn.cancel()
+	for {
+		symbols := m.connSymbols(conn)
+		if len(symbols) == 0 || conn.ctx.Err() != nil {
+			return
+		}
+		err := m.stream(conn, symbols)
+		for _, symbol := range symbols {
+			if book, ok := m.connBook(conn, symbol); ok {
+				book.reset()
+			}
+		}
+		if conn.ctx.Err() != nil {
+			return
+		}
+		level.Error(m.logger).Log("orderBookStream", err, "symbols", len(symbols))
+		select {
+		case <-conn.ctx.Done():
+			return
+		case <-time.After(m.retryDelay()):
+		}
+	}
+}
+
+// stream opens combined stream of the symbols and dispatches its events to
+// their books until the stream fails.
+func (m *OrderBookManager) stream(conn *bookConn, symbols []string) error {
+	ctx, cancel := context.WithCancel(conn.ctx)
+	defer cancel()
+	dech, done, err := m.service.CombinedDepthWebsocket(ctx, CombinedDepthWebsocketRequest{Symbols: symbols})
+	if err != nil {
+		return err
+	}
+	for _, symbol := range symbols {
+		if book, ok := m.connBook(conn, symbol); ok {
+			book.begin()
+			m.enqueue(symbol)
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-done:
+			return errors.New("depth stream closed")
+		case de := <-dech:
+			if book, ok := m.connBook(conn, de.Symbol); ok && !book.handleEvent(de) {
+				m.enqueue(de.Symbol)
+			}
+		}
+	}
+}
+
+func (m *OrderBookManager) connSymbols(conn *bookConn) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	symbols := make([]string, 0, len(conn.symbols))
+	for symbol := range conn.symbols {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// connBook returns book of the symbol if its events are delivered by the
+// connection.
+func (m *OrderBookManager) connBook(conn *bookConn, symbol string) (*LocalOrderBook, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	mb, ok := m.books[symbol]
+	if !ok || mb.conn != conn {
+		return nil, false
+	}
+	return mb.book, true
+}
+
+// fetchSnapshots fetches queued snapshots one by one within SnapshotWeight
+// until ctx is done.
+func (m *OrderBookManager) fetchSnapshots(ctx context.Context) {
+	limiter := NewRateLimiter(RateLimits{RequestWeight: m.snapshotWeight()})
+	params := map[string]string{"limit": strconv.Itoa(m.snapshotLimit())}
+	for {
+		symbol, ok := m.dequeue()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-m.fetches:
+			}
+			continue
+		}
+		book, ok := m.Book(symbol)
+		if !ok || !book.awaitsSnapshot() {
+			continue
+		}
+		if err := limiter.Wait(ctx, "GET", "api/v1/depth", params); err != nil {
+			return
+		}
+		ob, err := m.service.OrderBook(ctx, OrderBookRequest{Symbol: symbol, Limit: m.snapshotLimit()})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			level.Error(m.logger).Log("orderBookSnapshot", err, "symbol", symbol)
+			m.enqueue(symbol)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(m.retryDelay()):
+			}
+			continue
+		}
+		if !book.handleSnapshot(ob) {
+			m.enqueue(symbol)
+		}
+	}
+}
+
+// enqueue queues snapshot of the symbol unless it's queued already.
+func (m *OrderBookManager) enqueue(symbol string) {
+	m.mu.Lock()
+	if !m.queued[symbol] {
+		m.queued[symbol] = true
+		m.queue = append(m.queue, symbol)
+	}
+	m.mu.Unlock()
+	select {
+	case m.fetches <- struct{}{}:
+	default:
+	}
+}
+
+func (m *OrderBookManager) dequeue() (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.queue) == 0 {
+		return "", false
+	}
+	symbol := m.queue[0]
+	m.queue = m.queue[1:]
+	delete(m.queued, symbol)
+	return symbol, true
+}
+
+func (m *OrderBookManager) streamsPerConnection() int {
+	if m.StreamsPerConnection == 0 {
+		return 200
+	}
+	return m.StreamsPerConnection
+}
+
+func (m *OrderBookManager) snapshotLimit() int {
+	if m.SnapshotLimit == 0 {
+		return 100
+	}
+	return m.SnapshotLimit
+}
+
+func (m *OrderBookManager) snapshotWeight() int {
+	if m.SnapshotWeight == 0 {
+		return 1200
+	}
+	return m.SnapshotWeight
+}
+
+func (m *OrderBookManager) staleAfter() time.Duration {
+	if m.StaleAfter == 0 {
+		return time.Minute
+	}
+	return m.StaleAfter
+}
+
+func (m *OrderBookManager) retryDelay() time.Duration {
+	if m.RetryDelay == 0 {
+		return time.Second
+	}
+	return m.RetryDelay
+}
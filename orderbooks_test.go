@@ -0,0 +1,137 @@
+package binance
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// combinedStub serves combined depth streams and snapshots of all symbols.
+type combinedStub struct {
+	Service
+	conns     chan *stubConn
+	snapshots int32
+}
+
+type stubConn struct {
+	ctx     context.Context
+	symbols []string
+	events  chan *DepthEvent
+	closes  chan struct{}
+}
+
+func (s *combinedStub) CombinedDepthWebsocket(ctx context.Context, cdwr CombinedDepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error) {
+	conn := &stubConn{ctx: ctx, symbols: cdwr.Symbols, events: make(chan *DepthEvent), closes: make(chan struct{})}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		select {
+		case <-conn.closes:
+		case <-ctx.Done():
+		}
+	}()
+	s.conns <- conn
+	return conn.events, done, nil
+}
+
+func (s *combinedStub) OrderBook(ctx context.Context, obr OrderBookRequest) (*OrderBook, error) {
+	atomic.AddInt32(&s.snapshots, 1)
+	return &OrderBook{LastUpdateID: 10, Bids: levels("1", "1")}, nil
+}
+
+func TestOrderBookManager(t *testing.T) {
+	stub := &combinedStub{conns: make(chan *stubConn)}
+	m := NewOrderBookManager(stub, nil)
+	m.StreamsPerConnection = 2
+	m.SnapshotWeight = 15
+	m.RetryDelay = time.Millisecond
+
+	expectHealth := func(expected string) {
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			var health []string
+			for symbol, status := range m.Health() {
+				health = append(health, symbol+":"+string(status))
+			}
+			sort.Strings(health)
+			if strings.Join(health, " ") == expected {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected health %s, got %v", expected, health)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	receive := func() *stubConn {
+		select {
+		case conn := <-stub.conns:
+			return conn
+		case <-time.After(2 * time.Second):
+			t.Fatal("connection not opened")
+			return nil
+		}
+	}
+
+	m.Add("A", "B", "C")
+	ctx, cancel := context.WithCancel(context.Background())
+	exited := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(exited)
+	}()
+
+	// symbols are split into connections
+	first, second := receive(), receive()
+	if len(first.symbols) < len(second.symbols) {
+		first, second = second, first
+	}
+	if strings.Join(first.symbols, ",") != "A,B" || strings.Join(second.symbols, ",") != "C" {
+		t.Fatalf("unexpected connections %v and %v", first.symbols, second.symbols)
+	}
+	expectHealth("A:SYNCED B:SYNCED C:SYNCED")
+
+	b, _ := m.Book("B")
+	updates, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+	first.events <- &DepthEvent{WSEvent: WSEvent{Symbol: "B"}, FirstUpdateID: 11, UpdateID: 11, OrderBook: OrderBook{Bids: levels("2", "1")}}
+	<-updates
+	if bid, _ := b.BestBid(); b.UpdateID() != 11 || !bid.Price.Equal(MustParseDecimal("2")) {
+		t.Errorf("expected event applied, got update %d", b.UpdateID())
+	}
+	a, _ := m.Book("A")
+	a.mu.Lock()
+	a.updatedAt = time.Now().Add(-time.Hour)
+	a.mu.Unlock()
+	expectHealth("A:STALE B:SYNCED C:SYNCED")
+
+	// removing the last symbol closes the connection, snapshot of added
+	// symbol waits for request weight
+	m.Remove("C")
+	select {
+	case <-second.ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Error("connection not closed")
+	}
+	m.Add("D")
+	if third := receive(); strings.Join(third.symbols, ",") != "D" {
+		t.Errorf("unexpected connection %v", third.symbols)
+	}
+	expectHealth("A:STALE B:SYNCED D:RESYNCING")
+
+	// failed connection is reopened and its books resynced
+	first.closes <- struct{}{}
+	if reopened := receive(); strings.Join(reopened.symbols, ",") != "A,B" {
+		t.Errorf("unexpected connection %v", reopened.symbols)
+	}
+	expectHealth("A:RESYNCING B:RESYNCING D:RESYNCING")
+	if n := atomic.LoadInt32(&stub.snapshots); n != 3 {
+		t.Errorf("expected 3 snapshots within weight, got %d", n)
+	}
+
+	cancel()
+	<-exited
+}
@@ -0,0 +1,193 @@
+// Package paper provides binance.Service trading against live market data
+// without sending orders to the exchange.
+//
+// Market data calls and market streams are forwarded to upstream service, e.g.
+// created by binance.NewAPIService. Orders, balances, trades, withdrawals and
+// user data stream are served by sim.Service on the exchange whose books are
+// kept in sync with the live ones by Track. Because Service implements
+// binance.Service, bots run unchanged in paper mode:
+//
+//	exchange := sim.NewExchange(nil)
+//	exchange.SetBalance("BTC", binance.MustParseDecimal("1"))
+//	exchange.SetCommissions(binance.MustParseDecimal("0.001"), binance.MustParseDecimal("0.001"))
+//	ps := paper.NewService(upstream, exchange)
+//	done, err := ps.Track(ctx, sim.Symbol{Name: "BNBBTC", BaseAsset: "BNB", QuoteAsset: "BTC"})
+//	b := binance.NewBinance(ps)
+package paper
+
+import (
+	"context"
+
+	"github.com/rootpd/binance"
+	"github.com/rootpd/binance/sim"
+)
+
+// Service simulates trading calls locally and forwards the rest upstream.
+type Service struct {
+	binance.Service
+	Exchange *sim.Exchange
+
+	// account serves the simulated calls
+	account *sim.Service
+}
+
+var _ binance.Service = (*Service)(nil)
+
+// NewService creates paper trading service forwarding market data to upstream
+// and trading on the exchange. New exchange with real clock is created if nil.
+func NewService(upstream binance.Service, exchange *sim.Exchange) *Service {
+	if exchange == nil {
+		exchange = sim.NewExchange(nil)
+	}
+	return &Service{
+		Service:  upstream,
+		Exchange: exchange,
+		account:  sim.NewService(exchange),
+	}
+}
+
+// Track lists the symbol on the exchange and keeps its book and trades in sync
+// with upstream until ctx is done or one of the upstream streams ends. Book is
+// initialized from snapshot of the order book, orders of the account resting
+// in the book are filled by live trades crossing their price. Like
+// binance.LocalOrderBook, gap in update IDs of the depth stream reloads the
+// book from new snapshot. Returned channel is closed when tracking stops.
+func (s *Service) Track(ctx context.Context, symbol sim.Symbol) (chan struct{}, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	// streams are opened before the snapshot so no update is missed
+	dech, depthDone, err := s.Service.DepthWebsocket(ctx, binance.DepthWebsocketRequest{Symbol: symbol.Name})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	aech, tradeDone, err := s.Service.TradeWebsocket(ctx, binance.TradeWebsocketRequest{Symbol: symbol.Name})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	ob, err := s.snapshot(ctx, symbol.Name)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	s.Exchange.AddSymbol(symbol)
+	s.load(symbol.Name, ob)
+	updateID := ob.LastUpdateID
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer cancel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-depthDone:
+				return
+			case <-tradeDone:
+				return
+			case de := <-dech:
+				if de.FirstUpdateID > updateID+1 {
+					// missed update, events older than new snapshot are dropped
+					ob, err := s.snapshot(ctx, symbol.Name)
+					if err != nil {
+						return
+					}
+					s.load(symbol.Name, ob)
+					updateID = ob.LastUpdateID
+				}
+				if de.UpdateID <= updateID || de.FirstUpdateID > updateID+1 {
+					continue
+				}
+				for _, o := range de.Bids {
+					s.Exchange.SetLiquidity(symbol.Name, binance.SideBuy, o.Price, o.Quantity)
+				}
+				for _, o := range de.Asks {
+					s.Exchange.SetLiquidity(symbol.Name, binance.SideSell, o.Price, o.Quantity)
+				}
+				updateID = de.UpdateID
+			case ae := <-aech:
+				s.Exchange.ExecuteTrade(symbol.Name, ae.Price, ae.Quantity, ae.BuyerMaker)
+			}
+		}
+	}()
+	return done, nil
+}
+
+func (s *Service) snapshot(ctx context.Context, symbol string) (*binance.OrderBook, error) {
+	return s.Service.OrderBook(ctx, binance.OrderBookRequest{Symbol: symbol, Limit: 1000})
+}
+
+// load replaces liquidity of the symbol by the snapshot.
+func (s *Service) load(symbol string, ob *binance.OrderBook) {
+	s.Exchange.ClearLiquidity(symbol)
+	for _, o := range ob.Bids {
+		s.Exchange.SetLiquidity(symbol, binance.SideBuy, o.Price, o.Quantity)
+	}
+	for _, o := range ob.Asks {
+		s.Exchange.SetLiquidity(symbol, binance.SideSell, o.Price, o.Quantity)
+	}
+}
+
+// NewOrder places the order on the exchange, nothing is sent upstream.
+func (s *Service) NewOrder(ctx context.Context, or binance.NewOrderRequest) (*binance.ProcessedOrder, error) {
+	return s.account.NewOrder(ctx, or)
+}
+
+func (s *Service) NewOrderTest(ctx context.Context, or binance.NewOrderRequest) error {
+	return s.account.NewOrderTest(ctx, or)
+}
+
+func (s *Service) QueryOrder(ctx context.Context, qor binance.QueryOrderRequest) (*binance.ExecutedOrder, error) {
+	return s.account.QueryOrder(ctx, qor)
+}
+
+func (s *Service) CancelOrder(ctx context.Context, cor binance.CancelOrderRequest) (*binance.CanceledOrder, error) {
+	return s.account.CancelOrder(ctx, cor)
+}
+
+func (s *Service) OpenOrders(ctx context.Context, oor binance.OpenOrdersRequest) ([]*binance.ExecutedOrder, error) {
+	return s.account.OpenOrders(ctx, oor)
+}
+
+func (s *Service) AllOrders(ctx context.Context, aor binance.AllOrdersRequest) ([]*binance.ExecutedOrder, error) {
+	return s.account.AllOrders(ctx, aor)
+}
+
+func (s *Service) Account(ctx context.Context, ar binance.AccountRequest) (*binance.Account, error) {
+	return s.account.Account(ctx, ar)
+}
+
+func (s *Service) MyTrades(ctx context.Context, mtr binance.MyTradesRequest) ([]*binance.Trade, error) {
+	return s.account.MyTrades(ctx, mtr)
+}
+
+// Withdraw withdraws from virtual balance, nothing is sent to the exchange.
+func (s *Service) Withdraw(ctx context.Context, wr binance.WithdrawRequest) (*binance.WithdrawResult, error) {
+	return s.account.Withdraw(ctx, wr)
+}
+
+func (s *Service) DepositHistory(ctx context.Context, hr binance.HistoryRequest) ([]*binance.Deposit, error) {
+	return s.account.DepositHistory(ctx, hr)
+}
+
+func (s *Service) WithdrawHistory(ctx context.Context, hr binance.HistoryRequest) ([]*binance.Withdrawal, error) {
+	return s.account.WithdrawHistory(ctx, hr)
+}
+
+func (s *Service) StartUserDataStream(ctx context.Context) (*binance.Stream, error) {
+	return s.account.StartUserDataStream(ctx)
+}
+
+func (s *Service) KeepAliveUserDataStream(ctx context.Context, stream *binance.Stream) error {
+	return s.account.KeepAliveUserDataStream(ctx, stream)
+}
+
+func (s *Service) CloseUserDataStream(ctx context.Context, stream *binance.Stream) error {
+	return s.account.CloseUserDataStream(ctx, stream)
+}
+
+// UserDataWebsocket streams account events of the virtual account.
+func (s *Service) UserDataWebsocket(ctx context.Context, udwr binance.UserDataWebsocketRequest) (chan *binance.AccountEvent, chan struct{}, error) {
+	return s.account.UserDataWebsocket(ctx, udwr)
+}
@@ -0,0 +1,153 @@
+package paper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rootpd/binance"
+	"github.com/rootpd/binance/binancetest"
+	"github.com/rootpd/binance/sim"
+)
+
+var d = binance.MustParseDecimal
+
+var bnbbtc = sim.Symbol{Name: "BNBBTC", BaseAsset: "BNB", QuoteAsset: "BTC"}
+
+// eventually waits until cond holds, events of live streams are processed asynchronously.
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPaperTrading(t *testing.T) {
+	live := sim.NewExchange(nil)
+	live.AddSymbol(bnbbtc)
+	live.AddLiquidity("BNBBTC", binance.SideSell, d("0.1"), d("5"))
+	live.AddLiquidity("BNBBTC", binance.SideBuy, d("0.09"), d("5"))
+	upstream := binancetest.NewFakeService(live)
+
+	exchange := sim.NewExchange(nil)
+	exchange.SetBalance("BTC", d("1"))
+	exchange.SetCommissions(d("0.001"), d("0.002"))
+	ps := NewService(upstream, exchange)
+	b := binance.NewBinance(ps)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done, err := ps.Track(ctx, bnbbtc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s, _ := b.StartUserDataStream()
+	accounts, _, err := b.UserDataWebsocketContext(ctx, binance.UserDataWebsocketRequest{ListenKey: s.ListenKey})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// market order is filled by the snapshot of live book
+	if _, err := b.NewOrder(binance.NewOrderRequest{Symbol: "BNBBTC", Side: binance.SideBuy, Type: binance.TypeMarket, Quantity: d("2")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ae := <-accounts; len(ae.Balances) != 2 || !ae.Balances[0].Free.Equal(d("1.996")) {
+		t.Errorf("unexpected account event: %+v", ae.Balances[0])
+	}
+	po, err := b.NewOrder(binance.NewOrderRequest{
+		Symbol: "BNBBTC", Side: binance.SideBuy, Type: binance.TypeLimit, TimeInForce: binance.GTC,
+		Quantity: d("1"), Price: d("0.095"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, call := range upstream.Calls() {
+		if call.Method == "NewOrder" {
+			t.Errorf("order sent upstream: %+v", call)
+		}
+	}
+
+	// live trade crossing the resting order fills it as maker
+	live.ExecuteTrade("BNBBTC", d("0.095"), d("0.4"), true)
+	eventually(t, func() bool {
+		eo, _ := b.QueryOrder(binance.QueryOrderRequest{Symbol: "BNBBTC", OrderID: po.OrderID})
+		return eo.ExecutedQty.Equal(d("0.4"))
+	})
+	trades, _ := b.MyTrades(binance.MyTradesRequest{Symbol: "BNBBTC"})
+	if len(trades) != 2 || trades[0].IsMaker || !trades[1].IsMaker || !trades[1].Commission.Equal(d("0.0004")) {
+		t.Errorf("unexpected trades: %+v %+v", trades[0], trades[1])
+	}
+
+	// live depth updates are mirrored
+	live.AddLiquidity("BNBBTC", binance.SideSell, d("0.1"), d("4"))
+	live.AddLiquidity("BNBBTC", binance.SideSell, d("0.11"), d("7"))
+	eventually(t, func() bool {
+		ob, _ := ps.Exchange.OrderBook("BNBBTC", 0)
+		return len(ob.Asks) == 2 && ob.Asks[0].Quantity.Equal(d("9")) && ob.Asks[1].Quantity.Equal(d("7"))
+	})
+	if ob, err := b.OrderBook(binance.OrderBookRequest{Symbol: "BNBBTC"}); err != nil || !ob.Asks[0].Quantity.Equal(d("9")) {
+		t.Errorf("expected live order book, got %+v: %v", ob, err)
+	}
+
+	cancel()
+	<-done
+}
+
+func TestTrackUnknownSymbol(t *testing.T) {
+	ps := NewService(binancetest.NewFakeService(nil), nil)
+	if _, err := ps.Track(context.Background(), bnbbtc); err == nil {
+		t.Error("expected error for symbol unknown upstream")
+	}
+	if len(ps.Exchange.Symbols()) != 0 {
+		t.Errorf("unexpected symbols: %+v", ps.Exchange.Symbols())
+	}
+}
+
+// depthUpstream streams programmed depth events and serves programmed snapshots.
+type depthUpstream struct {
+	*binancetest.FakeService
+	events    chan *binance.DepthEvent
+	snapshots chan *binance.OrderBook
+}
+
+func (u *depthUpstream) DepthWebsocket(ctx context.Context, dwr binance.DepthWebsocketRequest) (chan *binance.DepthEvent, chan struct{}, error) {
+	return u.events, make(chan struct{}), nil
+}
+
+func (u *depthUpstream) OrderBook(ctx context.Context, obr binance.OrderBookRequest) (*binance.OrderBook, error) {
+	return <-u.snapshots, nil
+}
+
+func TestTrackResyncsOnGap(t *testing.T) {
+	upstream := &depthUpstream{
+		FakeService: binancetest.NewFakeService(nil),
+		events:      make(chan *binance.DepthEvent),
+		snapshots:   make(chan *binance.OrderBook, 2),
+	}
+	upstream.snapshots <- &binance.OrderBook{LastUpdateID: 10, Asks: []*binance.Order{{Price: d("0.1"), Quantity: d("5")}}}
+	ps := NewService(upstream, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := ps.Track(ctx, bnbbtc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	asks := func() []*binance.Order {
+		ob, _ := ps.Exchange.OrderBook("BNBBTC", 0)
+		return ob.Asks
+	}
+
+	upstream.events <- &binance.DepthEvent{FirstUpdateID: 9, UpdateID: 12, Asks: []*binance.Order{{Price: d("0.1"), Quantity: d("6")}}}
+	eventually(t, func() bool { a := asks(); return len(a) == 1 && a[0].Quantity.Equal(d("6")) })
+
+	// update 13 is missed, the book is reloaded and the event older than snapshot dropped
+	upstream.snapshots <- &binance.OrderBook{LastUpdateID: 20, Asks: []*binance.Order{{Price: d("0.1"), Quantity: d("3")}}}
+	upstream.events <- &binance.DepthEvent{FirstUpdateID: 14, UpdateID: 16, Asks: []*binance.Order{{Price: d("0.2"), Quantity: d("1")}}}
+	upstream.events <- &binance.DepthEvent{FirstUpdateID: 21, UpdateID: 22, Asks: []*binance.Order{{Price: d("0.3"), Quantity: d("1")}}}
+	eventually(t, func() bool { return len(asks()) == 2 })
+	if a := asks(); !a[0].Quantity.Equal(d("3")) || !a[1].Price.Equal(d("0.3")) {
+		t.Errorf("unexpected asks after resync: %+v %+v", a[0], a[1])
+	}
+}
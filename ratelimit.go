@@ -0,0 +1,355 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimits represents limits enforced by RateLimiter.
+//
+// Zero value of the limit disables its enforcement.
+type RateLimits struct {
+	// RequestWeight is REQUEST_WEIGHT limit per minute.
+	RequestWeight int
+	// Orders is ORDERS limit per 10 seconds.
+	Orders int
+	// DailyOrders is ORDERS limit per day.
+	DailyOrders int
+	// RawRequests is RAW_REQUESTS limit per 5 minutes.
+	RawRequests int
+}
+
+// DefaultRateLimits returns limits published by Binance for spot API.
+func DefaultRateLimits() RateLimits {
+	return RateLimits{
+		RequestWeight: 6000,
+		Orders:        100,
+		DailyOrders:   200000,
+		RawRequests:   61000,
+	}
+}
+
+// RateLimitError is returned when request was not sent to prevent exceeding
+// of rate limits or because the limiter backs off after 429/418 response.
+type RateLimitError struct {
+	// Limit identifies limit which would be exceeded.
+	Limit string
+	// RetryAfter is the time after which request can be retried.
+	RetryAfter time.Duration
+}
+
+// Error returns formatted error message.
+func (e RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit %s reached, retry after %s", e.Limit, e.RetryAfter)
+}
+
+// Names of the limits tracked by RateLimiter.
+const (
+	LimitRequestWeight = "REQUEST_WEIGHT"
+	LimitOrders        = "ORDERS"
+	LimitRawRequests   = "RAW_REQUESTS"
+	LimitBackoff       = "BACKOFF"
+)
+
+// RateLimiter throttles requests according to Binance rate limits.
+//
+// Limiter keeps its own account of used limits and corrects it with usage
+// reported by the server in X-MBX-USED-WEIGHT-* and X-MBX-ORDER-COUNT-*
+// headers. When 429 or 418 is returned, circuit is opened and all requests fail
+// fast until Retry-After passes. First request after that is sent as a probe
+// and the circuit is closed only if the probe isn't rate limited again.
+//
+// Single limiter should be shared by all services using the same IP address
+// or API key, as the limits are enforced by Binance across all of them.
+type RateLimiter struct {
+	// FailFast makes limiter return RateLimitError instead of waiting for the
+	// limit window to reset.
+	FailFast bool
+	// DefaultBackoff is used when 429/418 response doesn't contain Retry-After.
+	DefaultBackoff time.Duration
+
+	mu          sync.Mutex
+	now         func() time.Time
+	weight      *limitWindow
+	orders      *limitWindow
+	dailyOrders *limitWindow
+	raw         *limitWindow
+	openUntil   time.Time
+	probing     bool
+}
+
+// NewRateLimiter creates RateLimiter enforcing provided limits.
+func NewRateLimiter(limits RateLimits) *RateLimiter {
+	return &RateLimiter{
+		DefaultBackoff: time.Minute,
+		now:            time.Now,
+		weight:         &limitWindow{name: LimitRequestWeight, interval: time.Minute, limit: limits.RequestWeight},
+		orders:         &limitWindow{name: LimitOrders, interval: 10 * time.Second, limit: limits.Orders},
+		dailyOrders:    &limitWindow{name: LimitOrders, interval: 24 * time.Hour, limit: limits.DailyOrders},
+		raw:            &limitWindow{name: LimitRawRequests, interval: 5 * time.Minute, limit: limits.RawRequests},
+	}
+}
+
+// UsedWeight returns request weight used in current window.
+func (rl *RateLimiter) UsedWeight() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.weight.roll(rl.now())
+	return rl.weight.used
+}
+
+// OrderCount returns number of orders placed in current 10 seconds window.
+func (rl *RateLimiter) OrderCount() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.orders.roll(rl.now())
+	return rl.orders.used
+}
+
+// Wait blocks until request to the endpoint can be sent without exceeding
+// limits and reserves its cost. It returns RateLimitError when circuit is open
+// or FailFast is set, and context error when ctx is done while waiting.
+func (rl *RateLimiter) Wait(ctx context.Context, method, endpoint string, params map[string]string) error {
+	_, err := rl.Reserve(ctx, method, endpoint, params)
+	return err
+}
+
+// Reserve waits as Wait does and returns the reservation, so its cost can be
+// released if the request isn't sent.
+func (rl *RateLimiter) Reserve(ctx context.Context, method, endpoint string, params map[string]string) (*Reservation, error) {
+	weight := RequestWeight(method, endpoint, params)
+	order := isOrderEndpoint(method, endpoint)
+	for {
+		r, delay, err := rl.reserve(weight, order)
+		if err != nil {
+			return nil, err
+		}
+		if delay == 0 {
+			return r, nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Reservation is cost of single request reserved by RateLimiter.
+type Reservation struct {
+	windows []*limitWindow
+	costs   []int
+	starts  []time.Time
+	probe   bool
+}
+
+func (rl *RateLimiter) reserve(weight int, order bool) (*Reservation, time.Duration, error) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	if now.Before(rl.openUntil) {
+		return nil, 0, RateLimitError{Limit: LimitBackoff, RetryAfter: rl.openUntil.Sub(now)}
+	}
+	if rl.probing {
+		return nil, 0, RateLimitError{Limit: LimitBackoff, RetryAfter: time.Second}
+	}
+
+	r := &Reservation{
+		windows: []*limitWindow{rl.weight, rl.raw},
+		costs:   []int{weight, 1},
+	}
+	if order {
+		r.windows = append(r.windows, rl.orders, rl.dailyOrders)
+		r.costs = append(r.costs, 1, 1)
+	}
+	for i, w := range r.windows {
+		w.roll(now)
+		if !w.allows(r.costs[i]) {
+			delay := w.resetIn(now)
+			if rl.FailFast {
+				return nil, 0, RateLimitError{Limit: w.name, RetryAfter: delay}
+			}
+			return nil, delay, nil
+		}
+	}
+	for i, w := range r.windows {
+		w.used += r.costs[i]
+		r.starts = append(r.starts, w.start)
+	}
+	if !rl.openUntil.IsZero() {
+		// circuit was open, this request is the probe
+		rl.probing = true
+		r.probe = true
+	}
+	return r, 0, nil
+}
+
+// Update updates limiter state with response of the request. Response may be
+// nil if request failed.
+func (rl *RateLimiter) Update(res *http.Response) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	wasProbing := rl.probing
+	rl.probing = false
+	if res == nil {
+		return
+	}
+
+	for key, values := range res.Header {
+		if len(values) == 0 {
+			continue
+		}
+		n, err := strconv.Atoi(values[0])
+		if err != nil {
+			continue
+		}
+		key = strings.ToUpper(key)
+		switch key {
+		case "X-MBX-USED-WEIGHT-1M", "X-MBX-USED-WEIGHT":
+			rl.weight.roll(now)
+			rl.weight.used = n
+		case "X-MBX-ORDER-COUNT-10S":
+			rl.orders.roll(now)
+			rl.orders.used = n
+		case "X-MBX-ORDER-COUNT-1D":
+			rl.dailyOrders.roll(now)
+			rl.dailyOrders.used = n
+		}
+	}
+
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusTeapot {
+		backoff := rl.DefaultBackoff
+		if ra, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && ra > 0 {
+			backoff = time.Duration(ra) * time.Second
+		}
+		rl.openUntil = now.Add(backoff)
+		return
+	}
+	if wasProbing {
+		rl.openUntil = time.Time{}
+	}
+}
+
+// Release returns cost of the reservation for request which wasn't sent, e.g.
+// because it couldn't be signed, and ends the probe if the request was one.
+// Cost reserved in window which has rolled over since is already gone and
+// isn't returned again.
+func (rl *RateLimiter) Release(r *Reservation) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if r.probe {
+		rl.probing = false
+	}
+	now := rl.now()
+	for i, w := range r.windows {
+		w.roll(now)
+		if !w.start.Equal(r.starts[i]) {
+			continue
+		}
+		w.used -= r.costs[i]
+		if w.used < 0 {
+			w.used = 0
+		}
+	}
+}
+
+type limitWindow struct {
+	name     string
+	interval time.Duration
+	limit    int
+	start    time.Time
+	used     int
+}
+
+func (w *limitWindow) roll(now time.Time) {
+	start := now.Truncate(w.interval)
+	if !start.Equal(w.start) {
+		w.start = start
+		w.used = 0
+	}
+}
+
+func (w *limitWindow) allows(cost int) bool {
+	if w.limit == 0 {
+		return true
+	}
+	// request is allowed even if its cost alone exceeds the limit, otherwise it would block forever
+	return w.used == 0 || w.used+cost <= w.limit
+}
+
+func (w *limitWindow) resetIn(now time.Time) time.Duration {
+	return w.start.Add(w.interval).Sub(now)
+}
+
+// endpointWeights lists request weights of endpoints with fixed weight.
+var endpointWeights = map[string]int{
+	"GET api/v1/ping":                  1,
+	"GET api/v1/time":                  1,
+	"GET api/v1/aggTrades":             4,
+	"GET api/v1/trades":                1,
+	"GET api/v1/historicalTrades":      5,
+	"GET api/v1/klines":                2,
+	"GET api/v1/ticker/allPrices":      4,
+	"GET api/v1/ticker/allBookTickers": 4,
+	"GET api/v3/ticker/price":          1,
+	"GET api/v3/ticker/bookTicker":     1,
+	"GET api/v3/avgPrice":              1,
+	"GET api/v1/exchangeInfo":          10,
+	"POST api/v3/order":                1,
+	"POST api/v3/order/test":           1,
+	"GET api/v3/order":                 4,
+	"DELETE api/v3/order":              1,
+	"GET api/v3/allOrders":             20,
+	"GET api/v3/account":               20,
+	"GET api/v3/myTrades":              20,
+	"POST api/v1/userDataStream":       2,
+	"PUT api/v1/userDataStream":        2,
+	"DELETE api/v1/userDataStream":     2,
+}
+
+// RequestWeight returns request weight of the call to the endpoint with given params.
+func RequestWeight(method, endpoint string, params map[string]string) int {
+	switch method + " " + endpoint {
+	case "GET api/v1/depth":
+		limit, _ := strconv.Atoi(params["limit"])
+		switch {
+		case limit == 0 || limit <= 100:
+			return 5
+		case limit <= 500:
+			return 25
+		case limit <= 1000:
+			return 50
+		default:
+			return 250
+		}
+	case "GET api/v1/ticker/24hr":
+		if params["symbol"] == "" {
+			return 80
+		}
+		return 2
+	case "GET api/v3/openOrders":
+		if params["symbol"] == "" {
+			return 80
+		}
+		return 6
+	}
+	if w, ok := endpointWeights[method+" "+endpoint]; ok {
+		return w
+	}
+	return 1
+}
+
+func isOrderEndpoint(method, endpoint string) bool {
+	return method == "POST" && endpoint == "api/v3/order"
+}
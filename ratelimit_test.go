@@ -0,0 +1,161 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterFailFast(t *testing.T) {
+	rl := NewRateLimiter(RateLimits{RequestWeight: 10})
+	rl.FailFast = true
+	now := time.Date(2018, 1, 1, 10, 0, 30, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		if err := rl.Wait(context.Background(), "GET", "api/v1/klines", nil); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	err := rl.Wait(context.Background(), "GET", "api/v1/klines", nil)
+	rlErr, ok := err.(RateLimitError)
+	if !ok {
+		t.Fatalf("expected RateLimitError, got %T", err)
+	}
+	if rlErr.Limit != LimitRequestWeight || rlErr.RetryAfter != 30*time.Second {
+		t.Errorf("unexpected error: %s", rlErr)
+	}
+
+	now = now.Add(30 * time.Second)
+	if err := rl.Wait(context.Background(), "GET", "api/v1/klines", nil); err != nil {
+		t.Errorf("expected new window to allow request: %s", err)
+	}
+}
+
+func TestRateLimiterUsedWeightHeader(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-MBX-USED-WEIGHT-1M", "1199")
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	rl := NewRateLimiter(RateLimits{RequestWeight: 1200})
+	rl.FailFast = true
+	s := NewAPIService(ts.URL, "", nil, nil, nil, WithRateLimiter(rl))
+	if err := s.Ping(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rl.UsedWeight() != 1199 {
+		t.Errorf("used weight not taken from header: %d", rl.UsedWeight())
+	}
+	if _, err := s.Klines(context.Background(), KlinesRequest{Symbol: "BNBETH", Interval: Hour}); err == nil {
+		t.Errorf("expected request exceeding weight to fail")
+	}
+}
+
+func TestRateLimiterCircuit(t *testing.T) {
+	status := http.StatusTeapot
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"code":-1003,"msg":"Way too many requests; IP banned."}`))
+	}))
+	defer ts.Close()
+
+	rl := NewRateLimiter(DefaultRateLimits())
+	now := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+	s := NewAPIService(ts.URL, "", nil, nil, nil, WithRateLimiter(rl))
+
+	s.Ping(context.Background())
+	err := s.Ping(context.Background())
+	if rlErr, ok := err.(RateLimitError); !ok || rlErr.RetryAfter != 120*time.Second {
+		t.Fatalf("expected open circuit, got %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	status = http.StatusOK
+	if err := s.Ping(context.Background()); err != nil {
+		t.Fatalf("expected probe to pass: %s", err)
+	}
+	if err := s.Ping(context.Background()); err != nil {
+		t.Errorf("expected closed circuit: %s", err)
+	}
+}
+
+// probeSigner fails signing while refuse is set.
+type probeSigner struct {
+	refuse bool
+}
+
+func (ps *probeSigner) Sign(payload []byte) string {
+	return "signature"
+}
+
+func (ps *probeSigner) SignRequest(ctx context.Context, method, endpoint string, payload []byte) (string, error) {
+	if ps.refuse {
+		return "", errors.New("daemon unavailable")
+	}
+	return ps.Sign(payload), nil
+}
+
+func TestRateLimiterProbeSigningError(t *testing.T) {
+	status := http.StatusTeapot
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(status)
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	rl := NewRateLimiter(DefaultRateLimits())
+	now := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+	signer := &probeSigner{}
+	s := NewAPIService(ts.URL, "", signer, nil, nil, WithRateLimiter(rl), WithRetryPolicy(testRetryPolicy()))
+	s.Ping(context.Background())
+
+	now = now.Add(2 * time.Minute)
+	status = http.StatusOK
+	signer.refuse = true
+	used := rl.UsedWeight()
+	if _, err := s.Account(context.Background(), AccountRequest{}); err == nil {
+		t.Fatal("expected signing error")
+	}
+	if rl.UsedWeight() != used {
+		t.Errorf("expected weight of unsent probe released, used %d", rl.UsedWeight())
+	}
+	signer.refuse = false
+	if _, err := s.Account(context.Background(), AccountRequest{}); err != nil {
+		t.Errorf("expected next probe to pass: %s", err)
+	}
+}
+
+func TestRateLimiterReleaseAfterRollover(t *testing.T) {
+	rl := NewRateLimiter(RateLimits{RequestWeight: 10})
+	now := time.Date(2018, 1, 1, 10, 0, 50, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+
+	r, err := rl.Reserve(context.Background(), "GET", "api/v1/klines", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// weight reserved in the new window must not be released by old reservation
+	now = now.Add(20 * time.Second)
+	if err := rl.Wait(context.Background(), "GET", "api/v1/klines", nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rl.Release(r)
+	if used := rl.UsedWeight(); used != 2 {
+		t.Errorf("expected weight of new window kept, used %d", used)
+	}
+
+	r, _ = rl.Reserve(context.Background(), "GET", "api/v1/klines", nil)
+	rl.Release(r)
+	if used := rl.UsedWeight(); used != 2 {
+		t.Errorf("expected weight of current window released, used %d", used)
+	}
+}
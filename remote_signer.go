@@ -0,0 +1,171 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RequestSigner is implemented by signers which need to know what request they
+// sign, or which may fail. If Signer of the service implements RequestSigner,
+// SignRequest is used instead of Sign and the call fails with SigningError if
+// the payload couldn't be signed.
+type RequestSigner interface {
+	// SignRequest signs payload of the request and returns encoded string sum.
+	SignRequest(ctx context.Context, method, endpoint string, payload []byte) (string, error)
+}
+
+// SigningError is returned when the request couldn't be signed, e.g. because of
+// the policy of signing daemon. Request wasn't sent, so it isn't retried.
+type SigningError struct {
+	Reason string
+}
+
+// Error returns formatted error message.
+func (e SigningError) Error() string {
+	return fmt.Sprintf("unable to sign request: %s", e.Reason)
+}
+
+// RemoteSignerMethod is name of the RPC method served by signing daemon.
+const RemoteSignerMethod = "Signer.Sign"
+
+// SignArgs is request sent to signing daemon.
+type SignArgs struct {
+	ClientID string
+	Token    string
+	Method   string
+	Endpoint string
+	Payload  []byte
+}
+
+// SignReply is response of signing daemon.
+type SignReply struct {
+	Signature string
+}
+
+// RemoteSigner delegates signing to separate daemon over net/rpc, see package
+// signerd. API secret never enters memory of the process, the daemon signs only
+// requests allowed by policy of the client.
+type RemoteSigner struct {
+	// Network and Address of the daemon, e.g. "unix" and "/run/binance-signerd.sock".
+	Network string
+	Address string
+	// ClientID and Token identify the client to the daemon.
+	ClientID string
+	Token    string
+	// Timeout limits single signing call, zero means the call is limited only by context.
+	Timeout time.Duration
+
+	mu     sync.Mutex
+	client *rpc.Client
+}
+
+// NewRemoteSigner creates RemoteSigner connecting to the daemon listening on
+// unix socket. Connection is opened by first signing call.
+func NewRemoteSigner(socket, clientID, token string) *RemoteSigner {
+	return &RemoteSigner{
+		Network:  "unix",
+		Address:  socket,
+		ClientID: clientID,
+		Token:    token,
+		Timeout:  5 * time.Second,
+	}
+}
+
+// Sign signs provided payload and returns encoded string sum. It returns empty
+// string if the daemon refused to sign the payload, use SignRequest to get the reason.
+func (rs *RemoteSigner) Sign(payload []byte) string {
+	sig, err := rs.SignRequest(context.Background(), "", "", payload)
+	if err != nil {
+		return ""
+	}
+	return sig
+}
+
+// SignRequest asks the daemon to sign payload of the request. Broken connection
+// is opened again once.
+func (rs *RemoteSigner) SignRequest(ctx context.Context, method, endpoint string, payload []byte) (string, error) {
+	if rs.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, rs.Timeout)
+		defer cancel()
+	}
+	args := SignArgs{
+		ClientID: rs.ClientID,
+		Token:    rs.Token,
+		Method:   method,
+		Endpoint: endpoint,
+		Payload:  payload,
+	}
+	reply, err := rs.call(ctx, args)
+	if err == rpc.ErrShutdown || err == io.ErrUnexpectedEOF {
+		reply, err = rs.call(ctx, args)
+	}
+	if serr, ok := err.(rpc.ServerError); ok {
+		return "", SigningError{Reason: string(serr)}
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "remote signer call failed")
+	}
+	return reply.Signature, nil
+}
+
+func (rs *RemoteSigner) call(ctx context.Context, args SignArgs) (*SignReply, error) {
+	client, err := rs.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply := &SignReply{}
+	call := client.Go(RemoteSignerMethod, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-call.Done:
+	}
+	if call.Error == rpc.ErrShutdown || call.Error == io.ErrUnexpectedEOF {
+		rs.drop(client)
+	}
+	return reply, call.Error
+}
+
+func (rs *RemoteSigner) conn(ctx context.Context) (*rpc.Client, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.client != nil {
+		return rs.client, nil
+	}
+	var d net.Dialer
+	c, err := d.DialContext(ctx, rs.Network, rs.Address)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to connect to remote signer")
+	}
+	rs.client = rpc.NewClient(c)
+	return rs.client, nil
+}
+
+func (rs *RemoteSigner) drop(client *rpc.Client) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.client == client {
+		rs.client.Close()
+		rs.client = nil
+	}
+}
+
+// Close closes connection to the daemon.
+func (rs *RemoteSigner) Close() error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.client == nil {
+		return nil
+	}
+	err := rs.client.Close()
+	rs.client = nil
+	return err
+}
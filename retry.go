@@ -0,0 +1,111 @@
+package binance
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures retrying of failed REST calls.
+//
+// Call is retried when request fails on network error or server responds with
+// 5xx status code. Signed requests are stamped with fresh timestamp and signed
+// again for each attempt.
+type RetryPolicy struct {
+	// MaxAttempts is maximal number of attempts including the first one.
+	MaxAttempts int
+	// BaseDelay is delay before the second attempt, it doubles with each next attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts.
+	MaxDelay time.Duration
+	// Jitter is fraction of the delay which is randomized, value between 0 and 1.
+	Jitter float64
+	// Retryable decides whether the call can be safely retried. DefaultRetryable is used if nil.
+	Retryable func(method, endpoint string, params map[string]string) bool
+}
+
+// DefaultRetryPolicy returns policy with 3 attempts and exponential backoff starting at 200ms.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// DefaultRetryable considers GET requests and user stream keep-alive safe to
+// retry.
+//
+// New orders are not retried by the request itself. Instead, if NewOrder with
+// NewClientOrderID ends with unknown outcome, the order is queried by its client
+// order ID and sent again only if it doesn't exist.
+func DefaultRetryable(method, endpoint string, params map[string]string) bool {
+	switch {
+	case method == "GET":
+		return true
+	case method == "PUT" && endpoint == "api/v1/userDataStream":
+		return true
+	}
+	return false
+}
+
+func (rp *RetryPolicy) retryable(method, endpoint string, params map[string]string) bool {
+	if rp.Retryable != nil {
+		return rp.Retryable(method, endpoint, params)
+	}
+	return DefaultRetryable(method, endpoint, params)
+}
+
+// delay returns the delay before the next attempt, attempt is number of already executed attempts.
+func (rp *RetryPolicy) delay(attempt int) time.Duration {
+	d := float64(rp.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if rp.MaxDelay > 0 && d > float64(rp.MaxDelay) {
+		d = float64(rp.MaxDelay)
+	}
+	if rp.Jitter > 0 {
+		d = d * (1 - rp.Jitter + 2*rp.Jitter*rand.Float64())
+	}
+	return time.Duration(d)
+}
+
+// wait sleeps before the next attempt or returns error if ctx is done.
+func (rp *RetryPolicy) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(rp.delay(attempt))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// shouldRetry decides whether result of the attempt is transient failure.
+func shouldRetry(ctx context.Context, res *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		switch err.(type) {
+		case RateLimitError, SigningError:
+			return false
+		}
+		return true
+	}
+	return res.StatusCode >= 500
+}
+
+// unknownOutcome reports whether the error leaves it unknown if the request
+// was executed by the server.
+func unknownOutcome(err error) bool {
+	switch e := err.(type) {
+	case nil, RateLimitError, SigningError:
+		return false
+	case *Error:
+		return e.Is(ErrUnknownExecutionStatus)
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+	}
+}
+
+func TestRetryResignsRequest(t *testing.T) {
+	var signatures []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signatures = append(signatures, r.URL.Query().Get("signature"))
+		if len(signatures) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"balances":[]}`))
+	}))
+	defer ts.Close()
+
+	s := NewAPIService(ts.URL, "", &HmacSigner{Key: []byte("secret")}, nil, nil, WithRetryPolicy(testRetryPolicy()))
+	_, err := s.Account(context.Background(), AccountRequest{Timestamp: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(signatures) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(signatures))
+	}
+	if signatures[0] == signatures[1] || signatures[1] == signatures[2] {
+		t.Errorf("expected request to be signed again for each attempt")
+	}
+}
+
+func TestRetryNewOrderResolvesUnknownOutcome(t *testing.T) {
+	var posts int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			posts++
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"code":-1007,"msg":"Timeout waiting for response from backend server."}`))
+		case "GET":
+			if r.URL.Query().Get("origClientOrderId") != "myOrder" {
+				t.Errorf("order not queried by client order ID")
+			}
+			w.Write([]byte(`{"symbol":"BNBETH","orderId":42,"clientOrderId":"myOrder","price":"0.1",
+				"origQty":"1.0","executedQty":"0.0","status":"NEW","timeInForce":"GTC","type":"LIMIT",
+				"side":"BUY","stopPrice":"0.0","icebergQty":"0.0","time":1499827319559}`))
+		}
+	}))
+	defer ts.Close()
+
+	s := NewAPIService(ts.URL, "", &HmacSigner{Key: []byte("secret")}, nil, nil, WithRetryPolicy(testRetryPolicy()))
+	po, err := s.NewOrder(context.Background(), NewOrderRequest{
+		Symbol:           "BNBETH",
+		NewClientOrderID: "myOrder",
+		Timestamp:        time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if posts != 1 {
+		t.Errorf("order should be sent once, sent %d times", posts)
+	}
+	if po.OrderID != 42 {
+		t.Errorf("invalid order resolved: %#v", po)
+	}
+}
+
+func TestRetryNewOrderWithoutClientID(t *testing.T) {
+	var posts int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"code":-1007,"msg":"Timeout waiting for response from backend server."}`))
+	}))
+	defer ts.Close()
+
+	s := NewAPIService(ts.URL, "", &HmacSigner{Key: []byte("secret")}, nil, nil, WithRetryPolicy(testRetryPolicy()))
+	if _, err := s.NewOrder(context.Background(), NewOrderRequest{Symbol: "BNBETH", Timestamp: time.Now()}); err == nil {
+		t.Fatalf("expected error")
+	}
+	if posts != 1 {
+		t.Errorf("order without client order ID must not be retried, sent %d times", posts)
+	}
+}
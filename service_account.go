@@ -1,6 +1,7 @@
 package binance
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"strconv"
@@ -24,37 +25,83 @@ type rawExecutedOrder struct {
 	Time          float64 `json:"time"`
 }
 
-func (as *apiService) NewOrder(or NewOrderRequest) (*ProcessedOrder, error) {
+func (as *apiService) NewOrder(ctx context.Context, or NewOrderRequest) (*ProcessedOrder, error) {
 	params := make(map[string]string)
 	params["symbol"] = or.Symbol
 	params["side"] = string(or.Side)
 	params["type"] = string(or.Type)
 	params["timeInForce"] = string(or.TimeInForce)
-	params["quantity"] = strconv.FormatFloat(or.Quantity, 'f', 10, 64)
-	params["price"] = strconv.FormatFloat(or.Price, 'f', 10, 64)
-	params["timestamp"] = strconv.FormatInt(unixMillis(or.Timestamp), 10)
+	params["quantity"] = or.Quantity.String()
+	params["price"] = or.Price.String()
+	if !or.Timestamp.IsZero() {
+		params["timestamp"] = strconv.FormatInt(unixMillis(or.Timestamp), 10)
+	}
 	if or.NewClientOrderID != "" {
 		params["newClientOrderId"] = or.NewClientOrderID
 	}
-	if or.StopPrice != 0 {
-		params["stopPrice"] = strconv.FormatFloat(or.StopPrice, 'f', 10, 64)
+	if !or.StopPrice.IsZero() {
+		params["stopPrice"] = or.StopPrice.String()
+	}
+	if !or.IcebergQty.IsZero() {
+		params["icebergQty"] = or.IcebergQty.String()
 	}
-	if or.IcebergQty != 0 {
-		params["icebergQty"] = strconv.FormatFloat(or.IcebergQty, 'f', 10, 64)
+
+	po, err := as.placeOrder(ctx, params)
+	if err == nil || or.NewClientOrderID == "" || as.Retry == nil || ctx.Err() != nil || !unknownOutcome(err) {
+		return po, err
 	}
+	return as.resolveOrder(ctx, or, params, err)
+}
+
+// resolveOrder finds out whether order with unknown outcome was placed by
+// querying it by client order ID. If the order doesn't exist, it's sent again.
+func (as *apiService) resolveOrder(ctx context.Context, or NewOrderRequest, params map[string]string,
+	lastErr error) (*ProcessedOrder, error) {
+	for attempt := 1; attempt < as.Retry.MaxAttempts; attempt++ {
+		if err := as.Retry.wait(ctx, attempt); err != nil {
+			return nil, err
+		}
+		eo, err := as.QueryOrder(ctx, QueryOrderRequest{
+			Symbol:            or.Symbol,
+			OrigClientOrderID: or.NewClientOrderID,
+		})
+		if err == nil {
+			return &ProcessedOrder{
+				Symbol:        eo.Symbol,
+				OrderID:       int64(eo.OrderID),
+				ClientOrderID: eo.ClientOrderID,
+				TransactTime:  eo.Time,
+			}, nil
+		}
+		if bErr, ok := err.(*Error); !ok || !bErr.Is(ErrUnknownOrder) {
+			lastErr = err
+			continue
+		}
 
-	res, err := as.request("POST", "api/v3/order", params, true, true)
+		// order does not exist, it's safe to send it again
+		delete(params, "timestamp")
+		po, err := as.placeOrder(ctx, params)
+		if err == nil || ctx.Err() != nil || !unknownOutcome(err) {
+			return po, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func (as *apiService) placeOrder(ctx context.Context, params map[string]string) (*ProcessedOrder, error) {
+	res, err := as.request(ctx, "POST", "api/v3/order", params, true, true)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from Ticker/24hr")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return nil, as.handleError(textRes)
+		return nil, as.handleError("api/v3/order", res, textRes)
 	}
 
 	rawOrder := struct {
@@ -80,45 +127,49 @@ func (as *apiService) NewOrder(or NewOrderRequest) (*ProcessedOrder, error) {
 	}, nil
 }
 
-func (as *apiService) NewOrderTest(or NewOrderRequest) error {
+func (as *apiService) NewOrderTest(ctx context.Context, or NewOrderRequest) error {
 	params := make(map[string]string)
 	params["symbol"] = or.Symbol
 	params["side"] = string(or.Side)
 	params["type"] = string(or.Type)
 	params["timeInForce"] = string(or.TimeInForce)
-	params["quantity"] = strconv.FormatFloat(or.Quantity, 'f', 10, 64)
-	params["price"] = strconv.FormatFloat(or.Price, 'f', 10, 64)
-	params["timestamp"] = strconv.FormatInt(unixMillis(or.Timestamp), 10)
+	params["quantity"] = or.Quantity.String()
+	params["price"] = or.Price.String()
+	if !or.Timestamp.IsZero() {
+		params["timestamp"] = strconv.FormatInt(unixMillis(or.Timestamp), 10)
+	}
 	if or.NewClientOrderID != "" {
 		params["newClientOrderId"] = or.NewClientOrderID
 	}
-	if or.StopPrice != 0 {
-		params["stopPrice"] = strconv.FormatFloat(or.StopPrice, 'f', 10, 64)
+	if !or.StopPrice.IsZero() {
+		params["stopPrice"] = or.StopPrice.String()
 	}
-	if or.IcebergQty != 0 {
-		params["icebergQty"] = strconv.FormatFloat(or.IcebergQty, 'f', 10, 64)
+	if !or.IcebergQty.IsZero() {
+		params["icebergQty"] = or.IcebergQty.String()
 	}
 
-	res, err := as.request("POST", "api/v3/order/test", params, true, true)
+	res, err := as.request(ctx, "POST", "api/v3/order/test", params, true, true)
 	if err != nil {
 		return err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return errors.Wrap(err, "unable to read response from Ticker/24hr")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return as.handleError(textRes)
+		return as.handleError("api/v3/order/test", res, textRes)
 	}
 	return nil
 }
 
-func (as *apiService) QueryOrder(qor QueryOrderRequest) (*ExecutedOrder, error) {
+func (as *apiService) QueryOrder(ctx context.Context, qor QueryOrderRequest) (*ExecutedOrder, error) {
 	params := make(map[string]string)
 	params["symbol"] = qor.Symbol
-	params["timestamp"] = strconv.FormatInt(unixMillis(qor.Timestamp), 10)
+	if !qor.Timestamp.IsZero() {
+		params["timestamp"] = strconv.FormatInt(unixMillis(qor.Timestamp), 10)
+	}
 	if qor.OrderID != 0 {
 		params["orderId"] = strconv.FormatInt(qor.OrderID, 10)
 	}
@@ -129,18 +180,18 @@ func (as *apiService) QueryOrder(qor QueryOrderRequest) (*ExecutedOrder, error)
 		params["recvWindow"] = strconv.FormatInt(recvWindow(qor.RecvWindow), 10)
 	}
 
-	res, err := as.request("GET", "api/v3/order", params, true, true)
+	res, err := as.request(ctx, "GET", "api/v3/order", params, true, true)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from order.get")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return nil, as.handleError(textRes)
+		return nil, as.handleError("api/v3/order", res, textRes)
 	}
 
 	rawOrder := &rawExecutedOrder{}
@@ -155,10 +206,12 @@ func (as *apiService) QueryOrder(qor QueryOrderRequest) (*ExecutedOrder, error)
 	return eo, nil
 }
 
-func (as *apiService) CancelOrder(cor CancelOrderRequest) (*CanceledOrder, error) {
+func (as *apiService) CancelOrder(ctx context.Context, cor CancelOrderRequest) (*CanceledOrder, error) {
 	params := make(map[string]string)
 	params["symbol"] = cor.Symbol
-	params["timestamp"] = strconv.FormatInt(unixMillis(cor.Timestamp), 10)
+	if !cor.Timestamp.IsZero() {
+		params["timestamp"] = strconv.FormatInt(unixMillis(cor.Timestamp), 10)
+	}
 	if cor.OrderID != 0 {
 		params["orderId"] = strconv.FormatInt(cor.OrderID, 10)
 	}
@@ -172,18 +225,18 @@ func (as *apiService) CancelOrder(cor CancelOrderRequest) (*CanceledOrder, error
 		params["recvWindow"] = strconv.FormatInt(recvWindow(cor.RecvWindow), 10)
 	}
 
-	res, err := as.request("DELETE", "api/v3/order", params, true, true)
+	res, err := as.request(ctx, "DELETE", "api/v3/order", params, true, true)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from order.delete")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return nil, as.handleError(textRes)
+		return nil, as.handleError("api/v3/order", res, textRes)
 	}
 
 	rawCanceledOrder := struct {
@@ -204,26 +257,28 @@ func (as *apiService) CancelOrder(cor CancelOrderRequest) (*CanceledOrder, error
 	}, nil
 }
 
-func (as *apiService) OpenOrders(oor OpenOrdersRequest) ([]*ExecutedOrder, error) {
+func (as *apiService) OpenOrders(ctx context.Context, oor OpenOrdersRequest) ([]*ExecutedOrder, error) {
 	params := make(map[string]string)
 	params["symbol"] = oor.Symbol
-	params["timestamp"] = strconv.FormatInt(unixMillis(oor.Timestamp), 10)
+	if !oor.Timestamp.IsZero() {
+		params["timestamp"] = strconv.FormatInt(unixMillis(oor.Timestamp), 10)
+	}
 	if oor.RecvWindow != 0 {
 		params["recvWindow"] = strconv.FormatInt(recvWindow(oor.RecvWindow), 10)
 	}
 
-	res, err := as.request("GET", "api/v3/openOrders", params, true, true)
+	res, err := as.request(ctx, "GET", "api/v3/openOrders", params, true, true)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from openOrders.get")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return nil, as.handleError(textRes)
+		return nil, as.handleError("api/v3/openOrders", res, textRes)
 	}
 
 	rawOrders := []*rawExecutedOrder{}
@@ -243,10 +298,12 @@ func (as *apiService) OpenOrders(oor OpenOrdersRequest) ([]*ExecutedOrder, error
 	return eoc, nil
 }
 
-func (as *apiService) AllOrders(aor AllOrdersRequest) ([]*ExecutedOrder, error) {
+func (as *apiService) AllOrders(ctx context.Context, aor AllOrdersRequest) ([]*ExecutedOrder, error) {
 	params := make(map[string]string)
 	params["symbol"] = aor.Symbol
-	params["timestamp"] = strconv.FormatInt(unixMillis(aor.Timestamp), 10)
+	if !aor.Timestamp.IsZero() {
+		params["timestamp"] = strconv.FormatInt(unixMillis(aor.Timestamp), 10)
+	}
 	if aor.OrderID != 0 {
 		params["orderId"] = strconv.FormatInt(aor.OrderID, 10)
 	}
@@ -257,18 +314,18 @@ func (as *apiService) AllOrders(aor AllOrdersRequest) ([]*ExecutedOrder, error)
 		params["recvWindow"] = strconv.FormatInt(recvWindow(aor.RecvWindow), 10)
 	}
 
-	res, err := as.request("GET", "api/v3/allOrders", params, true, true)
+	res, err := as.request(ctx, "GET", "api/v3/allOrders", params, true, true)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from allOrders.get")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return nil, as.handleError(textRes)
+		return nil, as.handleError("api/v3/allOrders", res, textRes)
 	}
 
 	rawOrders := []*rawExecutedOrder{}
@@ -288,29 +345,31 @@ func (as *apiService) AllOrders(aor AllOrdersRequest) ([]*ExecutedOrder, error)
 	return eoc, nil
 }
 
-func (as *apiService) Account(ar AccountRequest) (*Account, error) {
+func (as *apiService) Account(ctx context.Context, ar AccountRequest) (*Account, error) {
 	params := make(map[string]string)
-	params["timestamp"] = strconv.FormatInt(unixMillis(ar.Timestamp), 10)
+	if !ar.Timestamp.IsZero() {
+		params["timestamp"] = strconv.FormatInt(unixMillis(ar.Timestamp), 10)
+	}
 	if ar.RecvWindow != 0 {
 		params["recvWindow"] = strconv.FormatInt(recvWindow(ar.RecvWindow), 10)
 	}
 
-	res, err := as.request("GET", "api/v3/account", params, true, true)
+	res, err := as.request(ctx, "GET", "api/v3/account", params, true, true)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from account.get")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return nil, as.handleError(textRes)
+		return nil, as.handleError("api/v3/account", res, textRes)
 	}
 
 	rawAccount := struct {
-		MakerCommision   int64 `json:"makerCommision"`
+		MakerCommission  int64 `json:"makerCommission"`
 		TakerCommission  int64 `json:"takerCommission"`
 		BuyerCommission  int64 `json:"buyerCommission"`
 		SellerCommission int64 `json:"sellerCommission"`
@@ -328,7 +387,7 @@ func (as *apiService) Account(ar AccountRequest) (*Account, error) {
 	}
 
 	acc := &Account{
-		MakerCommision:  rawAccount.MakerCommision,
+		MakerCommision:  rawAccount.MakerCommission,
 		TakerCommision:  rawAccount.TakerCommission,
 		BuyerCommision:  rawAccount.BuyerCommission,
 		SellerCommision: rawAccount.SellerCommission,
@@ -337,11 +396,11 @@ func (as *apiService) Account(ar AccountRequest) (*Account, error) {
 		CanDeposit:      rawAccount.CanDeposit,
 	}
 	for _, b := range rawAccount.Balances {
-		f, err := floatFromString(b.Free)
+		f, err := decimalFromString(b.Free)
 		if err != nil {
 			return nil, err
 		}
-		l, err := floatFromString(b.Locked)
+		l, err := decimalFromString(b.Locked)
 		if err != nil {
 			return nil, err
 		}
@@ -355,32 +414,34 @@ func (as *apiService) Account(ar AccountRequest) (*Account, error) {
 	return acc, nil
 }
 
-func (as *apiService) MyTrades(mtr MyTradesRequest) ([]*Trade, error) {
+func (as *apiService) MyTrades(ctx context.Context, mtr MyTradesRequest) ([]*Trade, error) {
 	params := make(map[string]string)
 	params["symbol"] = mtr.Symbol
-	params["timestamp"] = strconv.FormatInt(unixMillis(mtr.Timestamp), 10)
+	if !mtr.Timestamp.IsZero() {
+		params["timestamp"] = strconv.FormatInt(unixMillis(mtr.Timestamp), 10)
+	}
 	if mtr.RecvWindow != 0 {
 		params["recvWindow"] = strconv.FormatInt(recvWindow(mtr.RecvWindow), 10)
 	}
 	if mtr.FromID != 0 {
-		params["orderId"] = strconv.FormatInt(mtr.FromID, 10)
+		params["fromId"] = strconv.FormatInt(mtr.FromID, 10)
 	}
 	if mtr.Limit != 0 {
 		params["limit"] = strconv.Itoa(mtr.Limit)
 	}
 
-	res, err := as.request("GET", "api/v3/myTrades", params, true, true)
+	res, err := as.request(ctx, "GET", "api/v3/myTrades", params, true, true)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from myTrades.get")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return nil, as.handleError(textRes)
+		return nil, as.handleError("api/v3/myTrades", res, textRes)
 	}
 
 	rawTrades := []struct {
@@ -400,15 +461,15 @@ func (as *apiService) MyTrades(mtr MyTradesRequest) ([]*Trade, error) {
 
 	var tc []*Trade
 	for _, rt := range rawTrades {
-		price, err := floatFromString(rt.Price)
+		price, err := decimalFromString(rt.Price)
 		if err != nil {
 			return nil, err
 		}
-		qty, err := floatFromString(rt.Qty)
+		qty, err := decimalFromString(rt.Qty)
 		if err != nil {
 			return nil, err
 		}
-		commission, err := floatFromString(rt.Commission)
+		commission, err := decimalFromString(rt.Commission)
 		if err != nil {
 			return nil, err
 		}
@@ -431,12 +492,14 @@ func (as *apiService) MyTrades(mtr MyTradesRequest) ([]*Trade, error) {
 	return tc, nil
 }
 
-func (as *apiService) Withdraw(wr WithdrawRequest) (*WithdrawResult, error) {
+func (as *apiService) Withdraw(ctx context.Context, wr WithdrawRequest) (*WithdrawResult, error) {
 	params := make(map[string]string)
 	params["asset"] = wr.Asset
 	params["address"] = wr.Address
-	params["amount"] = strconv.FormatFloat(wr.Amount, 'f', 10, 64)
-	params["timestamp"] = strconv.FormatInt(unixMillis(wr.Timestamp), 10)
+	params["amount"] = wr.Amount.String()
+	if !wr.Timestamp.IsZero() {
+		params["timestamp"] = strconv.FormatInt(unixMillis(wr.Timestamp), 10)
+	}
 	if wr.RecvWindow != 0 {
 		params["recvWindow"] = strconv.FormatInt(recvWindow(wr.RecvWindow), 10)
 	}
@@ -444,18 +507,18 @@ func (as *apiService) Withdraw(wr WithdrawRequest) (*WithdrawResult, error) {
 		params["name"] = wr.Name
 	}
 
-	res, err := as.request("POST", "wapi/v1/withdraw.html", params, true, true)
+	res, err := as.request(ctx, "POST", "wapi/v1/withdraw.html", params, true, true)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from withdraw.post")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return nil, as.handleError(textRes)
+		return nil, as.handleError("wapi/v1/withdraw.html", res, textRes)
 	}
 
 	rawResult := struct {
@@ -471,9 +534,11 @@ func (as *apiService) Withdraw(wr WithdrawRequest) (*WithdrawResult, error) {
 		Success: rawResult.Success,
 	}, nil
 }
-func (as *apiService) DepositHistory(hr HistoryRequest) ([]*Deposit, error) {
+func (as *apiService) DepositHistory(ctx context.Context, hr HistoryRequest) ([]*Deposit, error) {
 	params := make(map[string]string)
-	params["timestamp"] = strconv.FormatInt(unixMillis(hr.Timestamp), 10)
+	if !hr.Timestamp.IsZero() {
+		params["timestamp"] = strconv.FormatInt(unixMillis(hr.Timestamp), 10)
+	}
 	if hr.Asset != "" {
 		params["asset"] = hr.Asset
 	}
@@ -484,30 +549,30 @@ func (as *apiService) DepositHistory(hr HistoryRequest) ([]*Deposit, error) {
 		params["startTime"] = strconv.FormatInt(unixMillis(hr.StartTime), 10)
 	}
 	if !hr.EndTime.IsZero() {
-		params["startTime"] = strconv.FormatInt(unixMillis(hr.EndTime), 10)
+		params["endTime"] = strconv.FormatInt(unixMillis(hr.EndTime), 10)
 	}
 	if hr.RecvWindow != 0 {
 		params["recvWindow"] = strconv.FormatInt(recvWindow(hr.RecvWindow), 10)
 	}
 
-	res, err := as.request("POST", "wapi/v1/getDepositHistory.html", params, true, true)
+	res, err := as.request(ctx, "POST", "wapi/v1/getDepositHistory.html", params, true, true)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from depositHistory.post")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return nil, as.handleError(textRes)
+		return nil, as.handleError("wapi/v1/getDepositHistory.html", res, textRes)
 	}
 
 	rawDepositHistory := struct {
 		DepositList []struct {
 			InsertTime float64 `json:"insertTime"`
-			Amount     float64 `json:"amount"`
+			Amount     Decimal `json:"amount"`
 			Asset      string  `json:"asset"`
 			Status     int     `json:"status"`
 		}
@@ -533,9 +598,11 @@ func (as *apiService) DepositHistory(hr HistoryRequest) ([]*Deposit, error) {
 
 	return dc, nil
 }
-func (as *apiService) WithdrawHistory(hr HistoryRequest) ([]*Withdrawal, error) {
+func (as *apiService) WithdrawHistory(ctx context.Context, hr HistoryRequest) ([]*Withdrawal, error) {
 	params := make(map[string]string)
-	params["timestamp"] = strconv.FormatInt(unixMillis(hr.Timestamp), 10)
+	if !hr.Timestamp.IsZero() {
+		params["timestamp"] = strconv.FormatInt(unixMillis(hr.Timestamp), 10)
+	}
 	if hr.Asset != "" {
 		params["asset"] = hr.Asset
 	}
@@ -546,33 +613,33 @@ func (as *apiService) WithdrawHistory(hr HistoryRequest) ([]*Withdrawal, error)
 		params["startTime"] = strconv.FormatInt(unixMillis(hr.StartTime), 10)
 	}
 	if !hr.EndTime.IsZero() {
-		params["startTime"] = strconv.FormatInt(unixMillis(hr.EndTime), 10)
+		params["endTime"] = strconv.FormatInt(unixMillis(hr.EndTime), 10)
 	}
 	if hr.RecvWindow != 0 {
 		params["recvWindow"] = strconv.FormatInt(recvWindow(hr.RecvWindow), 10)
 	}
 
-	res, err := as.request("POST", "wapi/v1/getWithdrawHistory.html", params, true, true)
+	res, err := as.request(ctx, "POST", "wapi/v1/getWithdrawHistory.html", params, true, true)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from withdrawHistory.post")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return nil, as.handleError(textRes)
+		return nil, as.handleError("wapi/v1/getWithdrawHistory.html", res, textRes)
 	}
 
 	rawWithdrawHistory := struct {
 		WithdrawList []struct {
-			Amount    float64 `json:"amount"`
+			Amount    Decimal `json:"amount"`
 			Address   string  `json:"address"`
 			TxID      string  `json:"txId"`
 			Asset     string  `json:"asset"`
-			ApplyTime float64 `json:"insertTime"`
+			ApplyTime float64 `json:"applyTime"`
 			Status    int     `json:"status"`
 		}
 		Success bool `json:"success"`
@@ -601,23 +668,23 @@ func (as *apiService) WithdrawHistory(hr HistoryRequest) ([]*Withdrawal, error)
 }
 
 func executedOrderFromRaw(reo *rawExecutedOrder) (*ExecutedOrder, error) {
-	price, err := strconv.ParseFloat(reo.Price, 64)
+	price, err := ParseDecimal(reo.Price)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Order.CloseTime")
 	}
-	origQty, err := strconv.ParseFloat(reo.OrigQty, 64)
+	origQty, err := ParseDecimal(reo.OrigQty)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Order.OrigQty")
 	}
-	execQty, err := strconv.ParseFloat(reo.ExecutedQty, 64)
+	execQty, err := ParseDecimal(reo.ExecutedQty)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Order.ExecutedQty")
 	}
-	stopPrice, err := strconv.ParseFloat(reo.StopPrice, 64)
+	stopPrice, err := ParseDecimal(reo.StopPrice)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Order.StopPrice")
 	}
-	icebergQty, err := strconv.ParseFloat(reo.IcebergQty, 64)
+	icebergQty, err := ParseDecimal(reo.IcebergQty)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Order.IcebergQty")
 	}
@@ -0,0 +1,67 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// accountServer responds to every request by body and records its query.
+func accountServer(t *testing.T, body string) (Service, *url.Values) {
+	query := &url.Values{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*query = r.URL.Query()
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(ts.Close)
+	return NewAPIService(ts.URL, "key", &HmacSigner{Key: []byte("secret")}, nil, nil), query
+}
+
+func TestAccountCommissions(t *testing.T) {
+	as, _ := accountServer(t, `{"makerCommission":15,"takerCommission":15,"buyerCommission":0,"sellerCommission":0,
+		"canTrade":true,"canWithdraw":true,"canDeposit":true,"balances":[]}`)
+	a, err := as.Account(context.Background(), AccountRequest{})
+	if err != nil || a.MakerCommision != 15 || a.TakerCommision != 15 {
+		t.Errorf("unexpected account %+v: %v", a, err)
+	}
+}
+
+func TestMyTradesFromID(t *testing.T) {
+	as, query := accountServer(t, `[]`)
+	if _, err := as.MyTrades(context.Background(), MyTradesRequest{Symbol: "LTCBTC", FromID: 28457}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query.Get("fromId") != "28457" || query.Get("orderId") != "" {
+		t.Errorf("unexpected query %v", *query)
+	}
+}
+
+func TestHistoryEndTime(t *testing.T) {
+	start := time.Unix(1508198532, 0)
+	end := start.Add(time.Hour)
+	hr := HistoryRequest{Asset: "ETH", StartTime: start, EndTime: end}
+
+	as, query := accountServer(t, `{"depositList":[],"success":true}`)
+	if _, err := as.DepositHistory(context.Background(), hr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query.Get("startTime") != "1508198532000" || query.Get("endTime") != "1508202132000" {
+		t.Errorf("unexpected deposit history query %v", *query)
+	}
+
+	as, query = accountServer(t, `{"withdrawList":[{"amount":1,"address":"0x6915f16f8791d0a1cc2bf47c13a6b2a92000504b",
+		"asset":"ETH","applyTime":1508198532000,"status":4}],"success":true}`)
+	withdrawals, err := as.WithdrawHistory(context.Background(), hr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query.Get("startTime") != "1508198532000" || query.Get("endTime") != "1508202132000" {
+		t.Errorf("unexpected withdraw history query %v", *query)
+	}
+	if len(withdrawals) != 1 || !withdrawals[0].ApplyTime.Equal(start) {
+		t.Errorf("unexpected withdrawals %+v", withdrawals)
+	}
+}
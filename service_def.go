@@ -1,51 +1,65 @@
 package binance
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/go-kit/kit/log"
-	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 )
 
 // Service represents service layer for Binance API.
 //
 // The main purpose for this layer is to be replaced with dummy implementation
-// if necessary without need to replace Binance instance.
+// if necessary without need to replace Binance instance. Package binancetest
+// provides in-memory FakeService for tests.
 type Service interface {
-	Ping() error
-	Time() (time.Time, error)
-	OrderBook(obr OrderBookRequest) (*OrderBook, error)
-	AggTrades(atr AggTradesRequest) ([]*AggTrade, error)
-	Klines(kr KlinesRequest) ([]*Kline, error)
-	Ticker24(tr TickerRequest) (*Ticker24, error)
-	TickerAllPrices() ([]*PriceTicker, error)
-	TickerAllBooks() ([]*BookTicker, error)
-
-	NewOrder(or NewOrderRequest) (*ProcessedOrder, error)
-	NewOrderTest(or NewOrderRequest) error
-	QueryOrder(qor QueryOrderRequest) (*ExecutedOrder, error)
-	CancelOrder(cor CancelOrderRequest) (*CanceledOrder, error)
-	OpenOrders(oor OpenOrdersRequest) ([]*ExecutedOrder, error)
-	AllOrders(aor AllOrdersRequest) ([]*ExecutedOrder, error)
-
-	Account(ar AccountRequest) (*Account, error)
-	MyTrades(mtr MyTradesRequest) ([]*Trade, error)
-	Withdraw(wr WithdrawRequest) (*WithdrawResult, error)
-	DepositHistory(hr HistoryRequest) ([]*Deposit, error)
-	WithdrawHistory(hr HistoryRequest) ([]*Withdrawal, error)
-
-	StartUserDataStream() (*Stream, error)
-	KeepAliveUserDataStream(s *Stream) error
-	CloseUserDataStream(s *Stream) error
-
-	DepthWebsocket(dwr DepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error)
-	KlineWebsocket(kwr KlineWebsocketRequest) (chan *KlineEvent, chan struct{}, error)
-	TradeWebsocket(twr TradeWebsocketRequest) (chan *AggTradeEvent, chan struct{}, error)
-	UserDataWebsocket(udwr UserDataWebsocketRequest) (chan *AccountEvent, chan struct{}, error)
+	Ping(ctx context.Context) error
+	Time(ctx context.Context) (time.Time, error)
+	OrderBook(ctx context.Context, obr OrderBookRequest) (*OrderBook, error)
+	AggTrades(ctx context.Context, atr AggTradesRequest) ([]*AggTrade, error)
+	Trades(ctx context.Context, tr TradesRequest) ([]*MarketTrade, error)
+	HistoricalTrades(ctx context.Context, htr HistoricalTradesRequest) ([]*MarketTrade, error)
+	Klines(ctx context.Context, kr KlinesRequest) ([]*Kline, error)
+	Ticker24(ctx context.Context, tr TickerRequest) (*Ticker24, error)
+	TickerAll24(ctx context.Context) ([]*Ticker24, error)
+	TickerPrice(ctx context.Context, tr TickerRequest) (*PriceTicker, error)
+	TickerAllPrices(ctx context.Context) ([]*PriceTicker, error)
+	TickerBook(ctx context.Context, tr TickerRequest) (*BookTicker, error)
+	TickerAllBooks(ctx context.Context) ([]*BookTicker, error)
+	AvgPrice(ctx context.Context, apr AvgPriceRequest) (*AvgPrice, error)
+	ExchangeInfo(ctx context.Context) (*ExchangeInfo, error)
+
+	NewOrder(ctx context.Context, or NewOrderRequest) (*ProcessedOrder, error)
+	NewOrderTest(ctx context.Context, or NewOrderRequest) error
+	QueryOrder(ctx context.Context, qor QueryOrderRequest) (*ExecutedOrder, error)
+	CancelOrder(ctx context.Context, cor CancelOrderRequest) (*CanceledOrder, error)
+	OpenOrders(ctx context.Context, oor OpenOrdersRequest) ([]*ExecutedOrder, error)
+	AllOrders(ctx context.Context, aor AllOrdersRequest) ([]*ExecutedOrder, error)
+
+	Account(ctx context.Context, ar AccountRequest) (*Account, error)
+	MyTrades(ctx context.Context, mtr MyTradesRequest) ([]*Trade, error)
+	Withdraw(ctx context.Context, wr WithdrawRequest) (*WithdrawResult, error)
+	DepositHistory(ctx context.Context, hr HistoryRequest) ([]*Deposit, error)
+	WithdrawHistory(ctx context.Context, hr HistoryRequest) ([]*Withdrawal, error)
+
+	StartUserDataStream(ctx context.Context) (*Stream, error)
+	KeepAliveUserDataStream(ctx context.Context, s *Stream) error
+	CloseUserDataStream(ctx context.Context, s *Stream) error
+
+	DepthWebsocket(ctx context.Context, dwr DepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error)
+	CombinedDepthWebsocket(ctx context.Context, cdwr CombinedDepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error)
+	KlineWebsocket(ctx context.Context, kwr KlineWebsocketRequest) (chan *KlineEvent, chan struct{}, error)
+	TradeWebsocket(ctx context.Context, twr TradeWebsocketRequest) (chan *AggTradeEvent, chan struct{}, error)
+	UserDataWebsocket(ctx context.Context, udwr UserDataWebsocketRequest) (chan *AccountEvent, chan struct{}, error)
 }
 
 type apiService struct {
@@ -54,41 +68,241 @@ type apiService struct {
 	Signer Signer
 	Logger log.Logger
 	Ctx    context.Context
+	Client *http.Client
+
+	StreamURL string
+
+	Limiter *RateLimiter
+	Retry   *RetryPolicy
+
+	Clock        *Clock
+	RecvWindow   time.Duration
+	syncInterval time.Duration
+
+	responseHooks []ResponseHook
+
+	Metrics *Metrics
+	streams streamTracker
+
+	logPolicy *LogPolicy
+	redactor  log.Logger
+}
+
+// ServiceOption sets optional configuration of Service created by NewAPIService.
+type ServiceOption func(*apiService)
+
+// WithHTTPClient sets HTTP client used for all REST calls.
+//
+// Client should be shared across services talking to the same host so the
+// opened connections are reused. See NewHTTPClient for tuned default.
+func WithHTTPClient(client *http.Client) ServiceOption {
+	return func(as *apiService) {
+		as.Client = client
+	}
+}
+
+// WithRateLimiter sets limiter throttling all REST calls of the service.
+//
+// The same limiter should be used by all services sharing IP address.
+func WithRateLimiter(limiter *RateLimiter) ServiceOption {
+	return func(as *apiService) {
+		as.Limiter = limiter
+	}
+}
+
+// WithRetryPolicy enables retrying of failed calls according to the policy.
+func WithRetryPolicy(policy RetryPolicy) ServiceOption {
+	return func(as *apiService) {
+		as.Retry = &policy
+	}
+}
+
+// WithClock sets clock used to stamp signed requests with server time.
+//
+// Requests with zero Timestamp are stamped automatically. Request rejected
+// because of timestamp outside of recvWindow (-1021) is sent again once after
+// the clock is synchronized.
+func WithClock(clock *Clock) ServiceOption {
+	return func(as *apiService) {
+		as.Clock = clock
+	}
+}
+
+// WithTimeSync creates clock synchronized with the server time of the service
+// every interval until service context is done. See WithClock.
+func WithTimeSync(interval time.Duration) ServiceOption {
+	return func(as *apiService) {
+		as.syncInterval = interval
+	}
+}
+
+// WithRecvWindow sets recvWindow used for signed requests which don't set their own.
+func WithRecvWindow(d time.Duration) ServiceOption {
+	return func(as *apiService) {
+		as.RecvWindow = d
+	}
 }
 
 // NewAPIService creates instance of Service.
 //
+// If url is empty, REST API of Production environment is used. Streams connect to
+// Production unless the environment is set by WithEnvironment.
 // If logger or ctx are not provided, NopLogger and Background context are used as default.
 // You can use context for one-time request cancel (e.g. when shutting down the app).
-func NewAPIService(url, apiKey string, signer Signer, logger log.Logger, ctx context.Context) Service {
+// If HTTP client is not provided via WithHTTPClient, client with DefaultHTTPClientConfig
+// is created once and reused by all calls of the service.
+func NewAPIService(url, apiKey string, signer Signer, logger log.Logger, ctx context.Context,
+	opts ...ServiceOption) Service {
 	if logger == nil {
 		logger = log.NewNopLogger()
 	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	return &apiService{
+	as := &apiService{
 		URL:    url,
 		APIKey: apiKey,
 		Signer: signer,
 		Logger: logger,
 		Ctx:    ctx,
 	}
+	for _, opt := range opts {
+		opt(as)
+	}
+	if as.URL == "" {
+		as.URL = Production.RESTURL
+	}
+	if as.StreamURL == "" {
+		as.StreamURL = Production.StreamURL
+	}
+	if as.Client == nil {
+		as.Client = NewHTTPClient(DefaultHTTPClientConfig())
+	}
+	if as.logPolicy == nil {
+		policy := DefaultLogPolicy()
+		as.logPolicy = &policy
+	}
+	as.logPolicy.literals = append(as.logPolicy.literals, as.APIKey)
+	as.redactor = RedactingLogger(as.Logger, *as.logPolicy)
+	if as.Metrics == nil {
+		as.Metrics = NopMetrics()
+	}
+	as.Metrics.setDefaults()
+	as.responseHooks = append(as.responseHooks, as.Metrics.observeResponse)
+	if as.syncInterval > 0 && as.Clock == nil {
+		as.Clock = NewClock(as, logger)
+		go as.Clock.Run(as.Ctx, as.syncInterval)
+	}
+	return as
+}
+
+// context returns context of single call. It's done when either provided ctx
+// or the service context is done. Returned cancel func has to be called once
+// the call is finished.
+func (as *apiService) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(as.Ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
 }
 
-func (as *apiService) request(method string, endpoint string, params map[string]string,
+func (as *apiService) request(ctx context.Context, method string, endpoint string, params map[string]string,
 	apiKey bool, sign bool) (*http.Response, error) {
-	transport := &http.Transport{}
-	client := &http.Client{
-		Transport: transport,
+	ctx, cancel := as.context(ctx)
+
+	retryable := as.Retry != nil && as.Retry.retryable(method, endpoint, params)
+	resynced := false
+	for attempt := 1; ; attempt++ {
+		resp, err := as.send(ctx, attempt, attempt > 1 || resynced, method, endpoint, params, apiKey, sign)
+		if err == nil && sign && as.Clock != nil && !resynced && resp.StatusCode == http.StatusBadRequest {
+			var timestampErr bool
+			resp, timestampErr = peekTimestampError(resp)
+			if timestampErr {
+				resp.Body.Close()
+				as.log(LogRequests, LogInfo).Log("resync", endpoint)
+				if err := as.Clock.Sync(ctx); err != nil {
+					as.log(LogRequests, LogErrors).Log("clockSync", err)
+				}
+				resynced = true
+				attempt--
+				continue
+			}
+		}
+		if !retryable || attempt >= as.Retry.MaxAttempts || !shouldRetry(ctx, resp, err) {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+			as.log(LogRequests, LogInfo).Log("retry", endpoint, "attempt", attempt, "status", resp.StatusCode)
+		} else {
+			as.log(LogRequests, LogInfo).Log("retry", endpoint, "attempt", attempt, "err", err)
+		}
+		if err := as.Retry.wait(ctx, attempt); err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+}
+
+// send executes single attempt of the request. Signed requests without
+// timestamp are stamped with current time, restamp forces new timestamp so
+// the repeated request isn't rejected.
+func (as *apiService) send(ctx context.Context, attempt int, restamp bool, method string, endpoint string,
+	params map[string]string, apiKey bool, sign bool) (*http.Response, error) {
+	// wait before the request is signed so the timestamp isn't stale
+	var reservation *Reservation
+	if as.Limiter != nil {
+		r, err := as.Limiter.Reserve(ctx, method, endpoint, params)
+		if err != nil {
+			return nil, err
+		}
+		reservation = r
 	}
 
-	url := fmt.Sprintf("%s/%s", as.URL, endpoint)
-	req, err := http.NewRequest(method, url, nil)
+	req, err := as.newRequest(ctx, restamp, method, endpoint, params, apiKey, sign)
+	if err != nil {
+		if reservation != nil {
+			as.Limiter.Release(reservation)
+		}
+		return nil, err
+	}
+	as.log(LogRequests, LogDebug).Log("method", method, "endpoint", endpoint, "queryString", req.URL.RawQuery)
+
+	start := time.Now()
+	resp, err := as.Client.Do(req)
+	if as.Limiter != nil {
+		as.Limiter.Update(resp)
+	}
+	as.reportResponse(ctx, &ResponseMetadata{
+		Method:   method,
+		Endpoint: endpoint,
+		Attempt:  attempt,
+		Duration: time.Since(start),
+		Weight:   RequestWeight(method, endpoint, params),
+		Err:      err,
+	}, resp)
+	return resp, err
+}
+
+// newRequest builds the request and signs it if sign is set.
+func (as *apiService) newRequest(ctx context.Context, restamp bool, method string, endpoint string,
+	params map[string]string, apiKey bool, sign bool) (*http.Request, error) {
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/%s", as.URL, endpoint), nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to create request")
 	}
-	req.WithContext(as.Ctx)
+	req = req.WithContext(ctx)
 
 	q := req.URL.Query()
 	for key, val := range params {
@@ -98,15 +312,71 @@ func (as *apiService) request(method string, endpoint string, params map[string]
 		req.Header.Add("X-MBX-APIKEY", as.APIKey)
 	}
 	if sign {
-		level.Debug(as.Logger).Log("queryString", q.Encode())
-		q.Add("signature", as.Signer.Sign([]byte(q.Encode())))
-		level.Debug(as.Logger).Log("signature", as.Signer.Sign([]byte(q.Encode())))
+		if restamp || q.Get("timestamp") == "" {
+			q.Set("timestamp", strconv.FormatInt(unixMillis(as.now()), 10))
+		}
+		if as.RecvWindow != 0 && q.Get("recvWindow") == "" {
+			q.Set("recvWindow", strconv.FormatInt(recvWindow(as.RecvWindow), 10))
+		}
+		// signature has to follow the signed payload, base64 signatures need escaping
+		payload := q.Encode()
+		signature, err := as.sign(ctx, method, endpoint, []byte(payload))
+		if err != nil {
+			if _, ok := err.(SigningError); !ok {
+				err = SigningError{Reason: err.Error()}
+			}
+			return nil, err
+		}
+		req.URL.RawQuery = payload + "&signature=" + url.QueryEscape(signature)
+	} else {
+		req.URL.RawQuery = q.Encode()
 	}
-	req.URL.RawQuery = q.Encode()
+	return req, nil
+}
 
-	resp, err := client.Do(req)
+// sign signs payload of the request, using RequestSigner if the signer implements it.
+func (as *apiService) sign(ctx context.Context, method, endpoint string, payload []byte) (string, error) {
+	if rs, ok := as.Signer.(RequestSigner); ok {
+		return rs.SignRequest(ctx, method, endpoint, payload)
+	}
+	if es, ok := as.Signer.(ErrSigner); ok {
+		return es.SignErr(payload)
+	}
+	return as.Signer.Sign(payload), nil
+}
+
+// now returns current time, adjusted to server time if the clock is set.
+func (as *apiService) now() time.Time {
+	if as.Clock != nil {
+		return as.Clock.Now()
+	}
+	return time.Now()
+}
+
+// peekTimestampError checks whether response contains -1021 error. Response
+// is returned with body which can be read again.
+func peekTimestampError(resp *http.Response) (*http.Response, bool) {
+	textRes, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(textRes))
 	if err != nil {
-		return nil, err
+		return resp, false
+	}
+	bErr := &Error{}
+	if err := json.Unmarshal(textRes, bErr); err != nil {
+		return resp, false
 	}
-	return resp, nil
+	return resp, bErr.Is(ErrTimestampOutOfWindow)
+}
+
+// cancelBody releases context of the call once the response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (cb *cancelBody) Close() error {
+	err := cb.ReadCloser.Close()
+	cb.cancel()
+	return err
 }
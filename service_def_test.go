@@ -1,12 +1,17 @@
 package binance
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestErrorHandler(t *testing.T) {
-	as := NewAPIService("", "", nil, nil).(*apiService)
-	err := as.handleError([]byte(`{"code":-1105,"msg":"Parameter 'side' was was empty."}`))
+	as := NewAPIService("", "", nil, nil, nil).(*apiService)
+	res := &http.Response{StatusCode: http.StatusBadRequest}
+	err := as.handleError("api/v3/order", res, []byte(`{"code":-1105,"msg":"Parameter 'side' was was empty."}`))
 	tErr, ok := err.(*Error)
 	if !ok {
 		t.Errorf("invalid type of error returned: %T", tErr)
@@ -18,3 +23,35 @@ func TestErrorHandler(t *testing.T) {
 		t.Errorf("invalid error message extracted")
 	}
 }
+
+func TestRequestContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	s := NewAPIService(ts.URL, "", nil, nil, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Ping(ctx); err == nil {
+		t.Errorf("expected error when call context is done")
+	}
+}
+
+func TestRequestServiceContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewAPIService(ts.URL, "", nil, nil, ctx)
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if err := s.Ping(context.Background()); err == nil {
+		t.Errorf("expected error when service context is done")
+	}
+}
@@ -1,8 +1,8 @@
 package binance
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"strconv"
 	"time"
@@ -10,58 +10,71 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (as *apiService) Ping() error {
+func (as *apiService) Ping(ctx context.Context) error {
 	params := make(map[string]string)
-	response, err := as.request("GET", "api/v1/ping", params, false, false)
+	res, err := as.request(ctx, "GET", "api/v1/ping", params, false, false)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("%#v\n", response.StatusCode)
+	defer res.Body.Close()
+	textRes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return errors.Wrap(err, "unable to read response from Ping")
+	}
+
+	if res.StatusCode != 200 {
+		return as.handleError("api/v1/ping", res, textRes)
+	}
 	return nil
 }
 
-func (as *apiService) Time() (time.Time, error) {
+func (as *apiService) Time(ctx context.Context) (time.Time, error) {
 	params := make(map[string]string)
-	res, err := as.request("GET", "api/v1/time", params, false, false)
+	res, err := as.request(ctx, "GET", "api/v1/time", params, false, false)
 	if err != nil {
 		return time.Time{}, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return time.Time{}, errors.Wrap(err, "unable to read response from Time")
 	}
-	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return time.Time{}, as.handleError("api/v1/time", res, textRes)
+	}
+
 	var rawTime struct {
-		ServerTime string `json:"serverTime"`
+		ServerTime float64 `json:"serverTime"`
 	}
 	if err := json.Unmarshal(textRes, &rawTime); err != nil {
 		return time.Time{}, errors.Wrap(err, "timeResponse unmarshal failed")
 	}
-	t, err := timeFromUnixTimestampFloat(rawTime)
+	t, err := timeFromUnixTimestampFloat(rawTime.ServerTime)
 	if err != nil {
 		return time.Time{}, err
 	}
 	return t, nil
 }
 
-func (as *apiService) OrderBook(obr OrderBookRequest) (*OrderBook, error) {
+func (as *apiService) OrderBook(ctx context.Context, obr OrderBookRequest) (*OrderBook, error) {
 	params := make(map[string]string)
 	params["symbol"] = obr.Symbol
 	if obr.Limit != 0 {
 		params["limit"] = strconv.Itoa(obr.Limit)
 	}
-	res, err := as.request("GET", "api/v1/depth", params, false, false)
+	res, err := as.request(ctx, "GET", "api/v1/depth", params, false, false)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from Time")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		as.handleError(textRes)
+		return nil, as.handleError("api/v1/depth", res, textRes)
 	}
 
 	rawBook := &struct {
@@ -77,11 +90,11 @@ func (as *apiService) OrderBook(obr OrderBookRequest) (*OrderBook, error) {
 		LastUpdateID: rawBook.LastUpdateID,
 	}
 	extractOrder := func(rawPrice, rawQuantity interface{}) (*Order, error) {
-		price, err := floatFromString(rawPrice)
+		price, err := decimalFromString(rawPrice)
 		if err != nil {
 			return nil, err
 		}
-		quantity, err := floatFromString(rawPrice)
+		quantity, err := decimalFromString(rawQuantity)
 		if err != nil {
 			return nil, err
 		}
@@ -108,7 +121,7 @@ func (as *apiService) OrderBook(obr OrderBookRequest) (*OrderBook, error) {
 	return ob, nil
 }
 
-func (as *apiService) AggTrades(atr AggTradesRequest) ([]*AggTrade, error) {
+func (as *apiService) AggTrades(ctx context.Context, atr AggTradesRequest) ([]*AggTrade, error) {
 	params := make(map[string]string)
 	params["symbol"] = atr.Symbol
 	if atr.FromID != 0 {
@@ -124,18 +137,18 @@ func (as *apiService) AggTrades(atr AggTradesRequest) ([]*AggTrade, error) {
 		params["limit"] = strconv.Itoa(atr.Limit)
 	}
 
-	res, err := as.request("GET", "api/v1/aggTrades", params, false, false)
+	res, err := as.request(ctx, "GET", "api/v1/aggTrades", params, false, false)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from AggTrades")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		as.handleError(textRes)
+		return nil, as.handleError("api/v1/aggTrades", res, textRes)
 	}
 
 	rawAggTrades := []struct {
@@ -153,11 +166,11 @@ func (as *apiService) AggTrades(atr AggTradesRequest) ([]*AggTrade, error) {
 	}
 	aggTrades := []*AggTrade{}
 	for _, rawTrade := range rawAggTrades {
-		price, err := floatFromString(rawTrade.Price)
+		price, err := decimalFromString(rawTrade.Price)
 		if err != nil {
 			return nil, err
 		}
-		quantity, err := floatFromString(rawTrade.Quantity)
+		quantity, err := decimalFromString(rawTrade.Quantity)
 		if err != nil {
 			return nil, err
 		}
@@ -177,7 +190,104 @@ func (as *apiService) AggTrades(atr AggTradesRequest) ([]*AggTrade, error) {
 	return aggTrades, nil
 }
 
-func (as *apiService) Klines(kr KlinesRequest) ([]*Kline, error) {
+func (as *apiService) Trades(ctx context.Context, tr TradesRequest) ([]*MarketTrade, error) {
+	params := make(map[string]string)
+	params["symbol"] = tr.Symbol
+	if tr.Limit != 0 {
+		params["limit"] = strconv.Itoa(tr.Limit)
+	}
+
+	res, err := as.request(ctx, "GET", "api/v1/trades", params, false, false)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	textRes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read response from Trades")
+	}
+
+	if res.StatusCode != 200 {
+		return nil, as.handleError("api/v1/trades", res, textRes)
+	}
+	return parseMarketTrades(textRes)
+}
+
+func (as *apiService) HistoricalTrades(ctx context.Context, htr HistoricalTradesRequest) ([]*MarketTrade, error) {
+	params := make(map[string]string)
+	params["symbol"] = htr.Symbol
+	if htr.Limit != 0 {
+		params["limit"] = strconv.Itoa(htr.Limit)
+	}
+	if htr.FromID != 0 {
+		params["fromId"] = strconv.FormatInt(htr.FromID, 10)
+	}
+
+	res, err := as.request(ctx, "GET", "api/v1/historicalTrades", params, true, false)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	textRes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read response from HistoricalTrades")
+	}
+
+	if res.StatusCode != 200 {
+		return nil, as.handleError("api/v1/historicalTrades", res, textRes)
+	}
+	return parseMarketTrades(textRes)
+}
+
+func parseMarketTrades(textRes []byte) ([]*MarketTrade, error) {
+	rawTrades := []struct {
+		ID           int64   `json:"id"`
+		Price        string  `json:"price"`
+		Qty          string  `json:"qty"`
+		QuoteQty     string  `json:"quoteQty"`
+		Time         float64 `json:"time"`
+		IsBuyerMaker bool    `json:"isBuyerMaker"`
+		IsBestMatch  bool    `json:"isBestMatch"`
+	}{}
+	if err := json.Unmarshal(textRes, &rawTrades); err != nil {
+		return nil, errors.Wrap(err, "rawTrades unmarshal failed")
+	}
+
+	var mtc []*MarketTrade
+	for _, rawTrade := range rawTrades {
+		price, err := ParseDecimal(rawTrade.Price)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot parse MarketTrade.Price")
+		}
+		qty, err := ParseDecimal(rawTrade.Qty)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot parse MarketTrade.Qty")
+		}
+		// older API versions don't return quoteQty
+		quoteQty := price.Mul(qty)
+		if rawTrade.QuoteQty != "" {
+			if quoteQty, err = ParseDecimal(rawTrade.QuoteQty); err != nil {
+				return nil, errors.Wrap(err, "cannot parse MarketTrade.QuoteQty")
+			}
+		}
+		t, err := timeFromUnixTimestampFloat(rawTrade.Time)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot parse MarketTrade.Time")
+		}
+		mtc = append(mtc, &MarketTrade{
+			ID:           rawTrade.ID,
+			Price:        price,
+			Qty:          qty,
+			QuoteQty:     quoteQty,
+			Time:         t,
+			IsBuyerMaker: rawTrade.IsBuyerMaker,
+			IsBestMatch:  rawTrade.IsBestMatch,
+		})
+	}
+	return mtc, nil
+}
+
+func (as *apiService) Klines(ctx context.Context, kr KlinesRequest) ([]*Kline, error) {
 	params := make(map[string]string)
 	params["symbol"] = kr.Symbol
 	params["interval"] = string(kr.Interval)
@@ -191,18 +301,18 @@ func (as *apiService) Klines(kr KlinesRequest) ([]*Kline, error) {
 		params["endTime"] = strconv.FormatInt(kr.EndTime, 10)
 	}
 
-	res, err := as.request("GET", "api/v1/klines", params, false, false)
+	res, err := as.request(ctx, "GET", "api/v1/klines", params, false, false)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from Klines")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		as.handleError(textRes)
+		return nil, as.handleError("api/v1/klines", res, textRes)
 	}
 
 	rawKlines := [][]interface{}{}
@@ -215,23 +325,23 @@ func (as *apiService) Klines(kr KlinesRequest) ([]*Kline, error) {
 		if err != nil {
 			return nil, errors.Wrap(err, "cannot parse Kline.OpenTime")
 		}
-		open, err := floatFromString(k[1])
+		open, err := decimalFromString(k[1])
 		if err != nil {
 			return nil, errors.Wrap(err, "cannot parse Kline.Open")
 		}
-		high, err := floatFromString(k[2])
+		high, err := decimalFromString(k[2])
 		if err != nil {
 			return nil, errors.Wrap(err, "cannot parse Kline.High")
 		}
-		low, err := floatFromString(k[3])
+		low, err := decimalFromString(k[3])
 		if err != nil {
 			return nil, errors.Wrap(err, "cannot parse Kline.Low")
 		}
-		cls, err := floatFromString(k[4])
+		cls, err := decimalFromString(k[4])
 		if err != nil {
 			return nil, errors.Wrap(err, "cannot parse Kline.Close")
 		}
-		volume, err := floatFromString(k[5])
+		volume, err := decimalFromString(k[5])
 		if err != nil {
 			return nil, errors.Wrap(err, "cannot parse Kline.Volume")
 		}
@@ -239,7 +349,7 @@ func (as *apiService) Klines(kr KlinesRequest) ([]*Kline, error) {
 		if err != nil {
 			return nil, errors.Wrap(err, "cannot parse Kline.CloseTime")
 		}
-		qav, err := floatFromString(k[7])
+		qav, err := decimalFromString(k[7])
 		if err != nil {
 			return nil, errors.Wrap(err, "cannot parse Kline.QuoteAssetVolume")
 		}
@@ -247,11 +357,11 @@ func (as *apiService) Klines(kr KlinesRequest) ([]*Kline, error) {
 		if !ok {
 			return nil, errors.Wrap(err, "cannot parse Kline.NumberOfTrades")
 		}
-		tbbav, err := floatFromString(k[9])
+		tbbav, err := decimalFromString(k[9])
 		if err != nil {
 			return nil, errors.Wrap(err, "cannot parse Kline.TakerBuyBaseAssetVolume")
 		}
-		tbqav, err := floatFromString(k[10])
+		tbqav, err := decimalFromString(k[10])
 		if err != nil {
 			return nil, errors.Wrap(err, "cannot parse Kline.TakerBuyQuoteAssetVolume")
 		}
@@ -272,87 +382,125 @@ func (as *apiService) Klines(kr KlinesRequest) ([]*Kline, error) {
 	return klines, nil
 }
 
-func (as *apiService) Ticker24(tr TickerRequest) (*Ticker24, error) {
+func (as *apiService) Ticker24(ctx context.Context, tr TickerRequest) (*Ticker24, error) {
 	params := make(map[string]string)
 	params["symbol"] = tr.Symbol
 
-	res, err := as.request("GET", "api/v1/ticker/24hr", params, false, false)
+	res, err := as.request(ctx, "GET", "api/v1/ticker/24hr", params, false, false)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from Ticker/24hr")
 	}
+
+	if res.StatusCode != 200 {
+		return nil, as.handleError("api/v1/ticker/24hr", res, textRes)
+	}
+
+	rawTicker := rawTicker24{}
+	if err := json.Unmarshal(textRes, &rawTicker); err != nil {
+		return nil, errors.Wrap(err, "rawTicker24 unmarshal failed")
+	}
+	return rawTicker.ticker()
+}
+
+func (as *apiService) TickerAll24(ctx context.Context) ([]*Ticker24, error) {
+	params := make(map[string]string)
+
+	res, err := as.request(ctx, "GET", "api/v1/ticker/24hr", params, false, false)
+	if err != nil {
+		return nil, err
+	}
 	defer res.Body.Close()
+	textRes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read response from Ticker/24hr")
+	}
 
 	if res.StatusCode != 200 {
-		as.handleError(textRes)
-	}
-
-	rawTicker24 := struct {
-		PriceChange        string  `json:"priceChange"`
-		PriceChangePercent string  `json:"priceChangePercent"`
-		WeightedAvgPrice   string  `json:"weightedAvgPrice"`
-		PrevClosePrice     string  `json:"prevClosePrice"`
-		LastPrice          string  `json:"lastPrice"`
-		BidPrice           string  `json:"bidPrice"`
-		AskPrice           string  `json:"askPrice"`
-		OpenPrice          string  `json:"openPrice"`
-		HighPrice          string  `json:"highPrice"`
-		LowPrice           string  `json:"lowPrice"`
-		Volume             string  `json:"volume"`
-		OpenTime           float64 `json:"openTime"`
-		CloseTime          float64 `json:"closeTime"`
-		FirstID            int
-		LastID             int
-		Count              int
-	}{}
-	if err := json.Unmarshal(textRes, &rawTicker24); err != nil {
+		return nil, as.handleError("api/v1/ticker/24hr", res, textRes)
+	}
+
+	rawTickers := []rawTicker24{}
+	if err := json.Unmarshal(textRes, &rawTickers); err != nil {
 		return nil, errors.Wrap(err, "rawTicker24 unmarshal failed")
 	}
+	var tickers []*Ticker24
+	for _, rawTicker := range rawTickers {
+		t24, err := rawTicker.ticker()
+		if err != nil {
+			return nil, err
+		}
+		tickers = append(tickers, t24)
+	}
+	return tickers, nil
+}
 
-	pc, err := strconv.ParseFloat(rawTicker24.PriceChange, 64)
+type rawTicker24 struct {
+	Symbol             string  `json:"symbol"`
+	PriceChange        string  `json:"priceChange"`
+	PriceChangePercent string  `json:"priceChangePercent"`
+	WeightedAvgPrice   string  `json:"weightedAvgPrice"`
+	PrevClosePrice     string  `json:"prevClosePrice"`
+	LastPrice          string  `json:"lastPrice"`
+	BidPrice           string  `json:"bidPrice"`
+	AskPrice           string  `json:"askPrice"`
+	OpenPrice          string  `json:"openPrice"`
+	HighPrice          string  `json:"highPrice"`
+	LowPrice           string  `json:"lowPrice"`
+	Volume             string  `json:"volume"`
+	OpenTime           float64 `json:"openTime"`
+	CloseTime          float64 `json:"closeTime"`
+	FirstID            int
+	LastID             int
+	Count              int
+}
+
+func (rawTicker24 rawTicker24) ticker() (*Ticker24, error) {
+	pc, err := ParseDecimal(rawTicker24.PriceChange)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Ticker24.PriceChange")
 	}
-	pcPercent, err := strconv.ParseFloat(rawTicker24.PriceChangePercent, 64)
+	pcPercent, err := ParseDecimal(rawTicker24.PriceChangePercent)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Ticker24.PriceChangePercent")
 	}
-	wap, err := strconv.ParseFloat(rawTicker24.WeightedAvgPrice, 64)
+	wap, err := ParseDecimal(rawTicker24.WeightedAvgPrice)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Ticker24.WeightedAvgPrice")
 	}
-	pcp, err := strconv.ParseFloat(rawTicker24.PrevClosePrice, 64)
+	pcp, err := ParseDecimal(rawTicker24.PrevClosePrice)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Ticker24.PrevClosePrice")
 	}
-	lastPrice, err := strconv.ParseFloat(rawTicker24.LastPrice, 64)
+	lastPrice, err := ParseDecimal(rawTicker24.LastPrice)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Ticker24.LastPrice")
 	}
-	bp, err := strconv.ParseFloat(rawTicker24.BidPrice, 64)
+	bp, err := ParseDecimal(rawTicker24.BidPrice)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Ticker24.BidPrice")
 	}
-	ap, err := strconv.ParseFloat(rawTicker24.AskPrice, 64)
+	ap, err := ParseDecimal(rawTicker24.AskPrice)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Ticker24.AskPrice")
 	}
-	op, err := strconv.ParseFloat(rawTicker24.OpenPrice, 64)
+	op, err := ParseDecimal(rawTicker24.OpenPrice)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Ticker24.OpenPrice")
 	}
-	hp, err := strconv.ParseFloat(rawTicker24.HighPrice, 64)
+	hp, err := ParseDecimal(rawTicker24.HighPrice)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Ticker24.HighPrice")
 	}
-	lowPrice, err := strconv.ParseFloat(rawTicker24.LowPrice, 64)
+	lowPrice, err := ParseDecimal(rawTicker24.LowPrice)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Ticker24.LowPrice")
 	}
-	vol, err := strconv.ParseFloat(rawTicker24.Volume, 64)
+	vol, err := ParseDecimal(rawTicker24.Volume)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse Ticker24.Volume")
 	}
@@ -365,6 +513,7 @@ func (as *apiService) Ticker24(tr TickerRequest) (*Ticker24, error) {
 		return nil, errors.Wrap(err, "cannot parse Ticker24.CloseTime")
 	}
 	t24 := &Ticker24{
+		Symbol:             rawTicker24.Symbol,
 		PriceChange:        pc,
 		PriceChangePercent: pcPercent,
 		WeightedAvgPrice:   wap,
@@ -385,21 +534,21 @@ func (as *apiService) Ticker24(tr TickerRequest) (*Ticker24, error) {
 	return t24, nil
 }
 
-func (as *apiService) TickerAllPrices() ([]*PriceTicker, error) {
+func (as *apiService) TickerAllPrices(ctx context.Context) ([]*PriceTicker, error) {
 	params := make(map[string]string)
 
-	res, err := as.request("GET", "api/v1/ticker/allPrices", params, false, false)
+	res, err := as.request(ctx, "GET", "api/v1/ticker/allPrices", params, false, false)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from Ticker/24hr")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		as.handleError(textRes)
+		return nil, as.handleError("api/v1/ticker/allPrices", res, textRes)
 	}
 
 	rawTickerAllPrices := []struct {
@@ -412,7 +561,7 @@ func (as *apiService) TickerAllPrices() ([]*PriceTicker, error) {
 
 	var tpc []*PriceTicker
 	for _, rawTickerPrice := range rawTickerAllPrices {
-		p, err := strconv.ParseFloat(rawTickerPrice.Price, 64)
+		p, err := ParseDecimal(rawTickerPrice.Price)
 		if err != nil {
 			return nil, errors.Wrap(err, "cannot parse TickerAllPrices.Price")
 		}
@@ -424,59 +573,329 @@ func (as *apiService) TickerAllPrices() ([]*PriceTicker, error) {
 	return tpc, nil
 }
 
-func (as *apiService) TickerAllBooks() ([]*BookTicker, error) {
+func (as *apiService) TickerAllBooks(ctx context.Context) ([]*BookTicker, error) {
 	params := make(map[string]string)
 
-	res, err := as.request("GET", "api/v1/ticker/allBookTickers", params, false, false)
+	res, err := as.request(ctx, "GET", "api/v1/ticker/allBookTickers", params, false, false)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from Ticker/allBookTickers")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return nil, as.handleError(textRes)
+		return nil, as.handleError("api/v1/ticker/allBookTickers", res, textRes)
 	}
 
-	rawBookTickers := []struct {
-		Symbol   string `json:"symbol"`
-		BidPrice string `json:"bidPrice"`
-		BidQty   string `json:"bidQty"`
-		AskPrice string `json:"askPrice"`
-		AskQty   string `json:"askQty"`
-	}{}
+	rawBookTickers := []rawBookTicker{}
 	if err := json.Unmarshal(textRes, &rawBookTickers); err != nil {
 		return nil, errors.Wrap(err, "rawBookTickers unmarshal failed")
 	}
 
 	var btc []*BookTicker
 	for _, rawBookTicker := range rawBookTickers {
-		bp, err := strconv.ParseFloat(rawBookTicker.BidPrice, 64)
+		bt, err := rawBookTicker.ticker()
 		if err != nil {
-			return nil, errors.Wrap(err, "cannot parse TickerBookTickers.BidPrice")
+			return nil, err
 		}
-		bqty, err := strconv.ParseFloat(rawBookTicker.BidQty, 64)
-		if err != nil {
-			return nil, errors.Wrap(err, "cannot parse TickerBookTickers.BidQty")
+		btc = append(btc, bt)
+	}
+	return btc, nil
+}
+
+func (as *apiService) TickerPrice(ctx context.Context, tr TickerRequest) (*PriceTicker, error) {
+	params := make(map[string]string)
+	params["symbol"] = tr.Symbol
+
+	res, err := as.request(ctx, "GET", "api/v3/ticker/price", params, false, false)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	textRes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read response from Ticker/price")
+	}
+
+	if res.StatusCode != 200 {
+		return nil, as.handleError("api/v3/ticker/price", res, textRes)
+	}
+
+	rawTickerPrice := struct {
+		Symbol string `json:"symbol"`
+		Price  string `json:"price"`
+	}{}
+	if err := json.Unmarshal(textRes, &rawTickerPrice); err != nil {
+		return nil, errors.Wrap(err, "rawTickerPrice unmarshal failed")
+	}
+	p, err := ParseDecimal(rawTickerPrice.Price)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse TickerPrice.Price")
+	}
+	return &PriceTicker{
+		Symbol: rawTickerPrice.Symbol,
+		Price:  p,
+	}, nil
+}
+
+func (as *apiService) TickerBook(ctx context.Context, tr TickerRequest) (*BookTicker, error) {
+	params := make(map[string]string)
+	params["symbol"] = tr.Symbol
+
+	res, err := as.request(ctx, "GET", "api/v3/ticker/bookTicker", params, false, false)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	textRes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read response from Ticker/bookTicker")
+	}
+
+	if res.StatusCode != 200 {
+		return nil, as.handleError("api/v3/ticker/bookTicker", res, textRes)
+	}
+
+	rawTicker := rawBookTicker{}
+	if err := json.Unmarshal(textRes, &rawTicker); err != nil {
+		return nil, errors.Wrap(err, "rawBookTicker unmarshal failed")
+	}
+	return rawTicker.ticker()
+}
+
+type rawBookTicker struct {
+	Symbol   string `json:"symbol"`
+	BidPrice string `json:"bidPrice"`
+	BidQty   string `json:"bidQty"`
+	AskPrice string `json:"askPrice"`
+	AskQty   string `json:"askQty"`
+}
+
+func (rawBookTicker rawBookTicker) ticker() (*BookTicker, error) {
+	bp, err := ParseDecimal(rawBookTicker.BidPrice)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse TickerBookTickers.BidPrice")
+	}
+	bqty, err := ParseDecimal(rawBookTicker.BidQty)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse TickerBookTickers.BidQty")
+	}
+	ap, err := ParseDecimal(rawBookTicker.AskPrice)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse TickerBookTickers.AskPrice")
+	}
+	aqty, err := ParseDecimal(rawBookTicker.AskQty)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse TickerBookTickers.AskQty")
+	}
+	return &BookTicker{
+		Symbol:   rawBookTicker.Symbol,
+		BidPrice: bp,
+		BidQty:   bqty,
+		AskPrice: ap,
+		AskQty:   aqty,
+	}, nil
+}
+
+func (as *apiService) AvgPrice(ctx context.Context, apr AvgPriceRequest) (*AvgPrice, error) {
+	params := make(map[string]string)
+	params["symbol"] = apr.Symbol
+
+	res, err := as.request(ctx, "GET", "api/v3/avgPrice", params, false, false)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	textRes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read response from avgPrice")
+	}
+
+	if res.StatusCode != 200 {
+		return nil, as.handleError("api/v3/avgPrice", res, textRes)
+	}
+
+	rawAvgPrice := struct {
+		Mins  int    `json:"mins"`
+		Price string `json:"price"`
+	}{}
+	if err := json.Unmarshal(textRes, &rawAvgPrice); err != nil {
+		return nil, errors.Wrap(err, "rawAvgPrice unmarshal failed")
+	}
+	p, err := ParseDecimal(rawAvgPrice.Price)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse AvgPrice.Price")
+	}
+	return &AvgPrice{
+		Mins:  rawAvgPrice.Mins,
+		Price: p,
+	}, nil
+}
+
+func (as *apiService) ExchangeInfo(ctx context.Context) (*ExchangeInfo, error) {
+	params := make(map[string]string)
+
+	res, err := as.request(ctx, "GET", "api/v1/exchangeInfo", params, false, false)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	textRes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read response from exchangeInfo")
+	}
+
+	if res.StatusCode != 200 {
+		return nil, as.handleError("api/v1/exchangeInfo", res, textRes)
+	}
+
+	rawExchangeInfo := struct {
+		Timezone   string  `json:"timezone"`
+		ServerTime float64 `json:"serverTime"`
+		RateLimits []struct {
+			RateLimitType string `json:"rateLimitType"`
+			Interval      string `json:"interval"`
+			IntervalNum   int    `json:"intervalNum"`
+			Limit         int    `json:"limit"`
+		} `json:"rateLimits"`
+		Symbols []struct {
+			Symbol             string            `json:"symbol"`
+			Status             string            `json:"status"`
+			BaseAsset          string            `json:"baseAsset"`
+			BaseAssetPrecision int               `json:"baseAssetPrecision"`
+			QuoteAsset         string            `json:"quoteAsset"`
+			QuotePrecision     int               `json:"quotePrecision"`
+			OrderTypes         []string          `json:"orderTypes"`
+			IcebergAllowed     bool              `json:"icebergAllowed"`
+			Filters            []json.RawMessage `json:"filters"`
+		} `json:"symbols"`
+	}{}
+	if err := json.Unmarshal(textRes, &rawExchangeInfo); err != nil {
+		return nil, errors.Wrap(err, "rawExchangeInfo unmarshal failed")
+	}
+
+	st, err := timeFromUnixTimestampFloat(rawExchangeInfo.ServerTime)
+	if err != nil {
+		return nil, err
+	}
+	ei := &ExchangeInfo{
+		Timezone:   rawExchangeInfo.Timezone,
+		ServerTime: st,
+	}
+	for _, rawRateLimit := range rawExchangeInfo.RateLimits {
+		ei.RateLimits = append(ei.RateLimits, &RateLimit{
+			Type:        rawRateLimit.RateLimitType,
+			Interval:    RateLimitInterval(rawRateLimit.Interval),
+			IntervalNum: rawRateLimit.IntervalNum,
+			Limit:       rawRateLimit.Limit,
+		})
+	}
+	for _, rawSymbol := range rawExchangeInfo.Symbols {
+		si := &SymbolInfo{
+			Symbol:             rawSymbol.Symbol,
+			Status:             SymbolStatus(rawSymbol.Status),
+			BaseAsset:          rawSymbol.BaseAsset,
+			BaseAssetPrecision: rawSymbol.BaseAssetPrecision,
+			QuoteAsset:         rawSymbol.QuoteAsset,
+			QuotePrecision:     rawSymbol.QuotePrecision,
+			IcebergAllowed:     rawSymbol.IcebergAllowed,
 		}
-		ap, err := strconv.ParseFloat(rawBookTicker.AskPrice, 64)
-		if err != nil {
-			return nil, errors.Wrap(err, "cannot parse TickerBookTickers.AskPrice")
+		for _, ot := range rawSymbol.OrderTypes {
+			si.OrderTypes = append(si.OrderTypes, OrderType(ot))
 		}
-		aqty, err := strconv.ParseFloat(rawBookTicker.AskQty, 64)
-		if err != nil {
-			return nil, errors.Wrap(err, "cannot parse TickerBookTickers.AskQty")
-		}
-		btc = append(btc, &BookTicker{
-			Symbol:   rawBookTicker.Symbol,
-			BidPrice: bp,
-			BidQty:   bqty,
-			AskPrice: ap,
-			AskQty:   aqty,
-		})
+		for _, rawFilter := range rawSymbol.Filters {
+			if err := parseSymbolFilter(&si.Filters, rawFilter); err != nil {
+				return nil, errors.Wrapf(err, "cannot parse filters of %s", si.Symbol)
+			}
+		}
+		ei.Symbols = append(ei.Symbols, si)
 	}
-	return btc, nil
+	return ei, nil
+}
+
+// parseSymbolFilter sets filter of matching type, unknown filters are ignored.
+func parseSymbolFilter(filters *SymbolFilters, raw json.RawMessage) error {
+	rawFilter := struct {
+		FilterType       string `json:"filterType"`
+		MinPrice         string `json:"minPrice"`
+		MaxPrice         string `json:"maxPrice"`
+		TickSize         string `json:"tickSize"`
+		MultiplierUp     string `json:"multiplierUp"`
+		MultiplierDown   string `json:"multiplierDown"`
+		AvgPriceMins     int    `json:"avgPriceMins"`
+		MinQty           string `json:"minQty"`
+		MaxQty           string `json:"maxQty"`
+		StepSize         string `json:"stepSize"`
+		MinNotional      string `json:"minNotional"`
+		ApplyToMarket    bool   `json:"applyToMarket"`
+		Limit            int    `json:"limit"`
+		MaxNumOrders     int    `json:"maxNumOrders"`
+		MaxNumAlgoOrders int    `json:"maxNumAlgoOrders"`
+	}{}
+	if err := json.Unmarshal(raw, &rawFilter); err != nil {
+		return errors.Wrap(err, "rawFilter unmarshal failed")
+	}
+
+	// decimal parses optional decimal field, missing value is zero
+	var err error
+	decimal := func(name, value string) Decimal {
+		if value == "" || err != nil {
+			return Decimal{}
+		}
+		var d Decimal
+		if d, err = ParseDecimal(value); err != nil {
+			err = errors.Wrapf(err, "cannot parse %s.%s", rawFilter.FilterType, name)
+		}
+		return d
+	}
+	switch rawFilter.FilterType {
+	case "PRICE_FILTER":
+		filters.Price = &PriceFilter{
+			MinPrice: decimal("minPrice", rawFilter.MinPrice),
+			MaxPrice: decimal("maxPrice", rawFilter.MaxPrice),
+			TickSize: decimal("tickSize", rawFilter.TickSize),
+		}
+	case "PERCENT_PRICE":
+		filters.PercentPrice = &PercentPriceFilter{
+			MultiplierUp:   decimal("multiplierUp", rawFilter.MultiplierUp),
+			MultiplierDown: decimal("multiplierDown", rawFilter.MultiplierDown),
+			AvgPriceMins:   rawFilter.AvgPriceMins,
+		}
+	case "LOT_SIZE", "MARKET_LOT_SIZE":
+		f := &LotSizeFilter{
+			MinQty:   decimal("minQty", rawFilter.MinQty),
+			MaxQty:   decimal("maxQty", rawFilter.MaxQty),
+			StepSize: decimal("stepSize", rawFilter.StepSize),
+		}
+		if rawFilter.FilterType == "LOT_SIZE" {
+			filters.LotSize = f
+		} else {
+			filters.MarketLotSize = f
+		}
+	case "MIN_NOTIONAL":
+		filters.MinNotional = &MinNotionalFilter{
+			MinNotional:   decimal("minNotional", rawFilter.MinNotional),
+			ApplyToMarket: rawFilter.ApplyToMarket,
+			AvgPriceMins:  rawFilter.AvgPriceMins,
+		}
+	case "ICEBERG_PARTS":
+		filters.IcebergParts = &IcebergPartsFilter{Limit: rawFilter.Limit}
+	case "MAX_NUM_ORDERS":
+		// older responses publish the value as limit
+		limit := rawFilter.MaxNumOrders
+		if limit == 0 {
+			limit = rawFilter.Limit
+		}
+		filters.MaxNumOrders = &MaxNumOrdersFilter{Limit: limit}
+	case "MAX_NUM_ALGO_ORDERS":
+		limit := rawFilter.MaxNumAlgoOrders
+		if limit == 0 {
+			limit = rawFilter.Limit
+		}
+		filters.MaxNumAlgoOrders = &MaxNumOrdersFilter{Limit: limit}
+	}
+	return err
 }
@@ -1,29 +1,29 @@
 package binance
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
-	"log"
 
 	"github.com/pkg/errors"
 )
 
-func (as *apiService) StartUserDataStream() (*Stream, error) {
+func (as *apiService) StartUserDataStream(ctx context.Context) (*Stream, error) {
 	params := make(map[string]string)
 
-	res, err := as.request("POST", "api/v1/userDataStream", params, true, false)
+	res, err := as.request(ctx, "POST", "api/v1/userDataStream", params, true, false)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to read response from userDataStream.post")
 	}
-	defer res.Body.Close()
 
-	log.Println(string(textRes))
+	as.log(LogResponses, LogDebug).Log("userDataStream", textRes)
 	if res.StatusCode != 200 {
-		return nil, as.handleError(textRes)
+		return nil, as.handleError("api/v1/userDataStream", res, textRes)
 	}
 
 	var s Stream
@@ -32,41 +32,41 @@ func (as *apiService) StartUserDataStream() (*Stream, error) {
 	}
 	return &s, nil
 }
-func (as *apiService) KeepAliveUserDataStream(s *Stream) error {
+func (as *apiService) KeepAliveUserDataStream(ctx context.Context, s *Stream) error {
 	params := make(map[string]string)
 	params["listenKey"] = s.ListenKey
 
-	res, err := as.request("PUT", "api/v1/userDataStream", params, true, false)
+	res, err := as.request(ctx, "PUT", "api/v1/userDataStream", params, true, false)
 	if err != nil {
 		return err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return errors.Wrap(err, "unable to read response from userDataStream.put")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return as.handleError(textRes)
+		return as.handleError("api/v1/userDataStream", res, textRes)
 	}
 	return nil
 }
-func (as *apiService) CloseUserDataStream(s *Stream) error {
+func (as *apiService) CloseUserDataStream(ctx context.Context, s *Stream) error {
 	params := make(map[string]string)
 	params["listenKey"] = s.ListenKey
 
-	res, err := as.request("DELETE", "api/v1/userDataStream", params, true, false)
+	res, err := as.request(ctx, "DELETE", "api/v1/userDataStream", params, true, false)
 	if err != nil {
 		return err
 	}
+	defer res.Body.Close()
 	textRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return errors.Wrap(err, "unable to read response from userDataStream.delete")
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return as.handleError(textRes)
+		return as.handleError("api/v1/userDataStream", res, textRes)
 	}
 	return nil
 }
@@ -1,114 +1,218 @@
 package binance
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"net"
 	"strings"
 	"time"
 
-	"github.com/go-kit/kit/log/level"
 	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
 )
 
-func (as *apiService) DepthWebsocket(dwr DepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error) {
-	url := fmt.Sprintf("wss://stream.binance.com:9443/ws/%s@depth", strings.ToLower(dwr.Symbol))
-	c, _, err := websocket.DefaultDialer.Dial(url, nil)
+func (as *apiService) DepthWebsocket(ctx context.Context, dwr DepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error) {
+	stream := fmt.Sprintf("%s@depth", strings.ToLower(dwr.Symbol))
+	ctx, cancel := as.context(ctx)
+	c, err := as.dialStream(ctx, stream, stream)
 	if err != nil {
-		log.Fatal("dial:", err)
+		cancel()
+		return nil, nil, err
 	}
-
 	done := make(chan struct{})
 	dech := make(chan *DepthEvent)
 
 	go func() {
 		defer c.Close()
 		defer close(done)
+		defer cancel()
+		defer as.Metrics.StreamConnections.With("stream", stream).Add(-1)
 		for {
 			select {
-			case <-as.Ctx.Done():
-				level.Info(as.Logger).Log("closing reader")
+			case <-ctx.Done():
+				as.log(LogStreams, LogInfo).Log("closing reader")
 				return
 			default:
 				_, message, err := c.ReadMessage()
 				if err != nil {
-					level.Error(as.Logger).Log("wsRead", err)
+					as.log(LogStreams, LogErrors).Log("wsRead", err)
 					return
 				}
-				rawDepth := struct {
-					Type          string          `json:"e"`
-					Time          float64         `json:"E"`
-					Symbol        string          `json:"s"`
-					UpdateID      int             `json:"u"`
-					BidDepthDelta [][]interface{} `json:"b"`
-					AskDepthDelta [][]interface{} `json:"a"`
-				}{}
-				if err := json.Unmarshal(message, &rawDepth); err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", string(message))
+				as.Metrics.StreamMessages.With("stream", stream).Add(1)
+				de, err := parseDepthEvent(message)
+				if err != nil {
+					as.streamParseFailure(stream, err, string(message))
+					return
+				}
+				select {
+				case dech <- de:
+				case <-ctx.Done():
 					return
 				}
-				t, err := timeFromUnixTimestampFloat(rawDepth.Time)
+			}
+		}
+	}()
+
+	go as.exitHandler(ctx, c, done)
+	return dech, done, nil
+}
+
+func (as *apiService) CombinedDepthWebsocket(ctx context.Context, cdwr CombinedDepthWebsocketRequest) (chan *DepthEvent, chan struct{}, error) {
+	if len(cdwr.Symbols) == 0 {
+		return nil, nil, errors.New("no symbols to stream")
+	}
+	streams := make([]string, 0, len(cdwr.Symbols))
+	for _, symbol := range cdwr.Symbols {
+		streams = append(streams, fmt.Sprintf("%s@depth", strings.ToLower(symbol)))
+	}
+	// combined streams are labeled by slot of the connection, so the labels
+	// stay bounded and connection reopened in the slot is counted as reconnect
+	slot := as.streams.acquire()
+	stream := fmt.Sprintf("combined@depth#%d", slot)
+	ctx, cancel := as.context(ctx)
+	c, err := as.dialCombinedStream(ctx, streams, stream)
+	if err != nil {
+		cancel()
+		as.streams.release(slot)
+		return nil, nil, err
+	}
+	done := make(chan struct{})
+	// buffered, so that a burst of events of all symbols doesn't stall the connection
+	dech := make(chan *DepthEvent, len(streams))
+
+	go func() {
+		defer c.Close()
+		defer close(done)
+		defer cancel()
+		defer as.streams.release(slot)
+		defer as.Metrics.StreamConnections.With("stream", stream).Add(-1)
+		for {
+			select {
+			case <-ctx.Done():
+				as.log(LogStreams, LogInfo).Log("closing reader")
+				return
+			default:
+				_, message, err := c.ReadMessage()
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", string(message))
+					as.log(LogStreams, LogErrors).Log("wsRead", err)
 					return
 				}
-				de := &DepthEvent{
-					WSEvent: WSEvent{
-						Type:   rawDepth.Type,
-						Time:   t,
-						Symbol: rawDepth.Symbol,
-					},
-					UpdateID: rawDepth.UpdateID,
+				as.Metrics.StreamMessages.With("stream", stream).Add(1)
+				rawCombined := struct {
+					Stream string          `json:"stream"`
+					Data   json.RawMessage `json:"data"`
+				}{}
+				if err := json.Unmarshal(message, &rawCombined); err != nil {
+					as.streamParseFailure(stream, err, string(message))
+					return
 				}
-				for _, b := range rawDepth.BidDepthDelta {
-					p, err := floatFromString(b[0])
-					if err != nil {
-						level.Error(as.Logger).Log("wsUnmarshal", err, "body", string(message))
-						return
-					}
-					q, err := floatFromString(b[1])
-					if err != nil {
-						level.Error(as.Logger).Log("wsUnmarshal", err, "body", string(message))
-						return
-					}
-					de.Bids = append(de.Bids, &Order{
-						Price:    p,
-						Quantity: q,
-					})
+				de, err := parseDepthEvent(rawCombined.Data)
+				if err != nil {
+					as.streamParseFailure(stream, err, string(message))
+					return
+				}
+				select {
+				case dech <- de:
+				case <-ctx.Done():
+					return
 				}
-				dech <- de
 			}
 		}
 	}()
 
-	go as.exitHandler(c, done)
+	go as.exitHandler(ctx, c, done)
 	return dech, done, nil
 }
 
-func (as *apiService) KlineWebsocket(kwr KlineWebsocketRequest) (chan *KlineEvent, chan struct{}, error) {
-	url := fmt.Sprintf("wss://stream.binance.com:9443/ws/%s@kline_%s", strings.ToLower(kwr.Symbol), string(kwr.Interval))
-	c, _, err := websocket.DefaultDialer.Dial(url, nil)
+// parseDepthEvent parses payload of depth stream.
+func parseDepthEvent(message []byte) (*DepthEvent, error) {
+	rawDepth := struct {
+		Type          string          `json:"e"`
+		Time          float64         `json:"E"`
+		Symbol        string          `json:"s"`
+		FirstUpdateID int             `json:"U"`
+		UpdateID      int             `json:"u"`
+		BidDepthDelta [][]interface{} `json:"b"`
+		AskDepthDelta [][]interface{} `json:"a"`
+	}{}
+	if err := json.Unmarshal(message, &rawDepth); err != nil {
+		return nil, err
+	}
+	t, err := timeFromUnixTimestampFloat(rawDepth.Time)
 	if err != nil {
-		log.Fatal("dial:", err)
+		return nil, err
+	}
+	de := &DepthEvent{
+		WSEvent: WSEvent{
+			Type:   rawDepth.Type,
+			Time:   t,
+			Symbol: rawDepth.Symbol,
+		},
+		FirstUpdateID: rawDepth.FirstUpdateID,
+		UpdateID:      rawDepth.UpdateID,
+	}
+	if de.Bids, err = depthLevels(rawDepth.BidDepthDelta); err != nil {
+		return nil, err
+	}
+	if de.Asks, err = depthLevels(rawDepth.AskDepthDelta); err != nil {
+		return nil, err
+	}
+	return de, nil
+}
+
+// depthLevels parses price levels of depth update, quantity of removed level
+// is zero.
+func depthLevels(raw [][]interface{}) ([]*Order, error) {
+	var levels []*Order
+	for _, l := range raw {
+		if len(l) < 2 {
+			return nil, errors.Errorf("invalid depth level: %v", l)
+		}
+		p, err := decimalFromString(l[0])
+		if err != nil {
+			return nil, err
+		}
+		q, err := decimalFromString(l[1])
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, &Order{
+			Price:    p,
+			Quantity: q,
+		})
 	}
+	return levels, nil
+}
 
+func (as *apiService) KlineWebsocket(ctx context.Context, kwr KlineWebsocketRequest) (chan *KlineEvent, chan struct{}, error) {
+	stream := fmt.Sprintf("%s@kline_%s", strings.ToLower(kwr.Symbol), string(kwr.Interval))
+	ctx, cancel := as.context(ctx)
+	c, err := as.dialStream(ctx, stream, stream)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
 	done := make(chan struct{})
 	kech := make(chan *KlineEvent)
 
 	go func() {
 		defer c.Close()
 		defer close(done)
+		defer cancel()
+		defer as.Metrics.StreamConnections.With("stream", stream).Add(-1)
 		for {
 			select {
-			case <-as.Ctx.Done():
-				level.Info(as.Logger).Log("closing reader")
+			case <-ctx.Done():
+				as.log(LogStreams, LogInfo).Log("closing reader")
 				return
 			default:
 				_, message, err := c.ReadMessage()
 				if err != nil {
-					level.Error(as.Logger).Log("wsRead", err)
+					as.log(LogStreams, LogErrors).Log("wsRead", err)
 					return
 				}
+				as.Metrics.StreamMessages.With("stream", stream).Add(1)
 				rawKline := struct {
 					Type     string  `json:"e"`
 					Time     float64 `json:"E"`
@@ -133,62 +237,62 @@ func (as *apiService) KlineWebsocket(kwr KlineWebsocketRequest) (chan *KlineEven
 					} `json:"k"`
 				}{}
 				if err := json.Unmarshal(message, &rawKline); err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", string(message))
+					as.streamParseFailure(stream, err, string(message))
 					return
 				}
 				t, err := timeFromUnixTimestampFloat(rawKline.Time)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", rawKline.Time)
+					as.streamParseFailure(stream, err, rawKline.Time)
 					return
 				}
 				ot, err := timeFromUnixTimestampFloat(rawKline.Kline.OpenTime)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", rawKline.Kline.OpenTime)
+					as.streamParseFailure(stream, err, rawKline.Kline.OpenTime)
 					return
 				}
 				ct, err := timeFromUnixTimestampFloat(rawKline.Kline.CloseTime)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", rawKline.Kline.CloseTime)
+					as.streamParseFailure(stream, err, rawKline.Kline.CloseTime)
 					return
 				}
-				open, err := floatFromString(rawKline.Kline.Open)
+				open, err := decimalFromString(rawKline.Kline.Open)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", rawKline.Kline.Open)
+					as.streamParseFailure(stream, err, rawKline.Kline.Open)
 					return
 				}
-				cls, err := floatFromString(rawKline.Kline.Close)
+				cls, err := decimalFromString(rawKline.Kline.Close)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", rawKline.Kline.Close)
+					as.streamParseFailure(stream, err, rawKline.Kline.Close)
 					return
 				}
-				high, err := floatFromString(rawKline.Kline.High)
+				high, err := decimalFromString(rawKline.Kline.High)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", rawKline.Kline.High)
+					as.streamParseFailure(stream, err, rawKline.Kline.High)
 					return
 				}
-				low, err := floatFromString(rawKline.Kline.Low)
+				low, err := decimalFromString(rawKline.Kline.Low)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", rawKline.Kline.Low)
+					as.streamParseFailure(stream, err, rawKline.Kline.Low)
 					return
 				}
-				vol, err := floatFromString(rawKline.Kline.Volume)
+				vol, err := decimalFromString(rawKline.Kline.Volume)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", rawKline.Kline.Volume)
+					as.streamParseFailure(stream, err, rawKline.Kline.Volume)
 					return
 				}
-				qav, err := floatFromString(rawKline.Kline.QuoteAssetVolume)
+				qav, err := decimalFromString(rawKline.Kline.QuoteAssetVolume)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", (rawKline.Kline.QuoteAssetVolume))
+					as.streamParseFailure(stream, err, (rawKline.Kline.QuoteAssetVolume))
 					return
 				}
-				tbbav, err := floatFromString(rawKline.Kline.TakerBuyBaseAssetVolume)
+				tbbav, err := decimalFromString(rawKline.Kline.TakerBuyBaseAssetVolume)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", rawKline.Kline.TakerBuyBaseAssetVolume)
+					as.streamParseFailure(stream, err, rawKline.Kline.TakerBuyBaseAssetVolume)
 					return
 				}
-				tbqav, err := floatFromString(rawKline.Kline.TakerBuyQuoteAssetVolume)
+				tbqav, err := decimalFromString(rawKline.Kline.TakerBuyQuoteAssetVolume)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", rawKline.Kline.TakerBuyQuoteAssetVolume)
+					as.streamParseFailure(stream, err, rawKline.Kline.TakerBuyQuoteAssetVolume)
 					return
 				}
 
@@ -216,39 +320,47 @@ func (as *apiService) KlineWebsocket(kwr KlineWebsocketRequest) (chan *KlineEven
 						TakerBuyQuoteAssetVolume: tbqav,
 					},
 				}
-				kech <- ke
+				select {
+				case kech <- ke:
+				case <-ctx.Done():
+					return
+				}
 			}
 		}
 	}()
 
-	go as.exitHandler(c, done)
+	go as.exitHandler(ctx, c, done)
 	return kech, done, nil
 }
 
-func (as *apiService) TradeWebsocket(twr TradeWebsocketRequest) (chan *AggTradeEvent, chan struct{}, error) {
-	url := fmt.Sprintf("wss://stream.binance.com:9443/ws/%s@aggTrade", strings.ToLower(twr.Symbol))
-	c, _, err := websocket.DefaultDialer.Dial(url, nil)
+func (as *apiService) TradeWebsocket(ctx context.Context, twr TradeWebsocketRequest) (chan *AggTradeEvent, chan struct{}, error) {
+	stream := fmt.Sprintf("%s@aggTrade", strings.ToLower(twr.Symbol))
+	ctx, cancel := as.context(ctx)
+	c, err := as.dialStream(ctx, stream, stream)
 	if err != nil {
-		log.Fatal("dial:", err)
+		cancel()
+		return nil, nil, err
 	}
-
 	done := make(chan struct{})
 	aggtech := make(chan *AggTradeEvent)
 
 	go func() {
 		defer c.Close()
 		defer close(done)
+		defer cancel()
+		defer as.Metrics.StreamConnections.With("stream", stream).Add(-1)
 		for {
 			select {
-			case <-as.Ctx.Done():
-				level.Info(as.Logger).Log("closing reader")
+			case <-ctx.Done():
+				as.log(LogStreams, LogInfo).Log("closing reader")
 				return
 			default:
 				_, message, err := c.ReadMessage()
 				if err != nil {
-					level.Error(as.Logger).Log("wsRead", err)
+					as.log(LogStreams, LogErrors).Log("wsRead", err)
 					return
 				}
+				as.Metrics.StreamMessages.With("stream", stream).Add(1)
 				rawAggTrade := struct {
 					Type         string  `json:"e"`
 					Time         float64 `json:"E"`
@@ -262,28 +374,28 @@ func (as *apiService) TradeWebsocket(twr TradeWebsocketRequest) (chan *AggTradeE
 					IsMaker      bool    `json:"m"`
 				}{}
 				if err := json.Unmarshal(message, &rawAggTrade); err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", string(message))
+					as.streamParseFailure(stream, err, string(message))
 					return
 				}
 				t, err := timeFromUnixTimestampFloat(rawAggTrade.Time)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", rawAggTrade.Time)
+					as.streamParseFailure(stream, err, rawAggTrade.Time)
 					return
 				}
 
-				price, err := floatFromString(rawAggTrade.Price)
+				price, err := decimalFromString(rawAggTrade.Price)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", rawAggTrade.Price)
+					as.streamParseFailure(stream, err, rawAggTrade.Price)
 					return
 				}
-				qty, err := floatFromString(rawAggTrade.Quantity)
+				qty, err := decimalFromString(rawAggTrade.Quantity)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", rawAggTrade.Quantity)
+					as.streamParseFailure(stream, err, rawAggTrade.Quantity)
 					return
 				}
 				ts, err := timeFromUnixTimestampFloat(rawAggTrade.Timestamp)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", rawAggTrade.Timestamp)
+					as.streamParseFailure(stream, err, rawAggTrade.Timestamp)
 					return
 				}
 
@@ -303,43 +415,50 @@ func (as *apiService) TradeWebsocket(twr TradeWebsocketRequest) (chan *AggTradeE
 						BuyerMaker:   rawAggTrade.IsMaker,
 					},
 				}
-				aggtech <- ae
+				select {
+				case aggtech <- ae:
+				case <-ctx.Done():
+					return
+				}
 			}
 		}
 	}()
 
-	go as.exitHandler(c, done)
+	go as.exitHandler(ctx, c, done)
 	return aggtech, done, nil
 }
 
-func (as *apiService) UserDataWebsocket(urwr UserDataWebsocketRequest) (chan *AccountEvent, chan struct{}, error) {
-	url := fmt.Sprintf("wss://stream.binance.com:9443/ws/%s", urwr.ListenKey)
-	c, _, err := websocket.DefaultDialer.Dial(url, nil)
+func (as *apiService) UserDataWebsocket(ctx context.Context, urwr UserDataWebsocketRequest) (chan *AccountEvent, chan struct{}, error) {
+	stream := "userData"
+	ctx, cancel := as.context(ctx)
+	c, err := as.dialStream(ctx, urwr.ListenKey, stream)
 	if err != nil {
-		log.Fatal("dial:", err)
+		cancel()
+		return nil, nil, err
 	}
-
 	done := make(chan struct{})
 	aech := make(chan *AccountEvent)
 
 	go func() {
 		defer c.Close()
 		defer close(done)
+		defer cancel()
+		defer as.Metrics.StreamConnections.With("stream", stream).Add(-1)
 		for {
 			select {
-			case <-as.Ctx.Done():
-				level.Info(as.Logger).Log("closing reader")
+			case <-ctx.Done():
+				as.log(LogStreams, LogInfo).Log("closing reader")
 				return
 			default:
 				_, message, err := c.ReadMessage()
 				if err != nil {
-					level.Error(as.Logger).Log("wsRead", err)
+					as.log(LogStreams, LogErrors).Log("wsRead", err)
 					return
 				}
+				as.Metrics.StreamMessages.With("stream", stream).Add(1)
 				rawAccount := struct {
 					Type            string  `json:"e"`
 					Time            float64 `json:"E"`
-					OpenTime        float64 `json:"t"`
 					MakerCommision  int64   `json:"m"`
 					TakerCommision  int64   `json:"t"`
 					BuyerCommision  int64   `json:"b"`
@@ -354,12 +473,12 @@ func (as *apiService) UserDataWebsocket(urwr UserDataWebsocketRequest) (chan *Ac
 					} `json:"B"`
 				}{}
 				if err := json.Unmarshal(message, &rawAccount); err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", string(message))
+					as.streamParseFailure(stream, err, string(message))
 					return
 				}
 				t, err := timeFromUnixTimestampFloat(rawAccount.Time)
 				if err != nil {
-					level.Error(as.Logger).Log("wsUnmarshal", err, "body", rawAccount.Time)
+					as.streamParseFailure(stream, err, rawAccount.Time)
 					return
 				}
 
@@ -379,14 +498,14 @@ func (as *apiService) UserDataWebsocket(urwr UserDataWebsocketRequest) (chan *Ac
 					},
 				}
 				for _, b := range rawAccount.Balances {
-					free, err := floatFromString(b.AvailableBalance)
+					free, err := decimalFromString(b.AvailableBalance)
 					if err != nil {
-						level.Error(as.Logger).Log("wsUnmarshal", err, "body", b.AvailableBalance)
+						as.streamParseFailure(stream, err, b.AvailableBalance)
 						return
 					}
-					locked, err := floatFromString(b.Locked)
+					locked, err := decimalFromString(b.Locked)
 					if err != nil {
-						level.Error(as.Logger).Log("wsUnmarshal", err, "body", b.Locked)
+						as.streamParseFailure(stream, err, b.Locked)
 						return
 					}
 					ae.Balances = append(ae.Balances, &Balance{
@@ -395,16 +514,70 @@ func (as *apiService) UserDataWebsocket(urwr UserDataWebsocketRequest) (chan *Ac
 						Locked: locked,
 					})
 				}
-				aech <- ae
+				select {
+				case aech <- ae:
+				case <-ctx.Done():
+					return
+				}
 			}
 		}
 	}()
 
-	go as.exitHandler(c, done)
+	go as.exitHandler(ctx, c, done)
 	return aech, done, nil
 }
 
-func (as *apiService) exitHandler(c *websocket.Conn, done chan struct{}) {
+// dialStream opens websocket connection to the stream. Label identifies the
+// stream in metrics.
+func (as *apiService) dialStream(ctx context.Context, path string, label string) (*websocket.Conn, error) {
+	return as.dial(ctx, fmt.Sprintf("%s/ws/%s", strings.TrimSuffix(as.StreamURL, "/"), path), label)
+}
+
+// dialCombinedStream opens single connection delivering all the streams
+// wrapped with their names.
+func (as *apiService) dialCombinedStream(ctx context.Context, streams []string, label string) (*websocket.Conn, error) {
+	return as.dial(ctx, fmt.Sprintf("%s/stream?streams=%s", strings.TrimSuffix(as.StreamURL, "/"), strings.Join(streams, "/")), label)
+}
+
+// dial opens the connection, dialing is canceled when ctx is done. Vendored
+// websocket has no DialContext, so the connection is closed if ctx is done
+// before the handshake finishes.
+func (as *apiService) dial(ctx context.Context, url string, label string) (*websocket.Conn, error) {
+	var stop func() bool
+	dialer := *websocket.DefaultDialer
+	dialer.NetDial = func(network, addr string) (net.Conn, error) {
+		conn, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		stop = context.AfterFunc(ctx, func() { conn.Close() })
+		return conn, nil
+	}
+	c, _, err := dialer.Dial(url, nil)
+	if stop != nil && !stop() {
+		// ctx was done, the connection is closed
+		if err == nil {
+			c.Close()
+		}
+		err = ctx.Err()
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to dial stream")
+	}
+	if as.streams.open(label) {
+		as.Metrics.StreamReconnects.With("stream", label).Add(1)
+	}
+	as.Metrics.StreamConnections.With("stream", label).Add(1)
+	return c, nil
+}
+
+// streamParseFailure logs and counts message which couldn't be parsed.
+func (as *apiService) streamParseFailure(stream string, err error, body interface{}) {
+	as.Metrics.StreamParseFailures.With("stream", stream).Add(1)
+	as.log(LogStreams, LogErrors).Log("wsUnmarshal", err, "body", body)
+}
+
+func (as *apiService) exitHandler(ctx context.Context, c *websocket.Conn, done chan struct{}) {
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 	defer c.Close()
@@ -414,15 +587,15 @@ func (as *apiService) exitHandler(c *websocket.Conn, done chan struct{}) {
 		case t := <-ticker.C:
 			err := c.WriteMessage(websocket.TextMessage, []byte(t.String()))
 			if err != nil {
-				level.Error(as.Logger).Log("wsWrite", err)
+				as.log(LogStreams, LogErrors).Log("wsWrite", err)
 				return
 			}
-		case <-as.Ctx.Done():
+		case <-ctx.Done():
 			select {
 			case <-done:
 			case <-time.After(time.Second):
 			}
-			level.Info(as.Logger).Log("closing connection")
+			as.log(LogStreams, LogInfo).Log("closing connection")
 			return
 		}
 	}
@@ -0,0 +1,112 @@
+package binance
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestCombinedStreamReconnects(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		c.ReadMessage()
+	}))
+	defer ts.Close()
+
+	reconnects := newTestCounter()
+	env := Environment{Name: "local", RESTURL: ts.URL, StreamURL: "ws" + strings.TrimPrefix(ts.URL, "http")}
+	s := NewAPIService("", "", nil, nil, nil, WithEnvironment(env),
+		WithMetrics(&Metrics{StreamReconnects: reconnects}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first, cancelFirst := context.WithCancel(ctx)
+	_, done, err := s.CombinedDepthWebsocket(first, CombinedDepthWebsocketRequest{Symbols: []string{"BNBETH", "ETHBTC"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := s.CombinedDepthWebsocket(ctx, CombinedDepthWebsocketRequest{Symbols: []string{"LTCBTC"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reconnects.values) != 0 {
+		t.Errorf("concurrent streams must take separate slots: %v", reconnects.values)
+	}
+
+	// connection reopened in freed slot is counted as its reconnect
+	cancelFirst()
+	<-done
+	if _, _, err := s.CombinedDepthWebsocket(ctx, CombinedDepthWebsocketRequest{Symbols: []string{"ETHBTC", "BNBBTC"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := reconnects.value("stream", "combined@depth#0"); v != 1 || len(reconnects.values) != 1 {
+		t.Errorf("expected reconnect of the first slot, got %v", reconnects.values)
+	}
+}
+
+func TestDepthWebsocketStopsWithoutReader(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		c.WriteMessage(websocket.TextMessage, []byte(`{"e":"depthUpdate","E":1499404630606,"s":"BNBETH","U":1,"u":1,"b":[],"a":[]}`))
+		c.ReadMessage()
+	}))
+	defer ts.Close()
+
+	env := Environment{Name: "local", RESTURL: ts.URL, StreamURL: "ws" + strings.TrimPrefix(ts.URL, "http")}
+	as := NewAPIService("", "", nil, nil, nil, WithEnvironment(env))
+	ctx, cancel := context.WithCancel(context.Background())
+	_, done, err := as.DepthWebsocket(ctx, DepthWebsocketRequest{Symbol: "BNBETH"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// event is never read, the reader has to stop on cancel anyway
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Error("reader not stopped")
+	}
+}
+
+func TestStreamDialCanceled(t *testing.T) {
+	// accepts connections but never completes the handshake
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	env := Environment{Name: "local", RESTURL: "http://" + l.Addr().String(), StreamURL: "ws://" + l.Addr().String()}
+	as := NewAPIService("", "", nil, nil, nil, WithEnvironment(env))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, _, err := as.KlineWebsocket(ctx, KlineWebsocketRequest{Symbol: "BNBETH", Interval: Minute}); err == nil {
+		t.Fatal("expected dial to fail")
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("dial not canceled with context, took %s", d)
+	}
+}
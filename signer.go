@@ -1,9 +1,17 @@
 package binance
 
 import (
+	"crypto"
+	"crypto/ed25519"
 	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"fmt"
+
+	"github.com/pkg/errors"
 )
 
 // Signer signs provided payloads.
@@ -12,6 +20,14 @@ type Signer interface {
 	Sign(payload []byte) string
 }
 
+// ErrSigner is implemented by signers which may fail. If Signer of the service
+// implements ErrSigner, SignErr is used instead of Sign and the call fails with
+// SigningError if the payload couldn't be signed.
+type ErrSigner interface {
+	// SignErr signs provided payload and returns encoded string sum.
+	SignErr(payload []byte) (string, error)
+}
+
 // HmacSigner uses HMAC SHA256 for signing payloads.
 type HmacSigner struct {
 	Key []byte
@@ -23,3 +39,74 @@ func (hs *HmacSigner) Sign(payload []byte) string {
 	mac.Write(payload)
 	return hex.EncodeToString(mac.Sum(nil))
 }
+
+// RSASigner uses RSASSA-PKCS1-v1_5 with SHA256 for signing payloads. Signature
+// is encoded by base64.
+type RSASigner struct {
+	Key *rsa.PrivateKey
+}
+
+// NewRSASigner creates RSASigner from PEM encoded PKCS#8 private key. Passphrase
+// is required only if the key is encrypted.
+func NewRSASigner(pemKey, passphrase []byte) (*RSASigner, error) {
+	key, err := parsePrivateKey(pemKey, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("expected RSA private key, got %T", key))
+	}
+	return &RSASigner{Key: rsaKey}, nil
+}
+
+// Sign signs provided payload and returns encoded string sum. It returns empty
+// string if the key is invalid, use SignErr to get the reason.
+func (rs *RSASigner) Sign(payload []byte) string {
+	sig, _ := rs.SignErr(payload)
+	return sig
+}
+
+// SignErr signs provided payload and returns encoded string sum.
+func (rs *RSASigner) SignErr(payload []byte) (string, error) {
+	digest := sha256.Sum256(payload)
+	sig, err := rsa.SignPKCS1v15(rand.Reader, rs.Key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", errors.Wrap(err, "unable to sign payload by RSA key")
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// Ed25519Signer uses Ed25519 for signing payloads. Signature is encoded by base64.
+type Ed25519Signer struct {
+	Key ed25519.PrivateKey
+}
+
+// NewEd25519Signer creates Ed25519Signer from PEM encoded PKCS#8 private key.
+// Passphrase is required only if the key is encrypted.
+func NewEd25519Signer(pemKey, passphrase []byte) (*Ed25519Signer, error) {
+	key, err := parsePrivateKey(pemKey, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("expected Ed25519 private key, got %T", key))
+	}
+	return &Ed25519Signer{Key: edKey}, nil
+}
+
+// Sign signs provided payload and returns encoded string sum. It returns empty
+// string if the key is invalid, use SignErr to get the reason.
+func (es *Ed25519Signer) Sign(payload []byte) string {
+	sig, _ := es.SignErr(payload)
+	return sig
+}
+
+// SignErr signs provided payload and returns encoded string sum.
+func (es *Ed25519Signer) SignErr(payload []byte) (string, error) {
+	if len(es.Key) != ed25519.PrivateKeySize {
+		return "", errors.New(fmt.Sprintf("invalid Ed25519 private key length %d", len(es.Key)))
+	}
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(es.Key, payload)), nil
+}
@@ -0,0 +1,115 @@
+package signerd
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rootpd/binance"
+)
+
+// Policy restricts requests which can be signed for the client.
+//
+// Signature covers only the payload, not the endpoint it's sent to, so payload
+// restrictions (symbols, notional, withdrawals) apply to every payload no matter
+// which endpoint the client declares.
+type Policy struct {
+	// Endpoints lists allowed requests as "METHOD endpoint", e.g. "POST api/v3/order".
+	// Empty list allows all endpoints.
+	Endpoints []string
+	// Symbols lists allowed symbols. Empty list allows all symbols.
+	Symbols []string
+	// MaxNotional limits price * quantity (or quoteOrderQty) of orders. Orders
+	// without price are refused when set. Zero means no limit.
+	MaxNotional binance.Decimal
+	// AllowWithdrawals allows signing payloads with withdrawal address.
+	AllowWithdrawals bool
+	// MaxTimestampSkew refuses payloads with timestamp further from current time.
+	// Zero disables the check.
+	MaxTimestampSkew time.Duration
+}
+
+// PolicyError is returned when request is refused by the policy.
+type PolicyError struct {
+	Reason string
+}
+
+// Error returns formatted error message.
+func (e PolicyError) Error() string {
+	return fmt.Sprintf("refused by policy: %s", e.Reason)
+}
+
+// Check returns PolicyError if the request isn't allowed.
+func (p Policy) Check(method, endpoint string, payload []byte, now time.Time) error {
+	if len(p.Endpoints) > 0 && !contains(p.Endpoints, method+" "+endpoint) {
+		return PolicyError{Reason: fmt.Sprintf("endpoint %s %s not allowed", method, endpoint)}
+	}
+	params, err := url.ParseQuery(string(payload))
+	if err != nil {
+		return PolicyError{Reason: "malformed payload"}
+	}
+	if symbol := params.Get("symbol"); symbol != "" && len(p.Symbols) > 0 && !contains(p.Symbols, symbol) {
+		return PolicyError{Reason: fmt.Sprintf("symbol %s not allowed", symbol)}
+	}
+	if params.Get("address") != "" && !p.AllowWithdrawals {
+		return PolicyError{Reason: "withdrawals not allowed"}
+	}
+	if !p.MaxNotional.IsZero() {
+		if err := p.checkNotional(params); err != nil {
+			return err
+		}
+	}
+	if p.MaxTimestampSkew > 0 {
+		ms, err := strconv.ParseInt(params.Get("timestamp"), 10, 64)
+		if err != nil {
+			return PolicyError{Reason: "missing timestamp"}
+		}
+		skew := now.Sub(time.Unix(0, ms*int64(time.Millisecond)))
+		if skew > p.MaxTimestampSkew || skew < -p.MaxTimestampSkew {
+			return PolicyError{Reason: fmt.Sprintf("timestamp skew %s exceeds %s", skew, p.MaxTimestampSkew)}
+		}
+	}
+	return nil
+}
+
+func (p Policy) checkNotional(params url.Values) error {
+	var notional binance.Decimal
+	switch {
+	case params.Get("quoteOrderQty") != "":
+		q, err := binance.ParseDecimal(params.Get("quoteOrderQty"))
+		if err != nil {
+			return PolicyError{Reason: "malformed quoteOrderQty"}
+		}
+		notional = q
+	case params.Get("quantity") != "":
+		if params.Get("price") == "" {
+			return PolicyError{Reason: "notional of order without price can't be checked"}
+		}
+		qty, err := binance.ParseDecimal(params.Get("quantity"))
+		if err != nil {
+			return PolicyError{Reason: "malformed quantity"}
+		}
+		price, err := binance.ParseDecimal(params.Get("price"))
+		if err != nil {
+			return PolicyError{Reason: "malformed price"}
+		}
+		notional = qty.Mul(price)
+	default:
+		return nil
+	}
+	if notional.Cmp(p.MaxNotional) > 0 {
+		return PolicyError{Reason: fmt.Sprintf("notional %s exceeds %s", notional, p.MaxNotional)}
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+// Package signerd implements signing daemon used by binance.RemoteSigner.
+//
+// The daemon holds API keys of its clients and signs only requests allowed by
+// policy of each client. Every signing request is written to the audit log.
+package signerd
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/rootpd/binance"
+)
+
+// Client is a process allowed to sign requests by the daemon.
+type Client struct {
+	// ID identifies the client.
+	ID string
+	// Token authenticates the client.
+	Token string
+	// Signer signs requests of the client, e.g. HmacSigner with its API secret.
+	Signer binance.Signer
+	// Policy restricts requests which can be signed.
+	Policy Policy
+}
+
+// Server signs requests of registered clients.
+type Server struct {
+	audit log.Logger
+	now   func() time.Time
+
+	mu      sync.RWMutex
+	clients map[string]Client
+}
+
+// NewServer creates signing server. Each signing request is logged to audit
+// logger, NopLogger is used if not provided.
+func NewServer(audit log.Logger, clients ...Client) *Server {
+	if audit == nil {
+		audit = log.NewNopLogger()
+	}
+	s := &Server{
+		audit:   audit,
+		now:     time.Now,
+		clients: make(map[string]Client),
+	}
+	for _, c := range clients {
+		s.clients[c.ID] = c
+	}
+	return s
+}
+
+// SetClient registers the client or replaces existing one with the same ID.
+func (s *Server) SetClient(c Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[c.ID] = c
+}
+
+// Serve accepts connections on the listener and serves signing requests. It
+// blocks until the listener is closed.
+func (s *Server) Serve(l net.Listener) error {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("Signer", &signerService{server: s}); err != nil {
+		return err
+	}
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go srv.ServeConn(conn)
+	}
+}
+
+// Sign checks the request against policy of the client and signs its payload.
+func (s *Server) Sign(args binance.SignArgs) (string, error) {
+	s.mu.RLock()
+	c, ok := s.clients[args.ClientID]
+	s.mu.RUnlock()
+
+	record := []interface{}{
+		"time", s.now().UTC(),
+		"client", args.ClientID,
+		"method", args.Method,
+		"endpoint", args.Endpoint,
+		"payload", string(args.Payload),
+	}
+	if !ok || subtle.ConstantTimeCompare([]byte(c.Token), []byte(args.Token)) != 1 {
+		s.audit.Log(append(record, "allowed", false, "reason", "unknown client or token")...)
+		return "", PolicyError{Reason: "unknown client or token"}
+	}
+	if err := c.Policy.Check(args.Method, args.Endpoint, args.Payload, s.now()); err != nil {
+		s.audit.Log(append(record, "allowed", false, "reason", err)...)
+		return "", err
+	}
+	s.audit.Log(append(record, "allowed", true)...)
+	if es, ok := c.Signer.(binance.ErrSigner); ok {
+		return es.SignErr(args.Payload)
+	}
+	return c.Signer.Sign(args.Payload), nil
+}
+
+// signerService exposes the server over net/rpc.
+type signerService struct {
+	server *Server
+}
+
+// Sign serves binance.RemoteSignerMethod.
+func (ss *signerService) Sign(args binance.SignArgs, reply *binance.SignReply) error {
+	sig, err := ss.server.Sign(args)
+	if err != nil {
+		return err
+	}
+	reply.Signature = sig
+	return nil
+}
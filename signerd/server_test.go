@@ -0,0 +1,99 @@
+package signerd
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/rootpd/binance"
+)
+
+func TestPolicy(t *testing.T) {
+	now := time.Unix(1499827319, 0)
+	p := Policy{
+		Endpoints:        []string{"POST api/v3/order"},
+		Symbols:          []string{"BNBETH"},
+		MaxNotional:      binance.MustParseDecimal("10"),
+		MaxTimestampSkew: time.Minute,
+	}
+	cases := []struct {
+		endpoint string
+		payload  string
+		allowed  bool
+	}{
+		{"api/v3/order", "symbol=BNBETH&quantity=1&price=9.5&timestamp=1499827319559", true},
+		{"api/v3/order", "symbol=BNBETH&quantity=2&price=9.5&timestamp=1499827319559", false},
+		{"api/v3/order", "symbol=BNBETH&quantity=2&timestamp=1499827319559", false},
+		{"api/v3/order", "symbol=BNBETH&quoteOrderQty=5&timestamp=1499827319559", true},
+		{"api/v3/order", "symbol=ETHBTC&quantity=1&price=1&timestamp=1499827319559", false},
+		{"api/v3/order", "symbol=BNBETH&quantity=1&price=1&timestamp=1499827019559", false},
+		{"api/v3/account", "timestamp=1499827319559", false},
+		{"api/v3/order", "asset=ETH&address=0x1&amount=1&timestamp=1499827319559", false},
+	}
+	for _, c := range cases {
+		err := p.Check("POST", c.endpoint, []byte(c.payload), now)
+		if c.allowed && err != nil {
+			t.Errorf("%s: unexpected error: %v", c.payload, err)
+		}
+		if !c.allowed && err == nil {
+			t.Errorf("%s: expected refusal", c.payload)
+		}
+	}
+}
+
+func TestRemoteSigner(t *testing.T) {
+	secret := []byte("NhqPtmdSJYdKjVHjA7PZj4Mge3R5YNiP1e3UZjInClVN65XAbvqqM6A7H5fATj0j")
+	var audit bytes.Buffer
+	s := NewServer(log.NewLogfmtLogger(&audit), Client{
+		ID:     "bot",
+		Token:  "token",
+		Signer: &binance.HmacSigner{Key: secret},
+		Policy: Policy{Symbols: []string{"LTCBTC"}},
+	})
+
+	socket := filepath.Join(t.TempDir(), "signerd.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer l.Close()
+	go s.Serve(l)
+
+	payload := []byte("symbol=LTCBTC&side=BUY&type=LIMIT&timeInForce=GTC&quantity=1&price=0.1&recvWindow=5000&timestamp=1499827319559")
+	rs := binance.NewRemoteSigner(socket, "bot", "token")
+	defer rs.Close()
+	sig, err := rs.SignRequest(context.Background(), "POST", "api/v3/order", payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sig != "c8db56825ae71d6d79447849e617115f4a920fa2acdcab2b053c4b2838bd6b71" {
+		t.Errorf("remote signer returned invalid signature: %s", sig)
+	}
+
+	_, err = rs.SignRequest(context.Background(), "POST", "api/v3/order", []byte("symbol=ETHBTC"))
+	if _, ok := err.(binance.SigningError); !ok {
+		t.Errorf("expected SigningError, got %v", err)
+	}
+
+	intruder := binance.NewRemoteSigner(socket, "bot", "guess")
+	defer intruder.Close()
+	if sig := intruder.Sign(payload); sig != "" {
+		t.Errorf("signed payload of client with invalid token: %s", sig)
+	}
+
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 audit records, got %d: %s", len(lines), audit.String())
+	}
+	if !strings.Contains(lines[0], "allowed=true") || !strings.Contains(lines[1], "symbol ETHBTC not allowed") {
+		t.Errorf("unexpected audit log: %s", audit.String())
+	}
+	if strings.Contains(audit.String(), string(secret)) || strings.Contains(audit.String(), sig) {
+		t.Error("audit log contains secret or signature")
+	}
+}
@@ -0,0 +1,138 @@
+package sim
+
+import (
+	"sort"
+
+	"github.com/rootpd/binance"
+)
+
+// book keeps resting orders of the symbol, best price first and orders of the
+// same price by time.
+type book struct {
+	bids []*order
+	asks []*order
+
+	trades   []*binance.AggTrade
+	updateID int
+
+	// touched price levels since last depth event
+	touchedBids map[string]binance.Decimal
+	touchedAsks map[string]binance.Decimal
+}
+
+func (b *book) side(side binance.OrderSide) *[]*order {
+	if side == binance.SideBuy {
+		return &b.bids
+	}
+	return &b.asks
+}
+
+func (b *book) best(side binance.OrderSide) *order {
+	orders := *b.side(side)
+	if len(orders) == 0 {
+		return nil
+	}
+	return orders[0]
+}
+
+func (b *book) add(o *order) {
+	orders := b.side(o.side)
+	i := sort.Search(len(*orders), func(i int) bool {
+		c := (*orders)[i].price.Cmp(o.price)
+		if o.side == binance.SideBuy {
+			return c < 0
+		}
+		return c > 0
+	})
+	*orders = append(*orders, nil)
+	copy((*orders)[i+1:], (*orders)[i:])
+	(*orders)[i] = o
+}
+
+func (b *book) remove(o *order) {
+	orders := b.side(o.side)
+	for i, other := range *orders {
+		if other == o {
+			*orders = append((*orders)[:i], (*orders)[i+1:]...)
+			return
+		}
+	}
+}
+
+func (b *book) touch(side binance.OrderSide, price binance.Decimal) {
+	touched := &b.touchedAsks
+	if side == binance.SideBuy {
+		touched = &b.touchedBids
+	}
+	if *touched == nil {
+		*touched = make(map[string]binance.Decimal)
+	}
+	(*touched)[price.String()] = price
+}
+
+// cost returns quote amount needed to buy qty from asks, or to buy all asks
+// if there isn't enough of them.
+func (b *book) cost(qty binance.Decimal) binance.Decimal {
+	var cost binance.Decimal
+	for _, o := range b.asks {
+		if qty.Sign() <= 0 {
+			break
+		}
+		fill := minDecimal(qty, o.remaining())
+		cost = cost.Add(o.price.Mul(fill))
+		qty = qty.Sub(fill)
+	}
+	return cost
+}
+
+// levels aggregates orders of the side by price, at most limit levels if
+// limit is positive.
+func (b *book) levels(side binance.OrderSide, limit int) []*binance.Order {
+	var levels []*binance.Order
+	for _, o := range *b.side(side) {
+		if n := len(levels); n > 0 && levels[n-1].Price.Equal(o.price) {
+			levels[n-1].Quantity = levels[n-1].Quantity.Add(o.remaining())
+			continue
+		}
+		if limit > 0 && len(levels) == limit {
+			break
+		}
+		levels = append(levels, &binance.Order{Price: o.price, Quantity: o.remaining()})
+	}
+	return levels
+}
+
+// quantity returns total quantity of the side at price.
+func (b *book) quantity(side binance.OrderSide, price binance.Decimal) binance.Decimal {
+	var qty binance.Decimal
+	for _, o := range *b.side(side) {
+		if o.price.Equal(price) {
+			qty = qty.Add(o.remaining())
+		}
+	}
+	return qty
+}
+
+// changes returns touched levels with their current quantity, zero for
+// removed levels, and resets them.
+func (b *book) changes() (bids, asks []*binance.Order) {
+	bids = b.changed(binance.SideBuy, b.touchedBids)
+	asks = b.changed(binance.SideSell, b.touchedAsks)
+	b.touchedBids, b.touchedAsks = nil, nil
+	return bids, asks
+}
+
+func (b *book) changed(side binance.OrderSide, touched map[string]binance.Decimal) []*binance.Order {
+	var levels []*binance.Order
+	for _, price := range touched {
+		levels = append(levels, &binance.Order{Price: price, Quantity: b.quantity(side, price)})
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		c := levels[i].Price.Cmp(levels[j].Price)
+		if side == binance.SideBuy {
+			return c > 0
+		}
+		return c < 0
+	})
+	return levels
+}
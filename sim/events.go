@@ -0,0 +1,91 @@
+package sim
+
+import (
+	"sort"
+
+	"github.com/rootpd/binance"
+)
+
+// Listener receives events of the exchange. Callbacks are called synchronously
+// while the exchange is locked, they must not block nor call the exchange.
+type Listener struct {
+	Depth   func(*binance.DepthEvent)
+	Trade   func(*binance.AggTradeEvent)
+	Account func(*binance.AccountEvent)
+}
+
+// Subscribe registers the listener and returns function which unregisters it.
+func (e *Exchange) Subscribe(l Listener) func() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	id := e.nextListen
+	e.nextListen++
+	e.listeners[id] = l
+	return func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		delete(e.listeners, id)
+	}
+}
+
+func (e *Exchange) emit(fn func(Listener)) {
+	ids := make([]int, 0, len(e.listeners))
+	for id := range e.listeners {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		fn(e.listeners[id])
+	}
+}
+
+// flush emits depth events of changed books and account event if balances changed.
+func (e *Exchange) flush() {
+	now := e.now()
+	for _, symbol := range e.sortedSymbols() {
+		bk := e.books[symbol]
+		if bk.touchedBids == nil && bk.touchedAsks == nil {
+			continue
+		}
+		bk.updateID++
+		bids, asks := bk.changes()
+		event := &binance.DepthEvent{
+			WSEvent:       binance.WSEvent{Type: "depthUpdate", Time: now, Symbol: symbol},
+			FirstUpdateID: bk.updateID,
+			UpdateID:      bk.updateID,
+			OrderBook: binance.OrderBook{
+				LastUpdateID: bk.updateID,
+				Bids:         bids,
+				Asks:         asks,
+			},
+		}
+		e.emit(func(l Listener) {
+			if l.Depth != nil {
+				l.Depth(event)
+			}
+		})
+	}
+
+	if !e.accountChanged {
+		return
+	}
+	e.accountChanged = false
+	account := e.account()
+	e.emit(func(l Listener) {
+		if l.Account != nil {
+			l.Account(&binance.AccountEvent{
+				WSEvent: binance.WSEvent{Type: "outboundAccountInfo", Time: now},
+				Account: *account,
+			})
+		}
+	})
+}
+
+func (e *Exchange) sortedSymbols() []string {
+	symbols := make([]string, 0, len(e.symbols))
+	for s := range e.symbols {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
@@ -0,0 +1,626 @@
+// Package sim implements deterministic in-memory exchange with matching engine.
+//
+// Exchange keeps order books per symbol and balances of single account. Orders
+// of the account are matched by price-time priority against resting orders of
+// the account and liquidity added by AddLiquidity, balances move on fills.
+// The same engine backs binancetest.FakeService, paper trading and backtests.
+package sim
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rootpd/binance"
+)
+
+// Symbol describes traded pair.
+type Symbol struct {
+	Name       string
+	BaseAsset  string
+	QuoteAsset string
+	// Filters are published in exchange info of fake services, orders aren't
+	// checked against them.
+	Filters binance.SymbolFilters
+}
+
+type balance struct {
+	free   binance.Decimal
+	locked binance.Decimal
+}
+
+type order struct {
+	id            int64
+	clientOrderID string
+	symbol        string
+	side          binance.OrderSide
+	typ           binance.OrderType
+	timeInForce   binance.TimeInForce
+	price         binance.Decimal
+	quantity      binance.Decimal
+	executed      binance.Decimal
+	status        binance.OrderStatus
+	time          time.Time
+	// own is true for orders of the account, false for added liquidity
+	own bool
+	// locked is amount of asset still locked by the order
+	locked binance.Decimal
+}
+
+func (o *order) remaining() binance.Decimal {
+	return o.quantity.Sub(o.executed)
+}
+
+func (o *order) open() bool {
+	return o.status == binance.StatusNew || o.status == binance.StatusPartiallyFilled
+}
+
+func (o *order) executedOrder() *binance.ExecutedOrder {
+	return &binance.ExecutedOrder{
+		Symbol:        o.symbol,
+		OrderID:       int(o.id),
+		ClientOrderID: o.clientOrderID,
+		Price:         o.price,
+		OrigQty:       o.quantity,
+		ExecutedQty:   o.executed,
+		Status:        o.status,
+		TimeInForce:   o.timeInForce,
+		Type:          o.typ,
+		Side:          o.side,
+		Time:          o.time,
+	}
+}
+
+// Exchange is in-memory exchange. It's safe for concurrent use.
+type Exchange struct {
+	mu  sync.Mutex
+	now func() time.Time
+
+	symbols  map[string]Symbol
+	books    map[string]*book
+	balances map[string]*balance
+	maker    binance.Decimal
+	taker    binance.Decimal
+
+	orders      []*order
+	trades      []*binance.Trade
+	tradeSymbol map[int64]string
+	deposits    []*binance.Deposit
+	withdrawals []*binance.Withdrawal
+
+	nextOrderID int64
+	nextTradeID int64
+
+	listeners      map[int]Listener
+	nextListen     int
+	accountChanged bool
+}
+
+// NewExchange creates empty exchange. Clock provides time of orders and trades,
+// time.Now is used if nil.
+func NewExchange(clock func() time.Time) *Exchange {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &Exchange{
+		now:         clock,
+		symbols:     make(map[string]Symbol),
+		books:       make(map[string]*book),
+		balances:    make(map[string]*balance),
+		tradeSymbol: make(map[int64]string),
+		listeners:   make(map[int]Listener),
+		nextOrderID: 1,
+		nextTradeID: 1,
+	}
+}
+
+// Now returns current time of the exchange clock.
+func (e *Exchange) Now() time.Time {
+	return e.now()
+}
+
+// AddSymbol lists new symbol on the exchange.
+func (e *Exchange) AddSymbol(s Symbol) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.symbols[s.Name] = s
+	if _, ok := e.books[s.Name]; !ok {
+		e.books[s.Name] = &book{}
+	}
+}
+
+// Symbols returns listed symbols sorted by name.
+func (e *Exchange) Symbols() []Symbol {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var symbols []Symbol
+	for _, s := range e.symbols {
+		symbols = append(symbols, s)
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].Name < symbols[j].Name })
+	return symbols
+}
+
+// SetCommission sets commission rate of both maker and taker, e.g. 0.001. The
+// commission is paid in received asset.
+func (e *Exchange) SetCommission(rate binance.Decimal) {
+	e.SetCommissions(rate, rate)
+}
+
+// SetCommissions sets commission rates of maker and taker separately.
+func (e *Exchange) SetCommissions(maker, taker binance.Decimal) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.maker, e.taker = maker, taker
+}
+
+// SetBalance sets free balance of the asset.
+func (e *Exchange) SetBalance(asset string, free binance.Decimal) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.balance(asset).free = free
+	e.accountChanged = true
+	e.flush()
+}
+
+// Deposit credits the asset and records the deposit.
+func (e *Exchange) Deposit(asset string, amount binance.Decimal) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	b := e.balance(asset)
+	b.free = b.free.Add(amount)
+	e.deposits = append(e.deposits, &binance.Deposit{
+		InsertTime: e.now(),
+		Amount:     amount,
+		Asset:      asset,
+		Status:     1,
+	})
+	e.accountChanged = true
+	e.flush()
+}
+
+// Withdraw debits the asset and records the withdrawal.
+func (e *Exchange) Withdraw(asset, address string, amount binance.Decimal) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	b := e.balance(asset)
+	if amount.Sign() <= 0 || b.free.Cmp(amount) < 0 {
+		return insufficientBalance()
+	}
+	b.free = b.free.Sub(amount)
+	e.withdrawals = append(e.withdrawals, &binance.Withdrawal{
+		Amount:    amount,
+		Address:   address,
+		TxID:      fmt.Sprintf("sim-%d", len(e.withdrawals)+1),
+		Asset:     asset,
+		ApplyTime: e.now(),
+		Status:    6,
+	})
+	e.accountChanged = true
+	e.flush()
+	return nil
+}
+
+// Deposits returns deposits of the asset, all assets if empty.
+func (e *Exchange) Deposits(asset string) []*binance.Deposit {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var deposits []*binance.Deposit
+	for _, d := range e.deposits {
+		if asset == "" || d.Asset == asset {
+			c := *d
+			deposits = append(deposits, &c)
+		}
+	}
+	return deposits
+}
+
+// Withdrawals returns withdrawals of the asset, all assets if empty.
+func (e *Exchange) Withdrawals(asset string) []*binance.Withdrawal {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var withdrawals []*binance.Withdrawal
+	for _, w := range e.withdrawals {
+		if asset == "" || w.Asset == asset {
+			c := *w
+			withdrawals = append(withdrawals, &c)
+		}
+	}
+	return withdrawals
+}
+
+// Account returns balances of the account.
+func (e *Exchange) Account() *binance.Account {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.account()
+}
+
+func (e *Exchange) account() *binance.Account {
+	// commission is reported in basis points
+	bps := binance.NewDecimalFromInt(10000)
+	a := &binance.Account{
+		MakerCommision:  int64(e.maker.Mul(bps).Float64()),
+		TakerCommision:  int64(e.taker.Mul(bps).Float64()),
+		BuyerCommision:  0,
+		SellerCommision: 0,
+		CanTrade:        true,
+		CanWithdraw:     true,
+		CanDeposit:      true,
+	}
+	var assets []string
+	for asset := range e.balances {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	for _, asset := range assets {
+		b := e.balances[asset]
+		a.Balances = append(a.Balances, &binance.Balance{
+			Asset:  asset,
+			Free:   b.free,
+			Locked: b.locked,
+		})
+	}
+	return a
+}
+
+func (e *Exchange) balance(asset string) *balance {
+	b, ok := e.balances[asset]
+	if !ok {
+		b = &balance{}
+		e.balances[asset] = b
+	}
+	return b
+}
+
+// PlaceOrder places order of the account and matches it immediately.
+func (e *Exchange) PlaceOrder(req binance.NewOrderRequest) (*binance.ExecutedOrder, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o, err := e.newOrder(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.lock(o); err != nil {
+		return nil, err
+	}
+	e.orders = append(e.orders, o)
+	e.match(o)
+	e.flush()
+	return o.executedOrder(), nil
+}
+
+// CheckOrder validates the order without placing it, like NewOrderTest.
+func (e *Exchange) CheckOrder(req binance.NewOrderRequest) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := e.newOrder(req)
+	return err
+}
+
+func (e *Exchange) newOrder(req binance.NewOrderRequest) (*order, error) {
+	if _, ok := e.symbols[req.Symbol]; !ok {
+		return nil, invalidSymbol()
+	}
+	if req.Side != binance.SideBuy && req.Side != binance.SideSell {
+		return nil, apiError(-1102, "Mandatory parameter 'side' was not sent, was empty/null, or malformed.")
+	}
+	if req.Quantity.Sign() <= 0 {
+		return nil, apiError(-1013, "Filter failure: LOT_SIZE")
+	}
+	o := &order{
+		clientOrderID: req.NewClientOrderID,
+		symbol:        req.Symbol,
+		side:          req.Side,
+		typ:           req.Type,
+		timeInForce:   req.TimeInForce,
+		price:         req.Price,
+		quantity:      req.Quantity,
+		status:        binance.StatusNew,
+		time:          e.now(),
+		own:           true,
+	}
+	switch req.Type {
+	case binance.TypeLimit:
+		if req.Price.Sign() <= 0 {
+			return nil, apiError(-1013, "Filter failure: PRICE_FILTER")
+		}
+		if o.timeInForce == "" {
+			o.timeInForce = binance.GTC
+		}
+	case binance.TypeMarket:
+		o.price = binance.Decimal{}
+		o.timeInForce = ""
+	default:
+		return nil, apiError(-1116, "Invalid orderType.")
+	}
+	if o.clientOrderID != "" {
+		for _, other := range e.orders {
+			if other.clientOrderID == o.clientOrderID && other.open() {
+				return nil, apiError(-2010, "Duplicate order sent.")
+			}
+		}
+	}
+	return o, nil
+}
+
+// lock reserves balance needed by the order and assigns its ID.
+func (e *Exchange) lock(o *order) error {
+	s := e.symbols[o.symbol]
+	asset, amount := s.BaseAsset, o.quantity
+	if o.side == binance.SideBuy {
+		asset = s.QuoteAsset
+		if o.typ == binance.TypeMarket {
+			amount = e.books[o.symbol].cost(o.quantity)
+		} else {
+			amount = o.price.Mul(o.quantity)
+		}
+	}
+	b := e.balance(asset)
+	if b.free.Cmp(amount) < 0 {
+		return insufficientBalance()
+	}
+	b.free = b.free.Sub(amount)
+	b.locked = b.locked.Add(amount)
+	o.locked = amount
+	o.id = e.nextOrderID
+	e.nextOrderID++
+	if o.clientOrderID == "" {
+		o.clientOrderID = fmt.Sprintf("sim-%d", o.id)
+	}
+	e.accountChanged = true
+	return nil
+}
+
+// release returns remaining locked balance of closed order.
+func (e *Exchange) release(o *order) {
+	if !o.own || o.locked.IsZero() {
+		return
+	}
+	s := e.symbols[o.symbol]
+	asset := s.BaseAsset
+	if o.side == binance.SideBuy {
+		asset = s.QuoteAsset
+	}
+	b := e.balance(asset)
+	b.locked = b.locked.Sub(o.locked)
+	b.free = b.free.Add(o.locked)
+	o.locked = binance.Decimal{}
+	e.accountChanged = true
+}
+
+// match matches taker order against the book and rests or expires the remainder.
+// Liquidity of other participants is matched only against orders of the account.
+func (e *Exchange) match(taker *order) {
+	bk := e.books[taker.symbol]
+	for skipped := 0; taker.remaining().Sign() > 0; {
+		makers := *bk.side(opposite(taker.side))
+		if skipped == len(makers) {
+			break
+		}
+		maker := makers[skipped]
+		if taker.typ == binance.TypeLimit && !crosses(taker, maker.price) {
+			break
+		}
+		if !taker.own && !maker.own {
+			skipped++
+			continue
+		}
+		qty := minDecimal(taker.remaining(), maker.remaining())
+		e.fill(maker, taker, maker.price, qty)
+		if maker.remaining().IsZero() {
+			bk.remove(maker)
+		}
+		bk.touch(maker.side, maker.price)
+	}
+
+	switch {
+	case taker.remaining().IsZero():
+	case taker.typ == binance.TypeLimit && taker.timeInForce == binance.GTC:
+		bk.add(taker)
+		bk.touch(taker.side, taker.price)
+		return
+	default:
+		taker.status = binance.StatusExpired
+	}
+	e.release(taker)
+}
+
+// fill executes qty of maker and taker order at price.
+func (e *Exchange) fill(maker, taker *order, price, qty binance.Decimal) {
+	s := e.symbols[taker.symbol]
+	now := e.now()
+	for _, o := range []*order{maker, taker} {
+		o.executed = o.executed.Add(qty)
+		if o.remaining().IsZero() {
+			o.status = binance.StatusFilled
+		} else {
+			o.status = binance.StatusPartiallyFilled
+		}
+		if !o.own {
+			continue
+		}
+		e.settle(s, o, price, qty, o == maker, now)
+	}
+	e.recordPublicTrade(taker.symbol, price, qty, maker.side == binance.SideBuy, now)
+}
+
+// settle moves balances of own order filled by qty at price and records the trade.
+func (e *Exchange) settle(s Symbol, o *order, price, qty binance.Decimal, maker bool, now time.Time) {
+	cost := price.Mul(qty)
+	trade := &binance.Trade{
+		ID:          e.nextTradeID,
+		Price:       price,
+		Qty:         qty,
+		Time:        now,
+		IsBuyer:     o.side == binance.SideBuy,
+		IsMaker:     maker,
+		IsBestMatch: true,
+	}
+	e.nextTradeID++
+	rate := e.taker
+	if maker {
+		rate = e.maker
+	}
+	if o.side == binance.SideBuy {
+		// lock of limit order was computed with its price, market order locked exact cost
+		used := cost
+		if o.typ == binance.TypeLimit {
+			used = o.price.Mul(qty)
+		}
+		used = minDecimal(used, o.locked)
+		quote := e.balance(s.QuoteAsset)
+		quote.locked = quote.locked.Sub(used)
+		quote.free = quote.free.Add(used.Sub(cost))
+		o.locked = o.locked.Sub(used)
+
+		trade.Commission = qty.Mul(rate)
+		trade.CommissionAsset = s.BaseAsset
+		base := e.balance(s.BaseAsset)
+		base.free = base.free.Add(qty.Sub(trade.Commission))
+	} else {
+		base := e.balance(s.BaseAsset)
+		base.locked = base.locked.Sub(qty)
+		o.locked = o.locked.Sub(qty)
+
+		trade.Commission = cost.Mul(rate)
+		trade.CommissionAsset = s.QuoteAsset
+		quote := e.balance(s.QuoteAsset)
+		quote.free = quote.free.Add(cost.Sub(trade.Commission))
+	}
+	if o.remaining().IsZero() && o.locked.Sign() != 0 {
+		// market buy filled cheaper than estimated
+		e.release(o)
+	}
+	e.trades = append(e.trades, trade)
+	e.tradeSymbol[trade.ID] = s.Name
+	e.accountChanged = true
+}
+
+// CancelOrder cancels open order of the account identified by ID or client order ID.
+func (e *Exchange) CancelOrder(symbol string, orderID int64, origClientOrderID string) (*binance.ExecutedOrder, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o := e.find(symbol, orderID, origClientOrderID)
+	if o == nil || !o.open() {
+		return nil, apiError(-2011, "Unknown order sent.")
+	}
+	o.status = binance.StatusCancelled
+	bk := e.books[symbol]
+	bk.remove(o)
+	bk.touch(o.side, o.price)
+	e.release(o)
+	e.flush()
+	return o.executedOrder(), nil
+}
+
+// QueryOrder returns order of the account identified by ID or client order ID.
+func (e *Exchange) QueryOrder(symbol string, orderID int64, origClientOrderID string) (*binance.ExecutedOrder, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o := e.find(symbol, orderID, origClientOrderID)
+	if o == nil {
+		return nil, apiError(-2013, "Order does not exist.")
+	}
+	return o.executedOrder(), nil
+}
+
+func (e *Exchange) find(symbol string, orderID int64, clientOrderID string) *order {
+	for i := len(e.orders) - 1; i >= 0; i-- {
+		o := e.orders[i]
+		if o.symbol != symbol {
+			continue
+		}
+		if (orderID != 0 && o.id == orderID) || (orderID == 0 && clientOrderID != "" && o.clientOrderID == clientOrderID) {
+			return o
+		}
+	}
+	return nil
+}
+
+// OpenOrders returns open orders of the account for the symbol, all symbols if empty.
+func (e *Exchange) OpenOrders(symbol string) []*binance.ExecutedOrder {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var orders []*binance.ExecutedOrder
+	for _, o := range e.orders {
+		if o.open() && (symbol == "" || o.symbol == symbol) {
+			orders = append(orders, o.executedOrder())
+		}
+	}
+	return orders
+}
+
+// AllOrders returns orders of the account for the symbol with ID from fromID,
+// at most limit orders if limit is positive.
+func (e *Exchange) AllOrders(symbol string, fromID int64, limit int) []*binance.ExecutedOrder {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var orders []*binance.ExecutedOrder
+	for _, o := range e.orders {
+		if o.symbol == symbol && o.id >= fromID {
+			orders = append(orders, o.executedOrder())
+		}
+		if limit > 0 && len(orders) == limit {
+			break
+		}
+	}
+	return orders
+}
+
+// MyTrades returns trades of the account for the symbol with ID from fromID,
+// at most limit trades if limit is positive.
+func (e *Exchange) MyTrades(symbol string, fromID int64, limit int) []*binance.Trade {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var trades []*binance.Trade
+	for _, t := range e.trades {
+		if e.tradeSymbol[t.ID] == symbol && t.ID >= fromID {
+			c := *t
+			trades = append(trades, &c)
+		}
+		if limit > 0 && len(trades) == limit {
+			break
+		}
+	}
+	return trades
+}
+
+func opposite(side binance.OrderSide) binance.OrderSide {
+	if side == binance.SideBuy {
+		return binance.SideSell
+	}
+	return binance.SideBuy
+}
+
+// crosses reports whether limit order can be executed at price.
+func crosses(o *order, price binance.Decimal) bool {
+	if o.side == binance.SideBuy {
+		return price.Cmp(o.price) <= 0
+	}
+	return price.Cmp(o.price) >= 0
+}
+
+func minDecimal(a, b binance.Decimal) binance.Decimal {
+	if a.Cmp(b) < 0 {
+		return a
+	}
+	return b
+}
+
+func apiError(code int, msg string) error {
+	return &binance.Error{
+		Code:       code,
+		Message:    msg,
+		StatusCode: 400,
+	}
+}
+
+func insufficientBalance() error {
+	return apiError(-2010, "Account has insufficient balance for requested action.")
+}
+
+func invalidSymbol() error {
+	return apiError(-1121, "Invalid symbol.")
+}
@@ -0,0 +1,234 @@
+package sim
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rootpd/binance"
+)
+
+var d = binance.MustParseDecimal
+
+func newTestExchange() *Exchange {
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := NewExchange(func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	})
+	e.AddSymbol(Symbol{Name: "BNBBTC", BaseAsset: "BNB", QuoteAsset: "BTC"})
+	e.SetBalance("BTC", d("1"))
+	e.SetBalance("BNB", d("10"))
+	return e
+}
+
+func balances(e *Exchange) map[string][2]string {
+	m := make(map[string][2]string)
+	for _, b := range e.Account().Balances {
+		m[b.Asset] = [2]string{b.Free.String(), b.Locked.String()}
+	}
+	return m
+}
+
+func TestLimitOrderLifecycle(t *testing.T) {
+	e := newTestExchange()
+	e.SetCommission(d("0.001"))
+
+	o, err := e.PlaceOrder(binance.NewOrderRequest{
+		Symbol: "BNBBTC", Side: binance.SideBuy, Type: binance.TypeLimit,
+		Quantity: d("2"), Price: d("0.1"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.Status != binance.StatusNew || o.TimeInForce != binance.GTC {
+		t.Errorf("unexpected order: %+v", o)
+	}
+	if b := balances(e)["BTC"]; b != [2]string{"0.8", "0.2"} {
+		t.Errorf("unexpected BTC balance: %v", b)
+	}
+
+	// resting order is filled at its own price by crossing liquidity
+	e.AddLiquidity("BNBBTC", binance.SideSell, d("0.09"), d("0.5"))
+	o, _ = e.QueryOrder("BNBBTC", int64(o.OrderID), "")
+	if o.Status != binance.StatusPartiallyFilled || !o.ExecutedQty.Equal(d("0.5")) {
+		t.Errorf("unexpected order: %+v", o)
+	}
+	e.AddLiquidity("BNBBTC", binance.SideSell, d("0.09"), d("2"))
+	o, _ = e.QueryOrder("BNBBTC", 0, o.ClientOrderID)
+	if o.Status != binance.StatusFilled {
+		t.Errorf("unexpected order: %+v", o)
+	}
+
+	b := balances(e)
+	if b["BTC"] != [2]string{"0.8", "0"} || b["BNB"] != [2]string{"11.998", "0"} {
+		t.Errorf("unexpected balances: %v", b)
+	}
+	trades := e.MyTrades("BNBBTC", 0, 0)
+	if len(trades) != 2 || !trades[0].IsBuyer || !trades[0].IsMaker || !trades[1].Price.Equal(d("0.1")) ||
+		trades[1].CommissionAsset != "BNB" || !trades[1].Commission.Equal(d("0.0015")) {
+		t.Errorf("unexpected trades: %+v %+v", trades[0], trades[1])
+	}
+
+	book, _ := e.OrderBook("BNBBTC", 0)
+	if len(book.Bids) != 0 || len(book.Asks) != 1 || !book.Asks[0].Quantity.Equal(d("0.5")) {
+		t.Errorf("unexpected book: %+v", book)
+	}
+}
+
+func TestMarketOrderAndCancel(t *testing.T) {
+	e := newTestExchange()
+	e.AddLiquidity("BNBBTC", binance.SideBuy, d("0.1"), d("1"))
+	e.AddLiquidity("BNBBTC", binance.SideBuy, d("0.09"), d("1"))
+	e.AddLiquidity("BNBBTC", binance.SideBuy, d("0.1"), d("1"))
+
+	o, err := e.PlaceOrder(binance.NewOrderRequest{
+		Symbol: "BNBBTC", Side: binance.SideSell, Type: binance.TypeMarket, Quantity: d("5"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.Status != binance.StatusExpired || !o.ExecutedQty.Equal(d("3")) {
+		t.Errorf("unexpected order: %+v", o)
+	}
+	if b := balances(e); b["BNB"] != [2]string{"7", "0"} || b["BTC"] != [2]string{"1.29", "0"} {
+		t.Errorf("unexpected balances: %v", b)
+	}
+
+	if _, err := e.PlaceOrder(binance.NewOrderRequest{
+		Symbol: "BNBBTC", Side: binance.SideSell, Type: binance.TypeLimit, Quantity: d("8"), Price: d("1"),
+	}); !errors.Is(err, binance.ErrInsufficientBalance) {
+		t.Errorf("expected insufficient balance, got %v", err)
+	}
+	o, _ = e.PlaceOrder(binance.NewOrderRequest{
+		Symbol: "BNBBTC", Side: binance.SideSell, Type: binance.TypeLimit, Quantity: d("7"), Price: d("1"),
+		NewClientOrderID: "mine",
+	})
+	if _, err := e.CancelOrder("BNBBTC", 0, "mine"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := e.CancelOrder("BNBBTC", int64(o.OrderID), ""); err == nil {
+		t.Error("expected error when cancelling twice")
+	}
+	if b := balances(e); b["BNB"] != [2]string{"7", "0"} {
+		t.Errorf("unexpected balances: %v", b)
+	}
+	if orders := e.AllOrders("BNBBTC", 0, 0); len(orders) != 2 || orders[1].Status != binance.StatusCancelled {
+		t.Errorf("unexpected orders: %+v", orders)
+	}
+}
+
+func TestExecuteTradeAndEvents(t *testing.T) {
+	e := newTestExchange()
+	var depth []*binance.DepthEvent
+	var accounts int
+	unsubscribe := e.Subscribe(Listener{
+		Depth:   func(de *binance.DepthEvent) { depth = append(depth, de) },
+		Account: func(*binance.AccountEvent) { accounts++ },
+	})
+	defer unsubscribe()
+
+	e.PlaceOrder(binance.NewOrderRequest{
+		Symbol: "BNBBTC", Side: binance.SideBuy, Type: binance.TypeLimit, Quantity: d("1"), Price: d("0.1"),
+	})
+	e.ExecuteTrade("BNBBTC", d("0.11"), d("5"), false)
+	e.ExecuteTrade("BNBBTC", d("0.1"), d("5"), true)
+
+	if len(depth) != 2 || len(depth[1].Bids) != 1 || !depth[1].Bids[0].Quantity.IsZero() {
+		t.Errorf("unexpected depth events: %+v", depth)
+	}
+	if accounts != 2 {
+		t.Errorf("expected 2 account events, got %d", accounts)
+	}
+	if orders := e.OpenOrders(""); len(orders) != 0 {
+		t.Errorf("unexpected open orders: %+v", orders)
+	}
+
+	klines, err := e.Klines(binance.KlinesRequest{Symbol: "BNBBTC", Interval: binance.Hour})
+	if err != nil || len(klines) != 1 || klines[0].NumberOfTrades != 2 || !klines[0].High.Equal(d("0.11")) {
+		t.Errorf("unexpected klines: %+v %v", klines, err)
+	}
+	ticker, _ := e.Ticker24("BNBBTC")
+	if !ticker.PriceChangePercent.Equal(d("-9.091")) || !ticker.Volume.Equal(d("10")) {
+		t.Errorf("unexpected ticker: %+v", ticker)
+	}
+}
+
+func TestExecuteTradeFillsAtMostQty(t *testing.T) {
+	e := newTestExchange()
+	for _, price := range []string{"0.1", "0.09"} {
+		e.PlaceOrder(binance.NewOrderRequest{
+			Symbol: "BNBBTC", Side: binance.SideBuy, Type: binance.TypeLimit, Quantity: d("1"), Price: d(price),
+		})
+	}
+	e.ExecuteTrade("BNBBTC", d("0.09"), d("1.5"), true)
+
+	orders := e.AllOrders("BNBBTC", 0, 0)
+	if len(orders) != 2 || !orders[0].ExecutedQty.Equal(d("1")) || !orders[1].ExecutedQty.Equal(d("0.5")) {
+		t.Errorf("unexpected orders: %+v", orders)
+	}
+	if b := balances(e)["BNB"]; b[0] != "11.5" {
+		t.Errorf("unexpected BNB balance: %v", b)
+	}
+}
+
+func TestKlinesAlignedToUnixEpoch(t *testing.T) {
+	e := newTestExchange()
+	e.ExecuteTrade("BNBBTC", d("0.1"), d("1"), true)
+
+	klines, err := e.Klines(binance.KlinesRequest{Symbol: "BNBBTC", Interval: binance.Week})
+	if err != nil || len(klines) != 1 {
+		t.Fatalf("unexpected klines: %+v %v", klines, err)
+	}
+	// weeks of the epoch start on Thursday
+	if open := time.Date(2017, 12, 28, 0, 0, 0, 0, time.UTC); !klines[0].OpenTime.Equal(open) {
+		t.Errorf("expected open time %s, got %s", open, klines[0].OpenTime)
+	}
+}
+
+func TestSetLiquidity(t *testing.T) {
+	e := newTestExchange()
+	e.SetCommissions(d("0.001"), d("0.002"))
+	e.AddLiquidity("BNBBTC", binance.SideSell, d("0.1"), d("5"))
+	e.AddLiquidity("BNBBTC", binance.SideSell, d("0.1"), d("3"))
+
+	// level is replaced, not added to
+	e.SetLiquidity("BNBBTC", binance.SideSell, d("0.1"), d("2"))
+	book, _ := e.OrderBook("BNBBTC", 0)
+	if len(book.Asks) != 1 || !book.Asks[0].Quantity.Equal(d("2")) {
+		t.Errorf("unexpected book: %+v", book)
+	}
+
+	e.PlaceOrder(binance.NewOrderRequest{Symbol: "BNBBTC", Side: binance.SideBuy, Type: binance.TypeMarket, Quantity: d("1")})
+	o, _ := e.PlaceOrder(binance.NewOrderRequest{
+		Symbol: "BNBBTC", Side: binance.SideBuy, Type: binance.TypeLimit, Quantity: d("1"), Price: d("0.09"),
+	})
+	e.SetLiquidity("BNBBTC", binance.SideSell, d("0.1"), d("0"))
+	e.SetLiquidity("BNBBTC", binance.SideSell, d("0.09"), d("3"))
+	if o, _ = e.QueryOrder("BNBBTC", int64(o.OrderID), ""); o.Status != binance.StatusFilled {
+		t.Errorf("unexpected order: %+v", o)
+	}
+	book, _ = e.OrderBook("BNBBTC", 0)
+	if len(book.Asks) != 1 || !book.Asks[0].Price.Equal(d("0.09")) || !book.Asks[0].Quantity.Equal(d("2")) {
+		t.Errorf("unexpected book: %+v", book)
+	}
+
+	trades := e.MyTrades("BNBBTC", 0, 0)
+	if len(trades) != 2 || trades[0].IsMaker || !trades[0].Commission.Equal(d("0.002")) ||
+		!trades[1].IsMaker || !trades[1].Commission.Equal(d("0.001")) {
+		t.Errorf("unexpected trades: %+v %+v", trades[0], trades[1])
+	}
+	if a := e.Account(); a.MakerCommision != 10 || a.TakerCommision != 20 {
+		t.Errorf("unexpected commissions: %+v", a)
+	}
+
+	// liquidity crossing liquidity of other participants isn't matched
+	e.SetLiquidity("BNBBTC", binance.SideBuy, d("0.09"), d("4"))
+	book, _ = e.OrderBook("BNBBTC", 0)
+	if len(book.Bids) != 1 || !book.Bids[0].Quantity.Equal(d("4")) || !book.Asks[0].Quantity.Equal(d("2")) {
+		t.Errorf("unexpected book: %+v", book)
+	}
+	if err := e.SetLiquidity("XRPBTC", binance.SideSell, d("1"), d("1")); err == nil {
+		t.Error("expected invalid symbol")
+	}
+}
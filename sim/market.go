@@ -0,0 +1,490 @@
+package sim
+
+import (
+	"time"
+
+	"github.com/rootpd/binance"
+)
+
+// AddLiquidity adds resting limit order of other participants to the book.
+// The order is matched against orders of the account if it crosses them, the
+// rest stays in the book. Balances of the account aren't affected by the order
+// itself, only by fills of its own orders.
+func (e *Exchange) AddLiquidity(symbol string, side binance.OrderSide, price, qty binance.Decimal) (int64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.symbols[symbol]; !ok {
+		return 0, invalidSymbol()
+	}
+	o := e.liquidity(symbol, side, price, qty)
+	e.match(o)
+	e.flush()
+	return o.id, nil
+}
+
+// SetLiquidity sets quantity of other participants at the price level of the
+// book, e.g. from depth update of live stream. Zero quantity removes the level.
+// Like AddLiquidity, the quantity is matched against crossed orders of the
+// account first.
+func (e *Exchange) SetLiquidity(symbol string, side binance.OrderSide, price, qty binance.Decimal) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	bk, ok := e.books[symbol]
+	if !ok {
+		return invalidSymbol()
+	}
+	orders := bk.side(side)
+	var kept []*order
+	for _, o := range *orders {
+		if !o.own && o.price.Equal(price) {
+			continue
+		}
+		kept = append(kept, o)
+	}
+	*orders = kept
+	bk.touch(side, price)
+	if qty.Sign() > 0 {
+		e.match(e.liquidity(symbol, side, price, qty))
+	}
+	e.flush()
+	return nil
+}
+
+// liquidity creates order of other participants.
+func (e *Exchange) liquidity(symbol string, side binance.OrderSide, price, qty binance.Decimal) *order {
+	o := &order{
+		id:          e.nextOrderID,
+		symbol:      symbol,
+		side:        side,
+		typ:         binance.TypeLimit,
+		timeInForce: binance.GTC,
+		price:       price,
+		quantity:    qty,
+		status:      binance.StatusNew,
+		time:        e.now(),
+	}
+	e.nextOrderID++
+	return o
+}
+
+// ClearLiquidity removes all orders added by AddLiquidity from the book of the symbol.
+func (e *Exchange) ClearLiquidity(symbol string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	bk, ok := e.books[symbol]
+	if !ok {
+		return
+	}
+	for _, side := range []binance.OrderSide{binance.SideBuy, binance.SideSell} {
+		orders := bk.side(side)
+		var kept []*order
+		for _, o := range *orders {
+			if o.own {
+				kept = append(kept, o)
+				continue
+			}
+			bk.touch(side, o.price)
+		}
+		*orders = kept
+	}
+	e.flush()
+}
+
+// ExecuteTrade records trade of other participants at price, e.g. replayed from
+// history. Resting orders of the account crossed by the price are filled at
+// their own price, together by at most qty.
+func (e *Exchange) ExecuteTrade(symbol string, price, qty binance.Decimal, buyerMaker bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	bk, ok := e.books[symbol]
+	if !ok {
+		return invalidSymbol()
+	}
+	now := e.now()
+	e.recordPublicTrade(symbol, price, qty, buyerMaker, now)
+
+	s := e.symbols[symbol]
+	for _, side := range []binance.OrderSide{binance.SideBuy, binance.SideSell} {
+		for _, o := range append([]*order(nil), *bk.side(side)...) {
+			if qty.IsZero() || !crosses(o, price) {
+				break
+			}
+			if !o.own {
+				continue
+			}
+			fill := minDecimal(qty, o.remaining())
+			qty = qty.Sub(fill)
+			o.executed = o.executed.Add(fill)
+			o.status = binance.StatusPartiallyFilled
+			if o.remaining().IsZero() {
+				o.status = binance.StatusFilled
+				bk.remove(o)
+			}
+			e.settle(s, o, o.price, fill, true, now)
+			bk.touch(side, o.price)
+		}
+	}
+	e.flush()
+	return nil
+}
+
+func (e *Exchange) recordPublicTrade(symbol string, price, qty binance.Decimal, buyerMaker bool, now time.Time) {
+	bk := e.books[symbol]
+	id := len(bk.trades) + 1
+	t := &binance.AggTrade{
+		ID:             id,
+		Price:          price,
+		Quantity:       qty,
+		FirstTradeID:   id,
+		LastTradeID:    id,
+		Timestamp:      now,
+		BuyerMaker:     buyerMaker,
+		BestPriceMatch: true,
+	}
+	bk.trades = append(bk.trades, t)
+	e.emit(func(l Listener) {
+		if l.Trade != nil {
+			c := *t
+			l.Trade(&binance.AggTradeEvent{
+				WSEvent:  binance.WSEvent{Type: "aggTrade", Time: now, Symbol: symbol},
+				AggTrade: c,
+			})
+		}
+	})
+}
+
+// OrderBook returns aggregated book of the symbol, at most limit levels per
+// side if limit is positive.
+func (e *Exchange) OrderBook(symbol string, limit int) (*binance.OrderBook, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	bk, ok := e.books[symbol]
+	if !ok {
+		return nil, invalidSymbol()
+	}
+	return &binance.OrderBook{
+		LastUpdateID: bk.updateID,
+		Bids:         bk.levels(binance.SideBuy, limit),
+		Asks:         bk.levels(binance.SideSell, limit),
+	}, nil
+}
+
+// AggTrades returns trades of the symbol matching the request. Every trade of
+// the exchange is reported as separate aggregated trade.
+func (e *Exchange) AggTrades(atr binance.AggTradesRequest) ([]*binance.AggTrade, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	bk, ok := e.books[atr.Symbol]
+	if !ok {
+		return nil, invalidSymbol()
+	}
+	limit := atr.Limit
+	if limit <= 0 {
+		limit = 500
+	}
+	var trades []*binance.AggTrade
+	for _, t := range bk.trades {
+		if !inRange(t.Timestamp, atr.StartTime, atr.EndTime) || int64(t.ID) < atr.FromID {
+			continue
+		}
+		c := *t
+		trades = append(trades, &c)
+		if len(trades) == limit {
+			break
+		}
+	}
+	return trades, nil
+}
+
+// Trades returns trades of the symbol with ID from fromID, most recent trades
+// if fromID is zero. At most limit trades are returned, 500 if limit is zero.
+func (e *Exchange) Trades(symbol string, fromID int64, limit int) ([]*binance.MarketTrade, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	bk, ok := e.books[symbol]
+	if !ok {
+		return nil, invalidSymbol()
+	}
+	if limit <= 0 {
+		limit = 500
+	}
+	from := 0
+	if fromID > 0 {
+		from = int(fromID) - 1
+	} else if len(bk.trades) > limit {
+		from = len(bk.trades) - limit
+	}
+	var trades []*binance.MarketTrade
+	for i := from; i < len(bk.trades) && len(trades) < limit; i++ {
+		t := bk.trades[i]
+		trades = append(trades, &binance.MarketTrade{
+			ID:           int64(t.ID),
+			Price:        t.Price,
+			Qty:          t.Quantity,
+			QuoteQty:     t.Price.Mul(t.Quantity),
+			Time:         t.Timestamp,
+			IsBuyerMaker: t.BuyerMaker,
+			IsBestMatch:  t.BestPriceMatch,
+		})
+	}
+	return trades, nil
+}
+
+// Klines returns candles of the symbol built from its trades. Intervals
+// without trades are skipped and month is approximated by 30 days.
+func (e *Exchange) Klines(kr binance.KlinesRequest) ([]*binance.Kline, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	bk, ok := e.books[kr.Symbol]
+	if !ok {
+		return nil, invalidSymbol()
+	}
+	d, ok := IntervalDuration(kr.Interval)
+	if !ok {
+		return nil, apiError(-1120, "Invalid interval.")
+	}
+	limit := kr.Limit
+	if limit <= 0 {
+		limit = 500
+	}
+	var klines []*binance.Kline
+	for _, t := range bk.trades {
+		if !inRange(t.Timestamp, kr.StartTime, kr.EndTime) {
+			continue
+		}
+		open := openTime(t.Timestamp, d)
+		var k *binance.Kline
+		if n := len(klines); n > 0 && klines[n-1].OpenTime.Equal(open) {
+			k = klines[n-1]
+		} else {
+			if len(klines) == limit {
+				break
+			}
+			k = &binance.Kline{
+				OpenTime:  open,
+				CloseTime: open.Add(d - time.Millisecond),
+				Open:      t.Price,
+				High:      t.Price,
+				Low:       t.Price,
+			}
+			klines = append(klines, k)
+		}
+		if t.Price.Cmp(k.High) > 0 {
+			k.High = t.Price
+		}
+		if t.Price.Cmp(k.Low) < 0 {
+			k.Low = t.Price
+		}
+		k.Close = t.Price
+		quote := t.Price.Mul(t.Quantity)
+		k.Volume = k.Volume.Add(t.Quantity)
+		k.QuoteAssetVolume = k.QuoteAssetVolume.Add(quote)
+		k.NumberOfTrades++
+		if !t.BuyerMaker {
+			k.TakerBuyBaseAssetVolume = k.TakerBuyBaseAssetVolume.Add(t.Quantity)
+			k.TakerBuyQuoteAssetVolume = k.TakerBuyQuoteAssetVolume.Add(quote)
+		}
+	}
+	return klines, nil
+}
+
+// Ticker24 returns statistics of the symbol over last 24 hours.
+func (e *Exchange) Ticker24(symbol string) (*binance.Ticker24, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.books[symbol]; !ok {
+		return nil, invalidSymbol()
+	}
+	return e.ticker24(symbol), nil
+}
+
+// Tickers24 returns statistics of every symbol over last 24 hours.
+func (e *Exchange) Tickers24() []*binance.Ticker24 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var tickers []*binance.Ticker24
+	for _, s := range e.sortedSymbols() {
+		tickers = append(tickers, e.ticker24(s))
+	}
+	return tickers
+}
+
+func (e *Exchange) ticker24(symbol string) *binance.Ticker24 {
+	bk := e.books[symbol]
+	now := e.now()
+	t := &binance.Ticker24{
+		Symbol:    symbol,
+		OpenTime:  now.Add(-24 * time.Hour),
+		CloseTime: now,
+	}
+	if bid := bk.best(binance.SideBuy); bid != nil {
+		t.BidPrice = bid.price
+	}
+	if ask := bk.best(binance.SideSell); ask != nil {
+		t.AskPrice = ask.price
+	}
+	var quote binance.Decimal
+	for _, trade := range bk.trades {
+		if !trade.Timestamp.After(t.OpenTime) {
+			if t.Count == 0 {
+				t.PrevClosePrice = trade.Price
+			}
+			continue
+		}
+		if t.Count == 0 {
+			t.OpenPrice, t.HighPrice, t.LowPrice = trade.Price, trade.Price, trade.Price
+			t.FirstID = trade.ID
+		}
+		if trade.Price.Cmp(t.HighPrice) > 0 {
+			t.HighPrice = trade.Price
+		}
+		if trade.Price.Cmp(t.LowPrice) < 0 {
+			t.LowPrice = trade.Price
+		}
+		t.LastPrice = trade.Price
+		t.LastID = trade.ID
+		t.Volume = t.Volume.Add(trade.Quantity)
+		quote = quote.Add(trade.Price.Mul(trade.Quantity))
+		t.Count++
+	}
+	if t.Count > 0 {
+		t.PriceChange = t.LastPrice.Sub(t.OpenPrice)
+		t.PriceChangePercent = t.PriceChange.Mul(binance.NewDecimalFromInt(100)).Div(t.OpenPrice, 3)
+		t.WeightedAvgPrice = quote.Div(t.Volume, 8)
+	}
+	return t
+}
+
+// Prices returns last trade price of every symbol which was traded.
+func (e *Exchange) Prices() []*binance.PriceTicker {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var prices []*binance.PriceTicker
+	for _, s := range e.sortedSymbols() {
+		if p, ok := e.price(s); ok {
+			prices = append(prices, p)
+		}
+	}
+	return prices
+}
+
+// Price returns last trade price of the symbol, zero if it wasn't traded.
+func (e *Exchange) Price(symbol string) (*binance.PriceTicker, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.books[symbol]; !ok {
+		return nil, invalidSymbol()
+	}
+	if p, ok := e.price(symbol); ok {
+		return p, nil
+	}
+	return &binance.PriceTicker{Symbol: symbol}, nil
+}
+
+func (e *Exchange) price(symbol string) (*binance.PriceTicker, bool) {
+	trades := e.books[symbol].trades
+	if len(trades) == 0 {
+		return nil, false
+	}
+	return &binance.PriceTicker{
+		Symbol: symbol,
+		Price:  trades[len(trades)-1].Price,
+	}, true
+}
+
+// Books returns best bid and ask of every symbol.
+func (e *Exchange) Books() []*binance.BookTicker {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var books []*binance.BookTicker
+	for _, s := range e.sortedSymbols() {
+		books = append(books, e.bookTicker(s))
+	}
+	return books
+}
+
+// Book returns best bid and ask of the symbol.
+func (e *Exchange) Book(symbol string) (*binance.BookTicker, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.books[symbol]; !ok {
+		return nil, invalidSymbol()
+	}
+	return e.bookTicker(symbol), nil
+}
+
+func (e *Exchange) bookTicker(symbol string) *binance.BookTicker {
+	bk := e.books[symbol]
+	t := &binance.BookTicker{Symbol: symbol}
+	if bid := bk.best(binance.SideBuy); bid != nil {
+		t.BidPrice, t.BidQty = bid.price, bk.quantity(binance.SideBuy, bid.price)
+	}
+	if ask := bk.best(binance.SideSell); ask != nil {
+		t.AskPrice, t.AskQty = ask.price, bk.quantity(binance.SideSell, ask.price)
+	}
+	return t
+}
+
+// AvgPrice returns volume weighted price of the symbol over last 5 minutes.
+// Last trade price is returned if the symbol wasn't traded within the period.
+func (e *Exchange) AvgPrice(symbol string) (*binance.AvgPrice, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	bk, ok := e.books[symbol]
+	if !ok {
+		return nil, invalidSymbol()
+	}
+	ap := &binance.AvgPrice{Mins: 5}
+	since := e.now().Add(-5 * time.Minute)
+	var volume, quote binance.Decimal
+	for _, t := range bk.trades {
+		ap.Price = t.Price
+		if t.Timestamp.After(since) {
+			volume = volume.Add(t.Quantity)
+			quote = quote.Add(t.Price.Mul(t.Quantity))
+		}
+	}
+	if volume.Sign() > 0 {
+		ap.Price = quote.Div(volume, 8)
+	}
+	return ap, nil
+}
+
+// IntervalDuration returns duration of the kline interval. Month is
+// approximated by 30 days.
+func IntervalDuration(i binance.Interval) (time.Duration, bool) {
+	d, ok := intervals[i]
+	return d, ok
+}
+
+// openTime returns open time of the kline of duration d containing t. Klines
+// are aligned to the Unix epoch as by the exchange.
+func openTime(t time.Time, d time.Duration) time.Time {
+	ns := t.UnixNano()
+	return time.Unix(0, ns-ns%int64(d)).In(t.Location())
+}
+
+var intervals = map[binance.Interval]time.Duration{
+	binance.Minute:         time.Minute,
+	binance.ThreeMinutes:   3 * time.Minute,
+	binance.FiveMinutes:    5 * time.Minute,
+	binance.FifteenMinutes: 15 * time.Minute,
+	binance.ThirtyMinutes:  30 * time.Minute,
+	binance.Hour:           time.Hour,
+	binance.TwoHours:       2 * time.Hour,
+	binance.FourHours:      4 * time.Hour,
+	binance.SixHours:       6 * time.Hour,
+	binance.EightHours:     8 * time.Hour,
+	binance.TwelveHours:    12 * time.Hour,
+	binance.Day:            24 * time.Hour,
+	binance.ThreeDays:      3 * 24 * time.Hour,
+	binance.Week:           7 * 24 * time.Hour,
+	binance.Month:          30 * 24 * time.Hour,
+}
+
+// inRange reports whether t is within start and end given in milliseconds,
+// zero bounds are open.
+func inRange(t time.Time, start, end int64) bool {
+	ms := t.UnixNano() / int64(time.Millisecond)
+	return (start == 0 || ms >= start) && (end == 0 || ms <= end)
+}
@@ -0,0 +1,353 @@
+package sim
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rootpd/binance"
+)
+
+// Service implements binance.Service on top of the exchange. Market data,
+// orders, account and user data stream are served by the exchange, websockets
+// stream its events.
+//
+// Service is shared by binancetest.FakeService and paper trading, which
+// forwards only account calls to it.
+type Service struct {
+	Exchange *Exchange
+
+	mu         sync.Mutex
+	listenKeys map[string]bool
+	nextKey    int
+}
+
+var _ binance.Service = (*Service)(nil)
+
+// NewService creates service backed by the exchange, new exchange with real
+// clock is created if nil.
+func NewService(exchange *Exchange) *Service {
+	if exchange == nil {
+		exchange = NewExchange(nil)
+	}
+	return &Service{
+		Exchange:   exchange,
+		listenKeys: make(map[string]bool),
+	}
+}
+
+func (s *Service) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (s *Service) Time(ctx context.Context) (time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, err
+	}
+	return s.Exchange.Now(), nil
+}
+
+func (s *Service) OrderBook(ctx context.Context, obr binance.OrderBookRequest) (*binance.OrderBook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	limit := obr.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	return s.Exchange.OrderBook(obr.Symbol, limit)
+}
+
+func (s *Service) AggTrades(ctx context.Context, atr binance.AggTradesRequest) ([]*binance.AggTrade, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Exchange.AggTrades(atr)
+}
+
+func (s *Service) Trades(ctx context.Context, tr binance.TradesRequest) ([]*binance.MarketTrade, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Exchange.Trades(tr.Symbol, 0, tr.Limit)
+}
+
+func (s *Service) HistoricalTrades(ctx context.Context, htr binance.HistoricalTradesRequest) ([]*binance.MarketTrade, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Exchange.Trades(htr.Symbol, htr.FromID, htr.Limit)
+}
+
+func (s *Service) Klines(ctx context.Context, kr binance.KlinesRequest) ([]*binance.Kline, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Exchange.Klines(kr)
+}
+
+func (s *Service) Ticker24(ctx context.Context, tr binance.TickerRequest) (*binance.Ticker24, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Exchange.Ticker24(tr.Symbol)
+}
+
+func (s *Service) TickerAll24(ctx context.Context) ([]*binance.Ticker24, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Exchange.Tickers24(), nil
+}
+
+func (s *Service) TickerPrice(ctx context.Context, tr binance.TickerRequest) (*binance.PriceTicker, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Exchange.Price(tr.Symbol)
+}
+
+func (s *Service) TickerBook(ctx context.Context, tr binance.TickerRequest) (*binance.BookTicker, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Exchange.Book(tr.Symbol)
+}
+
+func (s *Service) AvgPrice(ctx context.Context, apr binance.AvgPriceRequest) (*binance.AvgPrice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Exchange.AvgPrice(apr.Symbol)
+}
+
+func (s *Service) TickerAllPrices(ctx context.Context) ([]*binance.PriceTicker, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Exchange.Prices(), nil
+}
+
+func (s *Service) TickerAllBooks(ctx context.Context) ([]*binance.BookTicker, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Exchange.Books(), nil
+}
+
+// ExchangeInfo lists symbols of the exchange as trading with their filters and
+// publishes default rate limits.
+func (s *Service) ExchangeInfo(ctx context.Context) (*binance.ExchangeInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ei := &binance.ExchangeInfo{
+		Timezone:   "UTC",
+		ServerTime: s.Exchange.Now(),
+		RateLimits: RateLimits(binance.DefaultRateLimits()),
+	}
+	for _, sym := range s.Exchange.Symbols() {
+		ei.Symbols = append(ei.Symbols, &binance.SymbolInfo{
+			Symbol:             sym.Name,
+			Status:             binance.SymbolTrading,
+			BaseAsset:          sym.BaseAsset,
+			BaseAssetPrecision: 8,
+			QuoteAsset:         sym.QuoteAsset,
+			QuotePrecision:     8,
+			OrderTypes:         []binance.OrderType{binance.TypeLimit, binance.TypeMarket},
+			Filters:            sym.Filters,
+		})
+	}
+	return ei, nil
+}
+
+// RateLimits returns limits in form published by exchange info.
+func RateLimits(limits binance.RateLimits) []*binance.RateLimit {
+	var rls []*binance.RateLimit
+	for _, rl := range []*binance.RateLimit{
+		{Type: binance.LimitRequestWeight, Interval: binance.IntervalMinute, IntervalNum: 1, Limit: limits.RequestWeight},
+		{Type: binance.LimitOrders, Interval: binance.IntervalSecond, IntervalNum: 10, Limit: limits.Orders},
+		{Type: binance.LimitOrders, Interval: binance.IntervalDay, IntervalNum: 1, Limit: limits.DailyOrders},
+		{Type: binance.LimitRawRequests, Interval: binance.IntervalMinute, IntervalNum: 5, Limit: limits.RawRequests},
+	} {
+		if rl.Limit > 0 {
+			rls = append(rls, rl)
+		}
+	}
+	return rls
+}
+
+func (s *Service) NewOrder(ctx context.Context, or binance.NewOrderRequest) (*binance.ProcessedOrder, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	o, err := s.Exchange.PlaceOrder(or)
+	if err != nil {
+		return nil, err
+	}
+	return &binance.ProcessedOrder{
+		Symbol:        o.Symbol,
+		OrderID:       int64(o.OrderID),
+		ClientOrderID: o.ClientOrderID,
+		TransactTime:  o.Time,
+	}, nil
+}
+
+func (s *Service) NewOrderTest(ctx context.Context, or binance.NewOrderRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Exchange.CheckOrder(or)
+}
+
+func (s *Service) QueryOrder(ctx context.Context, qor binance.QueryOrderRequest) (*binance.ExecutedOrder, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Exchange.QueryOrder(qor.Symbol, qor.OrderID, qor.OrigClientOrderID)
+}
+
+func (s *Service) CancelOrder(ctx context.Context, cor binance.CancelOrderRequest) (*binance.CanceledOrder, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	o, err := s.Exchange.CancelOrder(cor.Symbol, cor.OrderID, cor.OrigClientOrderID)
+	if err != nil {
+		return nil, err
+	}
+	clientOrderID := cor.NewClientOrderID
+	if clientOrderID == "" {
+		clientOrderID = fmt.Sprintf("cancel-%d", o.OrderID)
+	}
+	return &binance.CanceledOrder{
+		Symbol:            o.Symbol,
+		OrigClientOrderID: o.ClientOrderID,
+		OrderID:           int64(o.OrderID),
+		ClientOrderID:     clientOrderID,
+	}, nil
+}
+
+func (s *Service) OpenOrders(ctx context.Context, oor binance.OpenOrdersRequest) ([]*binance.ExecutedOrder, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Exchange.OpenOrders(oor.Symbol), nil
+}
+
+func (s *Service) AllOrders(ctx context.Context, aor binance.AllOrdersRequest) ([]*binance.ExecutedOrder, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	limit := aor.Limit
+	if limit <= 0 {
+		limit = 500
+	}
+	return s.Exchange.AllOrders(aor.Symbol, aor.OrderID, limit), nil
+}
+
+func (s *Service) Account(ctx context.Context, ar binance.AccountRequest) (*binance.Account, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Exchange.Account(), nil
+}
+
+func (s *Service) MyTrades(ctx context.Context, mtr binance.MyTradesRequest) ([]*binance.Trade, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	limit := mtr.Limit
+	if limit <= 0 {
+		limit = 500
+	}
+	return s.Exchange.MyTrades(mtr.Symbol, mtr.FromID, limit), nil
+}
+
+// Withdraw withdraws from balance of the exchange, nothing is sent anywhere.
+func (s *Service) Withdraw(ctx context.Context, wr binance.WithdrawRequest) (*binance.WithdrawResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.Exchange.Withdraw(wr.Asset, wr.Address, wr.Amount); err != nil {
+		return nil, err
+	}
+	return &binance.WithdrawResult{Success: true, Msg: "success"}, nil
+}
+
+func (s *Service) DepositHistory(ctx context.Context, hr binance.HistoryRequest) ([]*binance.Deposit, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var deposits []*binance.Deposit
+	for _, d := range s.Exchange.Deposits(hr.Asset) {
+		if matchHistory(hr, d.Status, d.InsertTime) {
+			deposits = append(deposits, d)
+		}
+	}
+	return deposits, nil
+}
+
+func (s *Service) WithdrawHistory(ctx context.Context, hr binance.HistoryRequest) ([]*binance.Withdrawal, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var withdrawals []*binance.Withdrawal
+	for _, w := range s.Exchange.Withdrawals(hr.Asset) {
+		if matchHistory(hr, w.Status, w.ApplyTime) {
+			withdrawals = append(withdrawals, w)
+		}
+	}
+	return withdrawals, nil
+}
+
+func matchHistory(hr binance.HistoryRequest, status int, t time.Time) bool {
+	if hr.Status != nil && *hr.Status != status {
+		return false
+	}
+	if !hr.StartTime.IsZero() && t.Before(hr.StartTime) {
+		return false
+	}
+	return hr.EndTime.IsZero() || !t.After(hr.EndTime)
+}
+
+func (s *Service) StartUserDataStream(ctx context.Context) (*binance.Stream, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextKey++
+	key := fmt.Sprintf("sim-listen-key-%d", s.nextKey)
+	s.listenKeys[key] = true
+	return &binance.Stream{ListenKey: key}, nil
+}
+
+func (s *Service) KeepAliveUserDataStream(ctx context.Context, stream *binance.Stream) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.checkListenKey(stream.ListenKey)
+}
+
+func (s *Service) CloseUserDataStream(ctx context.Context, stream *binance.Stream) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.checkListenKey(stream.ListenKey); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.listenKeys, stream.ListenKey)
+	return nil
+}
+
+func (s *Service) checkListenKey(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.listenKeys[key] {
+		return apiError(-1125, "This listenKey does not exist.")
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package sim
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rootpd/binance"
+)
+
+// queue buffers events emitted by the exchange so that its listeners never
+// block on slow readers.
+type queue struct {
+	mu     sync.Mutex
+	events []interface{}
+	ready  chan struct{}
+}
+
+func newQueue() *queue {
+	return &queue{ready: make(chan struct{}, 1)}
+}
+
+func (q *queue) push(event interface{}) {
+	q.mu.Lock()
+	q.events = append(q.events, event)
+	q.mu.Unlock()
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (q *queue) pop() []interface{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	events := q.events
+	q.events = nil
+	return events
+}
+
+// stream subscribes the listener and delivers queued events by send until ctx
+// is done. Returned channel is closed when the stream ends.
+func (s *Service) stream(ctx context.Context, listener func(q *queue) Listener,
+	send func(event interface{}) bool) chan struct{} {
+	q := newQueue()
+	unsubscribe := s.Exchange.Subscribe(listener(q))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.ready:
+			}
+			for _, event := range q.pop() {
+				if !send(event) {
+					return
+				}
+			}
+		}
+	}()
+	return done
+}
+
+func (s *Service) DepthWebsocket(ctx context.Context, dwr binance.DepthWebsocketRequest) (chan *binance.DepthEvent, chan struct{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	dech := make(chan *binance.DepthEvent)
+	done := s.stream(ctx, func(q *queue) Listener {
+		return Listener{Depth: func(de *binance.DepthEvent) {
+			if de.Symbol == dwr.Symbol {
+				q.push(de)
+			}
+		}}
+	}, func(event interface{}) bool {
+		select {
+		case dech <- event.(*binance.DepthEvent):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+	return dech, done, nil
+}
+
+func (s *Service) CombinedDepthWebsocket(ctx context.Context, cdwr binance.CombinedDepthWebsocketRequest) (chan *binance.DepthEvent, chan struct{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	symbols := make(map[string]bool, len(cdwr.Symbols))
+	for _, symbol := range cdwr.Symbols {
+		symbols[symbol] = true
+	}
+	dech := make(chan *binance.DepthEvent, len(cdwr.Symbols))
+	done := s.stream(ctx, func(q *queue) Listener {
+		return Listener{Depth: func(de *binance.DepthEvent) {
+			if symbols[de.Symbol] {
+				q.push(de)
+			}
+		}}
+	}, func(event interface{}) bool {
+		select {
+		case dech <- event.(*binance.DepthEvent):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+	return dech, done, nil
+}
+
+func (s *Service) KlineWebsocket(ctx context.Context, kwr binance.KlineWebsocketRequest) (chan *binance.KlineEvent, chan struct{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	interval, ok := IntervalDuration(kwr.Interval)
+	if !ok {
+		return nil, nil, apiError(-1120, "Invalid interval.")
+	}
+	kech := make(chan *binance.KlineEvent)
+	var current *binance.KlineEvent
+	deliver := func(ke *binance.KlineEvent) bool {
+		c := *ke
+		select {
+		case kech <- &c:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	done := s.stream(ctx, func(q *queue) Listener {
+		return Listener{Trade: func(te *binance.AggTradeEvent) {
+			if te.Symbol == kwr.Symbol {
+				q.push(te)
+			}
+		}}
+	}, func(event interface{}) bool {
+		te := event.(*binance.AggTradeEvent)
+		open := openTime(te.Timestamp, interval)
+		if current != nil && !current.OpenTime.Equal(open) {
+			current.Final = true
+			if !deliver(current) {
+				return false
+			}
+			current = nil
+		}
+		if current == nil {
+			current = &binance.KlineEvent{
+				WSEvent:      binance.WSEvent{Type: "kline", Symbol: te.Symbol},
+				Interval:     kwr.Interval,
+				FirstTradeID: int64(te.FirstTradeID),
+				Kline: binance.Kline{
+					OpenTime:  open,
+					CloseTime: open.Add(interval - time.Millisecond),
+					Open:      te.Price,
+					High:      te.Price,
+					Low:       te.Price,
+				},
+			}
+		}
+		updateKline(current, te)
+		return deliver(current)
+	})
+	return kech, done, nil
+}
+
+func updateKline(ke *binance.KlineEvent, te *binance.AggTradeEvent) {
+	ke.Time = te.Time
+	ke.LastTradeID = int64(te.LastTradeID)
+	if te.Price.Cmp(ke.High) > 0 {
+		ke.High = te.Price
+	}
+	if te.Price.Cmp(ke.Low) < 0 {
+		ke.Low = te.Price
+	}
+	ke.Close = te.Price
+	quote := te.Price.Mul(te.Quantity)
+	ke.Volume = ke.Volume.Add(te.Quantity)
+	ke.QuoteAssetVolume = ke.QuoteAssetVolume.Add(quote)
+	ke.NumberOfTrades++
+	if !te.BuyerMaker {
+		ke.TakerBuyBaseAssetVolume = ke.TakerBuyBaseAssetVolume.Add(te.Quantity)
+		ke.TakerBuyQuoteAssetVolume = ke.TakerBuyQuoteAssetVolume.Add(quote)
+	}
+}
+
+func (s *Service) TradeWebsocket(ctx context.Context, twr binance.TradeWebsocketRequest) (chan *binance.AggTradeEvent, chan struct{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	aech := make(chan *binance.AggTradeEvent)
+	done := s.stream(ctx, func(q *queue) Listener {
+		return Listener{Trade: func(te *binance.AggTradeEvent) {
+			if te.Symbol == twr.Symbol {
+				q.push(te)
+			}
+		}}
+	}, func(event interface{}) bool {
+		select {
+		case aech <- event.(*binance.AggTradeEvent):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+	return aech, done, nil
+}
+
+func (s *Service) UserDataWebsocket(ctx context.Context, udwr binance.UserDataWebsocketRequest) (chan *binance.AccountEvent, chan struct{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkListenKey(udwr.ListenKey); err != nil {
+		return nil, nil, err
+	}
+	aech := make(chan *binance.AccountEvent)
+	done := s.stream(ctx, func(q *queue) Listener {
+		return Listener{Account: func(ae *binance.AccountEvent) {
+			q.push(ae)
+		}}
+	}, func(event interface{}) bool {
+		select {
+		case aech <- event.(*binance.AccountEvent):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+	return aech, done, nil
+}
@@ -0,0 +1,121 @@
+package binance
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// SymbolRegistry caches trading rules of symbols loaded by ExchangeInfo.
+//
+// Registry is empty until the first successful Refresh. Run refreshes it
+// periodically, so newly listed symbols, halts and changed filters are picked
+// up without restart.
+type SymbolRegistry struct {
+	service Service
+	logger  log.Logger
+
+	mu          sync.RWMutex
+	symbols     map[string]*SymbolInfo
+	info        *ExchangeInfo
+	refreshedAt time.Time
+}
+
+// NewSymbolRegistry creates registry loading symbols using provided service.
+func NewSymbolRegistry(service Service, logger log.Logger) *SymbolRegistry {
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+	return &SymbolRegistry{
+		service: service,
+		logger:  logger,
+		symbols: make(map[string]*SymbolInfo),
+	}
+}
+
+// Refresh loads current exchange info. Registry keeps previous state if the
+// call fails.
+func (r *SymbolRegistry) Refresh(ctx context.Context) error {
+	ei, err := r.service.ExchangeInfo(ctx)
+	if err != nil {
+		return err
+	}
+	symbols := make(map[string]*SymbolInfo, len(ei.Symbols))
+	for _, si := range ei.Symbols {
+		symbols[si.Symbol] = si
+	}
+
+	r.mu.Lock()
+	r.info = ei
+	r.symbols = symbols
+	r.refreshedAt = time.Now()
+	r.mu.Unlock()
+	level.Debug(r.logger).Log("exchangeInfoSymbols", len(symbols))
+	return nil
+}
+
+// Run refreshes the registry periodically until ctx is done.
+func (r *SymbolRegistry) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := r.Refresh(ctx); err != nil && ctx.Err() == nil {
+			level.Error(r.logger).Log("exchangeInfoRefresh", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Symbol returns trading rules of the symbol, false if it isn't listed.
+func (r *SymbolRegistry) Symbol(symbol string) (*SymbolInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	si, ok := r.symbols[symbol]
+	return si, ok
+}
+
+// Symbols returns all listed symbols in order returned by the server.
+func (r *SymbolRegistry) Symbols() []*SymbolInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.info == nil {
+		return nil
+	}
+	return append([]*SymbolInfo(nil), r.info.Symbols...)
+}
+
+// Trading returns names of symbols with SymbolTrading status.
+func (r *SymbolRegistry) Trading() []string {
+	var names []string
+	for _, si := range r.Symbols() {
+		if si.Status == SymbolTrading {
+			names = append(names, si.Symbol)
+		}
+	}
+	return names
+}
+
+// RateLimits returns rate limits published by the server, zero value before
+// the first refresh.
+func (r *SymbolRegistry) RateLimits() RateLimits {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.info == nil {
+		return RateLimits{}
+	}
+	return r.info.Limits()
+}
+
+// RefreshedAt returns time of the last successful refresh.
+func (r *SymbolRegistry) RefreshedAt() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.refreshedAt
+}
@@ -0,0 +1,72 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSymbolRegistry(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			fmt.Fprint(w, `{"timezone":"UTC","serverTime":1499827319559,`+
+				`"rateLimits":[{"rateLimitType":"REQUEST_WEIGHT","interval":"MINUTE","intervalNum":1,"limit":1200}],`+
+				`"symbols":[{"symbol":"ETHBTC","status":"TRADING","baseAsset":"ETH","quoteAsset":"BTC","orderTypes":["LIMIT"],`+
+				`"filters":[{"filterType":"LOT_SIZE","minQty":"0.001","maxQty":"100000","stepSize":"0.001"},{"filterType":"UNKNOWN"}]},`+
+				`{"symbol":"BNBBTC","status":"HALT","baseAsset":"BNB","quoteAsset":"BTC","orderTypes":[],"filters":[]}]}`)
+		case 2:
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			fmt.Fprint(w, `{"timezone":"UTC","serverTime":1499827319559,"rateLimits":[],"symbols":[]}`)
+		}
+	}))
+	defer ts.Close()
+
+	r := NewSymbolRegistry(NewAPIService(ts.URL, "", nil, nil, nil), nil)
+	if _, ok := r.Symbol("ETHBTC"); ok || r.Symbols() != nil || !r.RefreshedAt().IsZero() {
+		t.Error("expected empty registry before refresh")
+	}
+	if err := r.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	si, ok := r.Symbol("ETHBTC")
+	if !ok || si.QuoteAsset != "BTC" || !si.Filters.LotSize.MinQty.Equal(MustParseDecimal("0.001")) || si.Filters.Price != nil {
+		t.Errorf("unexpected symbol %+v", si)
+	}
+	if trading := r.Trading(); len(trading) != 1 || trading[0] != "ETHBTC" || len(r.Symbols()) != 2 {
+		t.Errorf("unexpected trading symbols %v", trading)
+	}
+	if limits := r.RateLimits(); limits != (RateLimits{RequestWeight: 1200}) {
+		t.Errorf("unexpected limits %+v", limits)
+	}
+
+	// failed refresh keeps previous state
+	if err := r.Refresh(context.Background()); err == nil {
+		t.Error("expected error")
+	}
+	if _, ok := r.Symbol("ETHBTC"); !ok {
+		t.Error("expected symbol to be kept")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx, time.Hour)
+		close(done)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(r.Symbols()) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("registry not refreshed by Run")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+}
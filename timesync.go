@@ -0,0 +1,117 @@
+package binance
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// Clock keeps track of offset between local and server time.
+//
+// Offset is measured by calling Service.Time and compensating for half of the
+// round-trip. Each Sync takes several samples and keeps the one with the
+// shortest round-trip as the most precise.
+type Clock struct {
+	// Samples is number of Time calls made by single Sync.
+	Samples int
+
+	service Service
+	logger  log.Logger
+
+	mu        sync.RWMutex
+	offset    time.Duration
+	roundTrip time.Duration
+	syncedAt  time.Time
+}
+
+// NewClock creates Clock measuring server time using provided service.
+func NewClock(service Service, logger log.Logger) *Clock {
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+	return &Clock{
+		Samples: 3,
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Sync measures current offset between local and server time.
+func (c *Clock) Sync(ctx context.Context) error {
+	var (
+		best    time.Duration
+		bestRTT time.Duration = -1
+		lastErr error
+	)
+	for i := 0; i < c.Samples; i++ {
+		start := time.Now()
+		st, err := c.service.Time(ctx)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		rtt := time.Since(start)
+		if bestRTT < 0 || rtt < bestRTT {
+			bestRTT = rtt
+			best = st.Sub(start.Add(rtt / 2))
+		}
+	}
+	if bestRTT < 0 {
+		return lastErr
+	}
+
+	c.mu.Lock()
+	c.offset = best
+	c.roundTrip = bestRTT
+	c.syncedAt = time.Now()
+	c.mu.Unlock()
+	level.Debug(c.logger).Log("clockOffset", best, "roundTrip", bestRTT)
+	return nil
+}
+
+// Run synchronizes clock periodically until ctx is done.
+func (c *Clock) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Sync(ctx); err != nil && ctx.Err() == nil {
+			level.Error(c.logger).Log("clockSync", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Now returns current server time estimated from local time.
+func (c *Clock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return time.Now().Add(c.offset)
+}
+
+// Offset returns last measured difference between server and local time.
+func (c *Clock) Offset() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.offset
+}
+
+// RoundTrip returns round-trip of the sample used for last offset.
+func (c *Clock) RoundTrip() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.roundTrip
+}
+
+// SyncedAt returns time of the last successful synchronization.
+func (c *Clock) SyncedAt() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.syncedAt
+}
@@ -0,0 +1,83 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newSkewedServer returns server with clock ahead of local time by skew which
+// rejects signed requests with timestamp outside of recvWindow.
+func newSkewedServer(skew time.Duration, accounts *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverTime := unixMillis(time.Now().Add(skew))
+		switch r.URL.Path {
+		case "/api/v1/time":
+			fmt.Fprintf(w, `{"serverTime":%d}`, serverTime)
+		case "/api/v3/account":
+			*accounts++
+			ts, _ := strconv.ParseInt(r.URL.Query().Get("timestamp"), 10, 64)
+			rw, _ := strconv.ParseInt(r.URL.Query().Get("recvWindow"), 10, 64)
+			if ts > serverTime+1000 || serverTime-ts > rw {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`))
+				return
+			}
+			w.Write([]byte(`{"canTrade":true,"balances":[]}`))
+		}
+	}))
+}
+
+func TestClockSync(t *testing.T) {
+	var accounts int
+	ts := newSkewedServer(time.Hour, &accounts)
+	defer ts.Close()
+
+	clock := NewClock(NewAPIService(ts.URL, "", nil, nil, nil), nil)
+	if err := clock.Sync(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if d := clock.Offset() - time.Hour; d < -time.Second || d > time.Second {
+		t.Errorf("invalid offset measured: %s", clock.Offset())
+	}
+}
+
+func TestTimestampFilledByClock(t *testing.T) {
+	var accounts int
+	ts := newSkewedServer(time.Hour, &accounts)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := NewAPIService(ts.URL, "", &HmacSigner{}, nil, ctx, WithTimeSync(time.Minute), WithRecvWindow(5*time.Second))
+	// first request gets rejected if it's sent before the first sync, then it's resent
+	acc, err := s.Account(context.Background(), AccountRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !acc.CanTrade {
+		t.Errorf("invalid account returned")
+	}
+	if accounts > 2 {
+		t.Errorf("request should be resent at most once, sent %d times", accounts)
+	}
+}
+
+func TestTimestampErrorResync(t *testing.T) {
+	var accounts int
+	ts := newSkewedServer(time.Hour, &accounts)
+	defer ts.Close()
+
+	clock := NewClock(NewAPIService(ts.URL, "", nil, nil, nil), nil)
+	s := NewAPIService(ts.URL, "", &HmacSigner{}, nil, nil, WithClock(clock), WithRecvWindow(5*time.Second))
+	if _, err := s.Account(context.Background(), AccountRequest{Timestamp: time.Now()}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if accounts != 2 {
+		t.Errorf("expected request to be resent once after resync, sent %d times", accounts)
+	}
+}
@@ -3,23 +3,24 @@ package binance
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 )
 
-func floatFromString(raw interface{}) (float64, error) {
+func decimalFromString(raw interface{}) (Decimal, error) {
 	str, ok := raw.(string)
 	if !ok {
-		return 0, errors.New(fmt.Sprintf("unable to parse, value not string: %T", raw))
+		return Decimal{}, errors.New(fmt.Sprintf("unable to parse, value not string: %T", raw))
 	}
-	flt, err := strconv.ParseFloat(str, 64)
+	d, err := ParseDecimal(str)
 	if err != nil {
-		return 0, errors.Wrap(err, fmt.Sprintf("unable to parse as float: %s", str))
+		return Decimal{}, errors.Wrap(err, fmt.Sprintf("unable to parse as decimal: %s", str))
 	}
-	return flt, nil
+	return d, nil
 }
 
 func intFromString(raw interface{}) (int, error) {
@@ -62,11 +63,29 @@ func recvWindow(d time.Duration) int64 {
 	return int64(d) / int64(time.Millisecond)
 }
 
-func (as *apiService) handleError(textRes []byte) error {
-	err := &Error{}
-	level.Info(as.Logger).Log("errorResponse", textRes)
-	if err := json.Unmarshal(textRes, err); err != nil {
-		return errors.Wrap(err, "error unmarshal failed")
+// maxErrorBody limits length of non-JSON error body included in the message.
+const maxErrorBody = 256
+
+// handleError builds error of failed call of the endpoint from its response.
+func (as *apiService) handleError(endpoint string, res *http.Response, textRes []byte) error {
+	as.log(LogResponses, LogInfo).Log("errorResponse", textRes)
+	err := &Error{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+	}
+	if jsonErr := json.Unmarshal(textRes, err); jsonErr != nil || err.Code == 0 {
+		// response from gateway or load balancer, e.g. HTML error page
+		err.Code = 0
+		err.Body = textRes
+		msg := strings.Join(strings.Fields(string(textRes)), " ")
+		if len(msg) > maxErrorBody {
+			msg = msg[:maxErrorBody] + "..."
+		}
+		if msg == "" {
+			msg = http.StatusText(res.StatusCode)
+		}
+		err.Message = msg
 	}
+	as.Metrics.observeError(endpoint, err)
 	return err
 }
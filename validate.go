@@ -0,0 +1,240 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// FieldError describes field of NewOrderRequest which failed validation.
+type FieldError struct {
+	// Field is name of the request field, e.g. "Price".
+	Field string
+	// Filter is type of the failed symbol filter, e.g. "PRICE_FILTER", empty
+	// for rules not defined by filters.
+	Filter string
+	Reason string
+}
+
+// Error returns formatted error message.
+func (e FieldError) Error() string {
+	if e.Filter == "" {
+		return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s (%s)", e.Field, e.Reason, e.Filter)
+}
+
+// ValidationError is returned when order fails client-side validation. It
+// matches ErrFilterFailure with errors.Is.
+type ValidationError struct {
+	Symbol string
+	Fields []FieldError
+}
+
+// Error returns formatted error message.
+func (e ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Error())
+	}
+	return fmt.Sprintf("invalid %s order: %s", e.Symbol, strings.Join(msgs, "; "))
+}
+
+// Is reports whether error falls into the target category.
+func (e ValidationError) Is(target error) bool {
+	return target == ErrFilterFailure
+}
+
+// ValidateOrder checks the order against trading rules of the symbol: status,
+// order types, time in force, iceberg and PRICE_FILTER, PERCENT_PRICE,
+// LOT_SIZE, MARKET_LOT_SIZE, MIN_NOTIONAL and ICEBERG_PARTS filters. All
+// failed fields are returned in ValidationError.
+//
+// PERCENT_PRICE and MIN_NOTIONAL of market orders need current average price
+// of the symbol, they aren't checked if avgPrice is zero. MIN_NOTIONAL is
+// checked for market orders only if the filter applies to market.
+// MAX_NUM_ORDERS depends on open orders of the account and isn't checked.
+func ValidateOrder(si *SymbolInfo, or NewOrderRequest, avgPrice Decimal) error {
+	v := &validation{ValidationError: ValidationError{Symbol: or.Symbol}}
+	if si.Status != SymbolTrading {
+		v.fail("Symbol", "", "symbol status is %s", si.Status)
+	}
+	v.orderType(si, or)
+	if or.Side != SideBuy && or.Side != SideSell {
+		v.fail("Side", "", "invalid side %q", or.Side)
+	}
+	if or.Type == TypeLimit {
+		if or.Price.Sign() <= 0 {
+			v.fail("Price", "", "price is required")
+		}
+		v.price("Price", si.Filters, or.Price, avgPrice)
+	}
+	if !or.StopPrice.IsZero() {
+		v.price("StopPrice", si.Filters, or.StopPrice, avgPrice)
+	}
+
+	if or.Quantity.Sign() <= 0 {
+		v.fail("Quantity", "", "quantity is required")
+	}
+	// market orders have to pass both LOT_SIZE and MARKET_LOT_SIZE
+	v.lot("Quantity", "LOT_SIZE", si.Filters.LotSize, or.Quantity)
+	if or.Type == TypeMarket {
+		v.lot("Quantity", "MARKET_LOT_SIZE", si.Filters.MarketLotSize, or.Quantity)
+	}
+
+	if f := si.Filters.MinNotional; f != nil && (or.Type != TypeMarket || f.ApplyToMarket) {
+		price := or.Price
+		if or.Type == TypeMarket {
+			price = avgPrice
+		}
+		if notional := price.Mul(or.Quantity); !price.IsZero() && notional.Cmp(f.MinNotional) < 0 {
+			v.fail("Quantity", "MIN_NOTIONAL", "notional %s below %s", notional, f.MinNotional)
+		}
+	}
+	v.iceberg(si, or)
+
+	if len(v.Fields) > 0 {
+		return v.ValidationError
+	}
+	return nil
+}
+
+// validation collects failed fields.
+type validation struct {
+	ValidationError
+}
+
+func (v *validation) fail(field, filter, format string, args ...interface{}) {
+	v.Fields = append(v.Fields, FieldError{Field: field, Filter: filter, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (v *validation) orderType(si *SymbolInfo, or NewOrderRequest) {
+	allowed := false
+	for _, ot := range si.OrderTypes {
+		allowed = allowed || ot == or.Type
+	}
+	if !allowed {
+		v.fail("Type", "", "order type %q not supported", or.Type)
+	}
+	switch {
+	case or.Type == TypeLimit && or.TimeInForce != GTC && or.TimeInForce != IOC && or.TimeInForce != FOK:
+		v.fail("TimeInForce", "", "invalid time in force %q of limit order", or.TimeInForce)
+	case or.Type == TypeMarket && or.TimeInForce != "":
+		v.fail("TimeInForce", "", "time in force not allowed for market order")
+	}
+}
+
+func (v *validation) price(field string, filters SymbolFilters, price, avgPrice Decimal) {
+	if price.Sign() <= 0 {
+		return
+	}
+	if f := filters.Price; f != nil {
+		switch {
+		case !f.MinPrice.IsZero() && price.Cmp(f.MinPrice) < 0:
+			v.fail(field, "PRICE_FILTER", "%s below min price %s", price, f.MinPrice)
+		case !f.MaxPrice.IsZero() && price.Cmp(f.MaxPrice) > 0:
+			v.fail(field, "PRICE_FILTER", "%s above max price %s", price, f.MaxPrice)
+		case !onGrid(price, f.MinPrice, f.TickSize):
+			v.fail(field, "PRICE_FILTER", "%s not multiple of tick size %s", price, f.TickSize)
+		}
+	}
+	if f := filters.PercentPrice; f != nil && !avgPrice.IsZero() {
+		up, down := avgPrice.Mul(f.MultiplierUp), avgPrice.Mul(f.MultiplierDown)
+		switch {
+		case !f.MultiplierUp.IsZero() && price.Cmp(up) > 0:
+			v.fail(field, "PERCENT_PRICE", "%s above %s", price, up)
+		case price.Cmp(down) < 0:
+			v.fail(field, "PERCENT_PRICE", "%s below %s", price, down)
+		}
+	}
+}
+
+func (v *validation) lot(field, filter string, f *LotSizeFilter, qty Decimal) {
+	if f == nil || qty.Sign() <= 0 {
+		return
+	}
+	switch {
+	case qty.Cmp(f.MinQty) < 0:
+		v.fail(field, filter, "%s below min quantity %s", qty, f.MinQty)
+	case !f.MaxQty.IsZero() && qty.Cmp(f.MaxQty) > 0:
+		v.fail(field, filter, "%s above max quantity %s", qty, f.MaxQty)
+	case !onGrid(qty, f.MinQty, f.StepSize):
+		v.fail(field, filter, "%s not multiple of step size %s", qty, f.StepSize)
+	}
+}
+
+func (v *validation) iceberg(si *SymbolInfo, or NewOrderRequest) {
+	if or.IcebergQty.IsZero() {
+		return
+	}
+	if !si.IcebergAllowed {
+		v.fail("IcebergQty", "", "iceberg orders not allowed")
+		return
+	}
+	if or.TimeInForce != GTC {
+		v.fail("TimeInForce", "", "iceberg order requires GTC")
+	}
+	v.lot("IcebergQty", "LOT_SIZE", si.Filters.LotSize, or.IcebergQty)
+	if f := si.Filters.IcebergParts; f != nil && or.IcebergQty.Sign() > 0 {
+		parts := or.Quantity.RoundStep(or.IcebergQty, RoundUp).Div(or.IcebergQty, 0)
+		if parts.Cmp(NewDecimalFromInt(int64(f.Limit))) > 0 {
+			v.fail("IcebergQty", "ICEBERG_PARTS", "%s parts exceed limit %d", parts, f.Limit)
+		}
+	}
+}
+
+// onGrid reports whether value is min plus multiple of step, zero step
+// disables the check.
+func onGrid(value, min, step Decimal) bool {
+	if step.IsZero() {
+		return true
+	}
+	diff := value.Sub(min)
+	return diff.RoundStep(step, RoundDown).Equal(diff)
+}
+
+// ValidOrder rejects orders failing ValidateOrder with rules of symbols in the
+// registry, to be used with RiskMiddleware. Orders of symbols missing in the
+// registry are rejected. Average price used by PERCENT_PRICE and MIN_NOTIONAL
+// of market orders is provided by avgPrice, those checks are skipped if nil.
+func ValidOrder(symbols *SymbolRegistry, avgPrice func(ctx context.Context, symbol string) (Decimal, error)) OrderCheck {
+	return func(ctx context.Context, or NewOrderRequest) error {
+		si, ok := symbols.Symbol(or.Symbol)
+		if !ok {
+			return ValidationError{Symbol: or.Symbol, Fields: []FieldError{{Field: "Symbol", Reason: "unknown symbol"}}}
+		}
+		var avg Decimal
+		if avgPrice != nil {
+			var err error
+			if avg, err = avgPrice(ctx, or.Symbol); err != nil {
+				return err
+			}
+		}
+		return ValidateOrder(si, or, avg)
+	}
+}
+
+// RoundPrice rounds price to tick size of the symbol in given direction. Price
+// is returned unchanged if the symbol has no tick size.
+func (si *SymbolInfo) RoundPrice(price Decimal, mode RoundingMode) Decimal {
+	if f := si.Filters.Price; f != nil {
+		return roundGrid(price, f.MinPrice, f.TickSize, mode)
+	}
+	return price
+}
+
+// RoundQuantity rounds quantity to step size of the symbol in given direction.
+// Quantity is returned unchanged if the symbol has no step size.
+func (si *SymbolInfo) RoundQuantity(qty Decimal, mode RoundingMode) Decimal {
+	if f := si.Filters.LotSize; f != nil {
+		return roundGrid(qty, f.MinQty, f.StepSize, mode)
+	}
+	return qty
+}
+
+func roundGrid(value, min, step Decimal, mode RoundingMode) Decimal {
+	if step.IsZero() {
+		return value
+	}
+	return value.Sub(min).RoundStep(step, mode).Add(min)
+}
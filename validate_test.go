@@ -0,0 +1,126 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func testSymbol() *SymbolInfo {
+	d := MustParseDecimal
+	return &SymbolInfo{
+		Symbol:         "ETHBTC",
+		Status:         SymbolTrading,
+		OrderTypes:     []OrderType{TypeLimit, TypeMarket},
+		IcebergAllowed: true,
+		Filters: SymbolFilters{
+			Price:         &PriceFilter{MinPrice: d("0.000001"), MaxPrice: d("100000"), TickSize: d("0.000001")},
+			PercentPrice:  &PercentPriceFilter{MultiplierUp: d("5"), MultiplierDown: d("0.2"), AvgPriceMins: 5},
+			LotSize:       &LotSizeFilter{MinQty: d("0.001"), MaxQty: d("100000"), StepSize: d("0.001")},
+			MarketLotSize: &LotSizeFilter{MaxQty: d("1000")},
+			MinNotional:   &MinNotionalFilter{MinNotional: d("0.001"), ApplyToMarket: true, AvgPriceMins: 5},
+			IcebergParts:  &IcebergPartsFilter{Limit: 10},
+		},
+	}
+}
+
+func TestValidateOrder(t *testing.T) {
+	d := MustParseDecimal
+	si := testSymbol()
+	limit := NewOrderRequest{Symbol: "ETHBTC", Side: SideBuy, Type: TypeLimit, TimeInForce: GTC, Quantity: d("1.5"), Price: d("0.071234")}
+
+	cases := []struct {
+		name     string
+		modify   func(or *NewOrderRequest)
+		avgPrice string
+		fields   []string
+	}{
+		{"valid limit", func(or *NewOrderRequest) {}, "0.07", nil},
+		{"valid market", func(or *NewOrderRequest) {
+			or.Type, or.TimeInForce, or.Price, or.Quantity = TypeMarket, "", Decimal{}, d("0.123")
+		}, "0.07", nil},
+		{"market step size", func(or *NewOrderRequest) {
+			or.Type, or.TimeInForce, or.Price, or.Quantity = TypeMarket, "", Decimal{}, d("0.1234567")
+		}, "0.07", []string{"Quantity LOT_SIZE"}},
+		{"tick size", func(or *NewOrderRequest) { or.Price = d("0.0712345") }, "0", []string{"Price PRICE_FILTER"}},
+		{"percent price", func(or *NewOrderRequest) { or.Price = d("0.01") }, "0.07", []string{"Price PERCENT_PRICE"}},
+		{"step size and notional", func(or *NewOrderRequest) { or.Quantity = d("0.0105"); or.Price = d("0.05") }, "0",
+			[]string{"Quantity LOT_SIZE", "Quantity MIN_NOTIONAL"}},
+		{"market notional", func(or *NewOrderRequest) {
+			or.Type, or.TimeInForce, or.Price, or.Quantity = TypeMarket, "", Decimal{}, d("0.01")
+		}, "0.07", []string{"Quantity MIN_NOTIONAL"}},
+		{"market lot size", func(or *NewOrderRequest) {
+			or.Type, or.TimeInForce, or.Price, or.Quantity = TypeMarket, "", Decimal{}, d("1001")
+		}, "0", []string{"Quantity MARKET_LOT_SIZE"}},
+		{"time in force", func(or *NewOrderRequest) { or.TimeInForce = "" }, "0", []string{"TimeInForce "}},
+		{"order type", func(or *NewOrderRequest) { or.Type = OrderType("STOP_LOSS") }, "0", []string{"Type "}},
+		{"iceberg parts", func(or *NewOrderRequest) { or.IcebergQty = d("0.1") }, "0", []string{"IcebergQty ICEBERG_PARTS"}},
+		{"iceberg", func(or *NewOrderRequest) { or.IcebergQty = d("0.5"); or.TimeInForce = IOC }, "0", []string{"TimeInForce "}},
+	}
+	for _, c := range cases {
+		or := limit
+		c.modify(&or)
+		err := ValidateOrder(si, or, d(c.avgPrice))
+		if c.fields == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", c.name, err)
+			}
+			continue
+		}
+		ve, ok := err.(ValidationError)
+		if !ok || !errors.Is(err, ErrFilterFailure) || len(ve.Fields) != len(c.fields) {
+			t.Errorf("%s: expected fields %v, got %v", c.name, c.fields, err)
+			continue
+		}
+		for i, f := range ve.Fields {
+			if f.Field+" "+f.Filter != c.fields[i] {
+				t.Errorf("%s: expected %s, got %+v", c.name, c.fields[i], f)
+			}
+		}
+	}
+
+	si.Filters.MinNotional.ApplyToMarket = false
+	market := NewOrderRequest{Symbol: "ETHBTC", Side: SideBuy, Type: TypeMarket, Quantity: d("0.01")}
+	if err := ValidateOrder(si, market, d("0.07")); err != nil {
+		t.Errorf("market order checked by MIN_NOTIONAL not applied to market: %v", err)
+	}
+
+	si.Status = SymbolHalt
+	if err := ValidateOrder(si, limit, Decimal{}); err == nil {
+		t.Error("expected error for halted symbol")
+	}
+}
+
+func TestValidOrderCheck(t *testing.T) {
+	r := NewSymbolRegistry(nil, nil)
+	r.symbols["ETHBTC"] = testSymbol()
+	check := ValidOrder(r, func(ctx context.Context, symbol string) (Decimal, error) {
+		return MustParseDecimal("1"), nil
+	})
+	or := NewOrderRequest{Symbol: "ETHBTC", Side: SideSell, Type: TypeLimit, TimeInForce: GTC,
+		Quantity: MustParseDecimal("1"), Price: MustParseDecimal("0.07")}
+	if err := check(context.Background(), or); err == nil {
+		t.Error("expected percent price failure")
+	}
+	or.Symbol = "BNBBTC"
+	if err := check(context.Background(), or); !errors.Is(err, ErrFilterFailure) {
+		t.Errorf("expected unknown symbol failure, got %v", err)
+	}
+}
+
+func TestRoundToSymbolGrid(t *testing.T) {
+	d := MustParseDecimal
+	si := testSymbol()
+	if p := si.RoundPrice(d("0.0712349"), RoundDown); !p.Equal(d("0.071234")) {
+		t.Errorf("unexpected price %s", p)
+	}
+	if p := si.RoundPrice(d("0.0712341"), RoundUp); !p.Equal(d("0.071235")) {
+		t.Errorf("unexpected price %s", p)
+	}
+	if q := si.RoundQuantity(d("1.23456"), RoundHalfUp); !q.Equal(d("1.235")) {
+		t.Errorf("unexpected quantity %s", q)
+	}
+	if q := (&SymbolInfo{}).RoundQuantity(d("1.23456"), RoundDown); !q.Equal(d("1.23456")) {
+		t.Errorf("expected quantity unchanged, got %s", q)
+	}
+}